@@ -1,12 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"inventory-api/internal/controllers"
 	"inventory-api/internal/db"
+	appmiddleware "inventory-api/internal/middleware"
 	"inventory-api/internal/routes"
+	"inventory-api/internal/services"
+	"inventory-api/internal/validation"
 
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
@@ -30,14 +40,38 @@ func main() {
 		log.Fatal("Failed to run migrations:", err)
 	}
 
+	// Cargar claves de firma JWT (falla al inicio si la configuración es inválida)
+	if _, err := services.LoadJWTKeys(); err != nil {
+		log.Fatal("Failed to load JWT keys:", err)
+	}
+
+	// Purga periódica de la papelera de productos
+	services.StartTrashPurger(database)
+
+	// Envío en segundo plano de los correos de recuperación de contraseña
+	services.StartPasswordResetWorker(database)
+
 	// Crear instancia de Echo
 	e := echo.New()
 
+	// Validación de las peticiones con las etiquetas validate de los modelos
+	e.Validator = validation.Default()
+
+	// Todos los errores se responden como application/problem+json (RFC 7807)
+	e.HTTPErrorHandler = controllers.HTTPErrorHandler
+
+	// Solo se confía en X-Forwarded-For cuando viene de un proxy en red privada (nginx)
+	e.IPExtractor = echo.ExtractIPFromXFFHeader()
+
 	// Middleware globales
+	e.Use(middleware.RequestID())
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORS())
 
+	// Idioma de los mensajes (Accept-Language o preferencia del usuario)
+	e.Use(appmiddleware.Language())
+
 	// Configurar middleware de rate limiting
 	e.Use(middleware.RateLimiter(middleware.NewRateLimiterMemoryStore(20)))
 
@@ -72,18 +106,64 @@ func main() {
 	fmt.Printf("🚀 Server starting on port %s\n", port)
 	fmt.Println("📚 Endpoints available:")
 	fmt.Println("   GET  /health")
+	fmt.Println("   GET  /.well-known/jwks.json")
+	fmt.Println("   GET  /problems[/:code]")
 	fmt.Println("   POST /auth/register")
 	fmt.Println("   POST /auth/login")
-	fmt.Println("   GET  /products")
+	fmt.Println("   POST /auth/login/mfa")
+	fmt.Println("   GET  /auth/oidc/{login,callback}")
+	fmt.Println("   POST /auth/mfa/{setup,enable,disable,recovery-codes} (Auth required)")
+	fmt.Println("   POST /auth/verify-email/{request,confirm}")
+	fmt.Println("   POST /auth/password/{forgot,reset}")
+	fmt.Println("   GET  /auth/password/policy")
+	fmt.Println("   GET|POST|DELETE /auth/api-keys (Auth required)")
+	fmt.Println("   POST /admin/users/:id/unlock (Admin required)")
+	fmt.Println("   GET  /audit (Admin required)")
+	fmt.Println("   POST /auth/switch-organization (Auth required)")
+	fmt.Println("   PUT  /auth/profile/language (Auth required)")
+	fmt.Println("   GET|POST /orgs (Auth required)")
+	fmt.Println("   GET|POST|PUT|DELETE /orgs/current/members (Auth required)")
+	fmt.Println("   GET|POST|PUT|DELETE /categories (Auth required)")
+	fmt.Println("   GET|POST|PUT|DELETE /categories/:id/attributes (Auth required)")
+	fmt.Println("   GET|POST|PUT|DELETE /stock-rules (Auth required)")
+	fmt.Println("   GET  /tags (Auth required)")
+	fmt.Println("   GET  /products (Auth required)")
 	fmt.Println("   POST /products (Auth required)")
-	fmt.Println("   GET  /products/:id")
+	fmt.Println("   POST /products/import (Auth required)")
+	fmt.Println("   POST /products/bulk (Auth required)")
+	fmt.Println("   POST /products/bulk/update (Auth required)")
+	fmt.Println("   GET  /products/export?format=csv|jsonl|xlsx (Auth required)")
+	fmt.Println("   GET  /products/:id[?as_of=YYYY-MM-DD] (Auth required)")
+	fmt.Println("   GET  /products/:id/history (Auth required)")
 	fmt.Println("   PUT  /products/:id (Auth required)")
-	fmt.Println("   DELETE /products/:id (Auth required)")
-	fmt.Println("   GET  /products/low-stock")
+	fmt.Println("   PATCH /products/:id (Auth required)")
+	fmt.Println("   DELETE /products/:id[?permanent=true] (Auth required)")
+	fmt.Println("   GET  /products/search?q= (Auth required)")
+	fmt.Println("   GET  /products/suggest?q= (Auth required)")
+	fmt.Println("   GET  /products/trash (Auth required)")
+	fmt.Println("   POST /products/:id/restore (Auth required)")
+	fmt.Println("   GET  /products/low-stock (Auth required)")
 	fmt.Println("   GET  /products/alerts (Auth required)")
 
 	// Iniciar servidor
-	if err := e.Start(":" + port); err != nil {
-		log.Fatal("Server failed to start:", err)
+	go func() {
+		if err := e.Start(":" + port); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Server failed to start:", err)
+		}
+	}()
+
+	// Parada ordenada: terminar las peticiones y los correos pendientes antes de salir
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+
+	log.Println("🛑 Shutting down...")
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := e.Shutdown(ctx); err != nil {
+		log.Printf("Warning: server shutdown failed: %v", err)
+	}
+	if err := services.StopPasswordResetWorker(ctx); err != nil {
+		log.Printf("Warning: pending password reset emails were not sent: %v", err)
 	}
 }
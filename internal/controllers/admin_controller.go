@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"net/http"
+
+	"inventory-api/internal/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// AdminController maneja los endpoints de administración de usuarios
+type AdminController struct {
+	authService *services.AuthService
+}
+
+// NewAdminController crea una nueva instancia del controlador de administración
+func NewAdminController(db *gorm.DB) *AdminController {
+	return &AdminController{
+		authService: services.NewAuthService(db),
+	}
+}
+
+// UnlockUser desbloquea una cuenta bloqueada por intentos fallidos
+// @Summary Desbloquear usuario
+// @Description Reinicia los intentos fallidos y elimina el bloqueo temporal de una cuenta
+// @Tags admin
+// @Produce json
+// @Security Bearer
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /admin/users/{id}/unlock [post]
+func (adc *AdminController) UnlockUser(c echo.Context) error {
+	// Obtener ID del parámetro URL
+	id, err := pathID(c, "id", "user")
+	if err != nil {
+		return err
+	}
+
+	if err := adc.authService.UnlockUser(id, clientInfo(c), auditContext(c)); err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "auth.user_unlocked"),
+	})
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"net/http"
+
+	"inventory-api/internal/models"
+	"inventory-api/internal/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// APIKeyController maneja los endpoints de API keys
+type APIKeyController struct {
+	apiKeyService *services.APIKeyService
+}
+
+// NewAPIKeyController crea una nueva instancia del controlador de API keys
+func NewAPIKeyController(db *gorm.DB) *APIKeyController {
+	return &APIKeyController{
+		apiKeyService: services.NewAPIKeyService(db),
+	}
+}
+
+// CreateAPIKey maneja la creación de API keys
+// @Summary Crear API key
+// @Description Crea una API key con un subconjunto de los permisos del usuario. La clave solo se muestra una vez
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param api_key body models.APIKeyRequest true "Datos de la API key"
+// @Success 201 {object} models.APIKeyCreatedResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Router /auth/api-keys [post]
+func (akc *APIKeyController) CreateAPIKey(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req models.APIKeyRequest
+
+	// Bind JSON request
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	// Crear API key (limitada a la organización activa)
+	orgID, _ := c.Get("org_id").(uint)
+	key, err := akc.apiKeyService.CreateAPIKey(userID, orgID, req)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": translate(c, "api_key.created"),
+		"api_key": key,
+	})
+}
+
+// ListAPIKeys maneja la obtención de las API keys del usuario
+// @Summary Listar API keys
+// @Description Lista las API keys del usuario autenticado (sin la clave secreta)
+// @Tags api-keys
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} models.APIKeyResponse
+// @Failure 401 {object} problem.Problem
+// @Router /auth/api-keys [get]
+func (akc *APIKeyController) ListAPIKeys(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	keys, err := akc.apiKeyService.ListAPIKeys(userID)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"api_keys": keys,
+		"total":    len(keys),
+	})
+}
+
+// RevokeAPIKey maneja la revocación de una API key
+// @Summary Revocar API key
+// @Description Revoca una API key del usuario autenticado
+// @Tags api-keys
+// @Security Bearer
+// @Param id path int true "API key ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /auth/api-keys/{id} [delete]
+func (akc *APIKeyController) RevokeAPIKey(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	// Obtener ID del parámetro URL
+	id, err := pathID(c, "id", "API key")
+	if err != nil {
+		return err
+	}
+
+	if err := akc.apiKeyService.RevokeAPIKey(userID, id); err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "api_key.revoked"),
+	})
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"inventory-api/internal/models"
+	"inventory-api/internal/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// AuditController maneja la consulta del log de auditoría
+type AuditController struct {
+	auditService *services.AuditService
+}
+
+// NewAuditController crea una nueva instancia del controlador de auditoría
+func NewAuditController(db *gorm.DB) *AuditController {
+	return &AuditController{
+		auditService: services.NewAuditService(db),
+	}
+}
+
+// ListAuditLogs lista las entradas del log de auditoría
+// @Summary Consultar auditoría
+// @Description Obtiene quién cambió qué, filtrando por entidad, autor y rango de fechas
+// @Tags admin
+// @Produce json
+// @Security Bearer
+// @Param entity_type query string false "Tipo de entidad (product, user)"
+// @Param entity_id query int false "ID de la entidad"
+// @Param actor_id query int false "ID del usuario autor"
+// @Param from query string false "Desde (RFC3339 o YYYY-MM-DD, inclusive)"
+// @Param to query string false "Hasta (RFC3339 exclusivo, o YYYY-MM-DD inclusive)"
+// @Param limit query int false "Máximo de resultados (por defecto 50, máximo 500)"
+// @Param offset query int false "Resultados a omitir"
+// @Success 200 {array} models.AuditLog
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Router /audit [get]
+func (auc *AuditController) ListAuditLogs(c echo.Context) error {
+	filter := models.AuditFilter{
+		EntityType: c.QueryParam("entity_type"),
+	}
+
+	// Parámetros numéricos opcionales
+	entityID, ok := queryInt(c, "entity_id")
+	if !ok {
+		return invalidParameter("entity_id", "error.invalid_value", "entity_id")
+	}
+	actorID, ok := queryInt(c, "actor_id")
+	if !ok {
+		return invalidParameter("actor_id", "error.invalid_value", "actor_id")
+	}
+	if filter.Limit, ok = queryInt(c, "limit"); !ok {
+		return invalidParameter("limit", "error.invalid_value", "limit")
+	}
+	if filter.Offset, ok = queryInt(c, "offset"); !ok {
+		return invalidParameter("offset", "error.invalid_value", "offset")
+	}
+	filter.EntityID = uint(entityID)
+	filter.ActorID = uint(actorID)
+
+	// Rango de fechas
+	if raw := c.QueryParam("from"); raw != "" {
+		from, _, err := parseTimeParam(raw)
+		if err != nil {
+			return invalidParameter("from", "error.invalid_date", "from")
+		}
+		filter.From = &from
+	}
+	if raw := c.QueryParam("to"); raw != "" {
+		to, dateOnly, err := parseTimeParam(raw)
+		if err != nil {
+			return invalidParameter("to", "error.invalid_date", "to")
+		}
+		// Una fecha sin hora incluye el día completo
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.To = &to
+	}
+
+	logs, total, err := auc.auditService.ListAuditLogs(filter)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"audit_logs": logs,
+		"total":      total,
+		"count":      len(logs),
+	})
+}
+
+// queryInt lee un parámetro entero no negativo; 0 si no está presente
+func queryInt(c echo.Context, name string) (int, bool) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return 0, true
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return 0, false
+	}
+	return value, true
+}
+
+// parseTimeParam interpreta un parámetro de fecha RFC3339 o YYYY-MM-DD (UTC)
+// Indica además si el valor era solo una fecha
+func parseTimeParam(raw string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	return t, true, err
+}
+
+// auditContext identifica al usuario autenticado y la petición para la auditoría
+func auditContext(c echo.Context) models.AuditContext {
+	audit := models.AuditContext{
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+		IP:        c.RealIP(),
+	}
+	if userID, ok := c.Get("user_id").(uint); ok {
+		audit.ActorID = &userID
+	}
+	audit.ActorEmail, _ = c.Get("user_email").(string)
+	return audit
+}
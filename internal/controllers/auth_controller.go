@@ -1,9 +1,13 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 
+	"inventory-api/internal/i18n"
+	"inventory-api/internal/middleware"
 	"inventory-api/internal/models"
+	"inventory-api/internal/problem"
 	"inventory-api/internal/services"
 
 	"github.com/labstack/echo/v4"
@@ -12,13 +16,15 @@ import (
 
 // AuthController maneja los endpoints de autenticación
 type AuthController struct {
-	authService *services.AuthService
+	authService    *services.AuthService
+	accountService *services.AccountService
 }
 
 // NewAuthController crea una nueva instancia del controlador de autenticación
 func NewAuthController(db *gorm.DB) *AuthController {
 	return &AuthController{
-		authService: services.NewAuthService(db),
+		authService:    services.NewAuthService(db),
+		accountService: services.NewAccountService(db),
 	}
 }
 
@@ -30,49 +36,26 @@ func NewAuthController(db *gorm.DB) *AuthController {
 // @Produce json
 // @Param user body models.UserRequest true "Datos del usuario"
 // @Success 201 {object} models.UserResponse
-// @Failure 400 {object} map[string]interface{}
-// @Failure 409 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
 // @Router /auth/register [post]
 func (ac *AuthController) Register(c echo.Context) error {
 	var req models.UserRequest
 
 	// Bind JSON request
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error":   "Invalid request format",
-			"details": err.Error(),
-		})
-	}
-
-	// Validar campos requeridos
-	if req.Email == "" {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error": "Email is required",
-		})
-	}
-
-	if req.Password == "" || len(req.Password) < 6 {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error": "Password must be at least 6 characters long",
-		})
+	if err := bindRequest(c, &req); err != nil {
+		return err
 	}
 
 	// Registrar usuario
-	user, err := ac.authService.RegisterUser(req)
+	user, err := ac.authService.RegisterUser(req, clientInfo(c))
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "user already exists" {
-			statusCode = http.StatusConflict
-		}
-
-		return c.JSON(statusCode, map[string]interface{}{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	// Respuesta exitosa
 	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"message": "User created successfully",
+		"message": translate(c, "auth.user_created"),
 		"user":    user,
 	})
 }
@@ -85,43 +68,71 @@ func (ac *AuthController) Register(c echo.Context) error {
 // @Produce json
 // @Param credentials body models.UserRequest true "Credenciales"
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
 // @Router /auth/login [post]
 func (ac *AuthController) Login(c echo.Context) error {
 	var req models.UserRequest
 
 	// Bind JSON request
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error":   "Invalid request format",
-			"details": err.Error(),
-		})
-	}
-
-	// Validar campos requeridos
-	if req.Email == "" || req.Password == "" {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error": "Email and password are required",
-		})
+	if err := bindRequest(c, &req); err != nil {
+		return err
 	}
 
 	// Autenticar usuario
-	token, user, err := ac.authService.LoginUser(req)
+	result, err := ac.authService.LoginUser(req, clientInfo(c))
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "invalid credentials" {
-			statusCode = http.StatusUnauthorized
-		}
+		return err
+	}
+
+	// Desde aquí se conoce al usuario: responder en su idioma preferido
+	middleware.SetUserLanguage(c, result.User.Language)
 
-		return c.JSON(statusCode, map[string]interface{}{
-			"error": err.Error(),
+	// La cuenta tiene MFA: el cliente debe enviar el código a /auth/login/mfa
+	if result.MFARequired {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"message":      translate(c, "auth.mfa_required"),
+			"mfa_required": true,
+			"mfa_token":    result.MFAToken,
 		})
 	}
 
 	// Respuesta exitosa
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"message": "Login successful",
+		"message": translate(c, "auth.login_successful"),
+		"token":   result.Token,
+		"user":    result.User,
+	})
+}
+
+// LoginMFA completa el login de una cuenta con MFA
+// @Summary Segundo paso del login
+// @Description Canjea el token de desafío y un código TOTP o de recuperación por el JWT final
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body models.MFALoginRequest true "Token de desafío y código"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Router /auth/login/mfa [post]
+func (ac *AuthController) LoginMFA(c echo.Context) error {
+	var req models.MFALoginRequest
+
+	// Bind JSON request
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	token, user, err := ac.authService.CompleteMFALogin(req, clientInfo(c))
+	if err != nil {
+		return err
+	}
+	middleware.SetUserLanguage(c, user.Language)
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "auth.login_successful"),
 		"token":   token,
 		"user":    user,
 	})
@@ -134,28 +145,31 @@ func (ac *AuthController) Login(c echo.Context) error {
 // @Produce json
 // @Security Bearer
 // @Success 200 {object} models.UserResponse
-// @Failure 401 {object} map[string]interface{}
+// @Failure 401 {object} problem.Problem
 // @Router /auth/profile [get]
 func (ac *AuthController) Profile(c echo.Context) error {
 	// Obtener ID del usuario del contexto (añadido por el middleware JWT)
-	userID, ok := c.Get("user_id").(uint)
-	if !ok {
-		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-			"error": "Invalid token",
-		})
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
 	}
 
 	// Obtener usuario de la base de datos
 	user, err := ac.authService.GetUserByID(userID)
 	if err != nil {
-		return c.JSON(http.StatusNotFound, map[string]interface{}{
-			"error": "User not found",
-		})
+		return err
+	}
+
+	// Últimos eventos de autenticación (logins correctos y fallidos)
+	events, err := ac.authService.GetRecentAuthEvents(userID, 20)
+	if err != nil {
+		return err
 	}
 
 	// Respuesta exitosa
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"user": user.ToResponse(),
+		"user":               user.ToResponse(),
+		"recent_auth_events": events,
 	})
 }
 
@@ -166,28 +180,266 @@ func (ac *AuthController) Profile(c echo.Context) error {
 // @Produce json
 // @Security Bearer
 // @Success 200 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
+// @Failure 401 {object} problem.Problem
 // @Router /auth/refresh [post]
 func (ac *AuthController) RefreshToken(c echo.Context) error {
 	// Obtener ID del usuario del contexto
-	userID, ok := c.Get("user_id").(uint)
-	if !ok {
-		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-			"error": "Invalid token",
-		})
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
 	}
 
 	// Generar nuevo token
-	token, err := ac.authService.RefreshToken(userID)
+	orgID, _ := c.Get("org_id").(uint)
+	mfaVerified, _ := c.Get("mfa").(bool)
+	token, err := ac.authService.RefreshToken(userID, orgID, mfaVerified)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"error": "Failed to refresh token",
-		})
+		return err
 	}
 
 	// Respuesta exitosa
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"message": "Token refreshed successfully",
+		"message": translate(c, "auth.token_refreshed"),
 		"token":   token,
 	})
 }
+
+// SwitchOrganization emite un token con otra organización del usuario como activa
+// @Summary Cambiar de organización
+// @Description Genera un nuevo token JWT cuyo tenant activo es la organización indicada
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param organization body models.SwitchOrganizationRequest true "Organización destino"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Router /auth/switch-organization [post]
+func (ac *AuthController) SwitchOrganization(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req models.SwitchOrganizationRequest
+
+	// Bind JSON request
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	mfaVerified, _ := c.Get("mfa").(bool)
+	token, err := ac.authService.SwitchOrganization(userID, req.OrganizationID, mfaVerified)
+	if err != nil {
+		if errors.Is(err, services.ErrMembershipNotFound) {
+			return problem.NotMember.New()
+		}
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":         translate(c, "auth.organization_switched"),
+		"token":           token,
+		"organization_id": req.OrganizationID,
+	})
+}
+
+// UpdateLanguage cambia el idioma preferido del usuario autenticado
+// @Summary Cambiar idioma preferido
+// @Description Guarda el idioma de las respuestas (es o en), que prevalece sobre Accept-Language. Vacío vuelve a usar la cabecera. Retorna un token nuevo con la preferencia
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param language body models.LanguageRequest true "Idioma preferido"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Router /auth/profile/language [put]
+func (ac *AuthController) UpdateLanguage(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req models.LanguageRequest
+
+	// Bind JSON request
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	orgID, _ := c.Get("org_id").(uint)
+	mfaVerified, _ := c.Get("mfa").(bool)
+	token, user, err := ac.authService.UpdateLanguage(userID, orgID, mfaVerified, req.Language)
+	if err != nil {
+		return err
+	}
+
+	// La respuesta ya usa la nueva preferencia
+	middleware.SetUserLanguage(c, user.Language)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "auth.language_updated"),
+		"token":   token,
+		"user":    user,
+	})
+}
+
+// JWKS publica las claves públicas usadas para firmar los tokens
+// @Summary Claves públicas JWT
+// @Description Retorna el JSON Web Key Set para verificar tokens en otros servicios
+// @Tags auth
+// @Produce json
+// @Success 200 {object} services.JWKSet
+// @Failure 500 {object} problem.Problem
+// @Router /.well-known/jwks.json [get]
+func (ac *AuthController) JWKS(c echo.Context) error {
+	jwks, err := ac.authService.GetJWKS()
+	if err != nil {
+		return err
+	}
+
+	// Permitir que los clientes cacheen el documento durante un tiempo corto
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, jwks)
+}
+
+// RequestEmailVerification reenvía el correo de verificación
+// @Summary Reenviar verificación de email
+// @Description Envía un nuevo enlace de verificación al email del usuario autenticado
+// @Tags auth
+// @Produce json
+// @Security Bearer
+// @Success 202 {object} map[string]interface{}
+// @Failure 401 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Router /auth/verify-email/request [post]
+func (ac *AuthController) RequestEmailVerification(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := ac.accountService.RequestEmailVerification(userID); err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"message": translate(c, "auth.verification_email_sent"),
+	})
+}
+
+// ConfirmEmailVerification confirma el email con el token recibido
+// @Summary Confirmar email
+// @Description Marca el email como verificado usando el token enviado por correo
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body models.TokenRequest true "Token de verificación"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} problem.Problem
+// @Router /auth/verify-email/confirm [post]
+func (ac *AuthController) ConfirmEmailVerification(c echo.Context) error {
+	var req models.TokenRequest
+
+	// Bind JSON request
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	user, err := ac.accountService.ConfirmEmailVerification(req.Token, clientInfo(c))
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "auth.email_verified"),
+		"user":    user,
+	})
+}
+
+// ForgotPassword solicita un enlace de recuperación de contraseña
+// @Summary Solicitar recuperación de contraseña
+// @Description Envía un enlace de recuperación si el email está registrado. La respuesta es la misma en ambos casos
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param email body models.EmailRequest true "Email de la cuenta"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Router /auth/password/forgot [post]
+func (ac *AuthController) ForgotPassword(c echo.Context) error {
+	var req models.EmailRequest
+
+	// Bind JSON request
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	if err := ac.accountService.RequestPasswordReset(req.Email); err != nil {
+		// El error se registra pero no se expone para no revelar si la cuenta existe
+		c.Logger().Errorf("password reset request failed: %v", err)
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"message": translate(c, "auth.password_reset_requested"),
+	})
+}
+
+// ResetPassword cambia la contraseña con el token de recuperación
+// @Summary Restablecer contraseña
+// @Description Cambia la contraseña usando el token de un solo uso enviado por correo
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param reset body models.PasswordResetRequest true "Token y nueva contraseña"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Router /auth/password/reset [post]
+func (ac *AuthController) ResetPassword(c echo.Context) error {
+	var req models.PasswordResetRequest
+
+	// Bind JSON request
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	if err := ac.accountService.ResetPassword(req.Token, req.Password, clientInfo(c)); err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "auth.password_reset"),
+	})
+}
+
+// PasswordPolicy retorna los requisitos de contraseña vigentes
+// @Summary Política de contraseñas
+// @Description Retorna los requisitos que deben cumplir las contraseñas nuevas
+// @Tags auth
+// @Produce json
+// @Success 200 {object} services.PasswordPolicy
+// @Router /auth/password/policy [get]
+func (ac *AuthController) PasswordPolicy(c echo.Context) error {
+	return c.JSON(http.StatusOK, services.LoadPasswordPolicy())
+}
+
+// translate retorna un mensaje del catálogo en el idioma de la respuesta
+func translate(c echo.Context, key string, args ...interface{}) string {
+	return i18n.T(middleware.GetLanguage(c), key, args...)
+}
+
+// clientInfo extrae la IP, el user agent y el ID de la petición
+func clientInfo(c echo.Context) models.ClientInfo {
+	return models.ClientInfo{
+		IP:        c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+	}
+}
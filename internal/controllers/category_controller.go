@@ -0,0 +1,330 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"inventory-api/internal/models"
+	"inventory-api/internal/services"
+	"inventory-api/internal/validation"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// CategoryController maneja los endpoints del árbol de categorías
+type CategoryController struct {
+	categoryService *services.CategoryService
+}
+
+// NewCategoryController crea una nueva instancia del controlador de categorías
+func NewCategoryController(db *gorm.DB) *CategoryController {
+	return &CategoryController{
+		categoryService: services.NewCategoryService(db),
+	}
+}
+
+// categories retorna el servicio de categorías limitado a la organización de la petición
+// y que atribuye los cambios al usuario autenticado
+func (cc *CategoryController) categories(c echo.Context) *services.CategoryService {
+	orgID, _ := c.Get("org_id").(uint)
+	return cc.categoryService.WithTenant(orgID).WithActor(auditContext(c))
+}
+
+// ListCategories lista las categorías de la organización
+// @Summary Listar categorías
+// @Description Obtiene el árbol de categorías de la organización, o una lista plana ordenada por ruta con flat=true
+// @Tags categories
+// @Produce json
+// @Security Bearer
+// @Param flat query bool false "Lista plana en lugar de árbol"
+// @Success 200 {array} models.CategoryResponse
+// @Router /categories [get]
+func (cc *CategoryController) ListCategories(c echo.Context) error {
+	flat, _ := strconv.ParseBool(c.QueryParam("flat"))
+
+	categories, err := cc.categories(c).ListCategories(flat)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"categories": categories,
+	})
+}
+
+// GetCategory obtiene una categoría con sus subcategorías directas
+// @Summary Obtener categoría
+// @Description Obtiene una categoría con su ruta desde la raíz y sus subcategorías directas
+// @Tags categories
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Category ID"
+// @Success 200 {object} models.CategoryResponse
+// @Failure 404 {object} problem.Problem
+// @Router /categories/{id} [get]
+func (cc *CategoryController) GetCategory(c echo.Context) error {
+	id, err := pathID(c, "id", "category")
+	if err != nil {
+		return err
+	}
+
+	category, err := cc.categories(c).GetCategory(id)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"category": category,
+	})
+}
+
+// CreateCategory crea una categoría
+// @Summary Crear categoría
+// @Description Crea una categoría en la raíz o bajo la categoría indicada en parent_id
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param category body models.CategoryRequest true "Datos de la categoría"
+// @Success 201 {object} models.CategoryResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Router /categories [post]
+func (cc *CategoryController) CreateCategory(c echo.Context) error {
+	var req models.CategoryRequest
+
+	// Bind JSON request
+	if err := c.Bind(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	category, err := cc.categories(c).CreateCategory(req)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message":  translate(c, "category.created"),
+		"category": category,
+	})
+}
+
+// UpdateCategory renombra o mueve una categoría
+// @Summary Actualizar categoría
+// @Description Cambia el nombre, el slug o la categoría padre; el nuevo nombre se aplica a sus productos
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Category ID"
+// @Param category body models.CategoryRequest true "Datos de la categoría"
+// @Success 200 {object} models.CategoryResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Router /categories/{id} [put]
+func (cc *CategoryController) UpdateCategory(c echo.Context) error {
+	id, err := pathID(c, "id", "category")
+	if err != nil {
+		return err
+	}
+
+	var req models.CategoryRequest
+
+	// Bind JSON request
+	if err := c.Bind(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	category, err := cc.categories(c).UpdateCategory(id, req)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":  translate(c, "category.updated"),
+		"category": category,
+	})
+}
+
+// DeleteCategory elimina una categoría vacía
+// @Summary Eliminar categoría
+// @Description Elimina una categoría sin subcategorías ni productos (incluidos los de la papelera)
+// @Tags categories
+// @Security Bearer
+// @Param id path int true "Category ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Router /categories/{id} [delete]
+func (cc *CategoryController) DeleteCategory(c echo.Context) error {
+	id, err := pathID(c, "id", "category")
+	if err != nil {
+		return err
+	}
+
+	if err := cc.categories(c).DeleteCategory(id); err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "category.deleted"),
+	})
+}
+
+// ListAttributes lista los atributos que aplican a los productos de una categoría
+// @Summary Listar atributos de categoría
+// @Description Obtiene las definiciones de atributos de la categoría y las heredadas de sus antecesoras
+// @Tags categories
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Category ID"
+// @Success 200 {array} models.AttributeDefinition
+// @Failure 404 {object} problem.Problem
+// @Router /categories/{id}/attributes [get]
+func (cc *CategoryController) ListAttributes(c echo.Context) error {
+	id, err := pathID(c, "id", "category")
+	if err != nil {
+		return err
+	}
+
+	attributes, err := cc.categories(c).ListAttributes(id)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"attributes": attributes,
+	})
+}
+
+// CreateAttribute define un atributo para los productos de una categoría
+// @Summary Crear atributo de categoría
+// @Description Define un atributo tipado (string, number, bool o enum) para la categoría y sus subcategorías
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Category ID"
+// @Param attribute body models.AttributeDefinitionRequest true "Definición del atributo"
+// @Success 201 {object} models.AttributeDefinition
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Router /categories/{id}/attributes [post]
+func (cc *CategoryController) CreateAttribute(c echo.Context) error {
+	id, err := pathID(c, "id", "category")
+	if err != nil {
+		return err
+	}
+
+	var req models.AttributeDefinitionRequest
+
+	// Bind JSON request
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+	if req.Type == "" {
+		return validation.Required("type")
+	}
+
+	attribute, err := cc.categories(c).CreateAttribute(id, req)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message":   translate(c, "attribute.created"),
+		"attribute": attribute,
+	})
+}
+
+// UpdateAttribute cambia la definición de un atributo de la categoría
+// @Summary Actualizar atributo de categoría
+// @Description Cambia la etiqueta, las opciones o la obligatoriedad; el tipo no se puede cambiar
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Category ID"
+// @Param key path string true "Clave del atributo"
+// @Param attribute body models.AttributeDefinitionRequest true "Definición del atributo"
+// @Success 200 {object} models.AttributeDefinition
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /categories/{id}/attributes/{key} [put]
+func (cc *CategoryController) UpdateAttribute(c echo.Context) error {
+	id, err := pathID(c, "id", "category")
+	if err != nil {
+		return err
+	}
+
+	var req models.AttributeDefinitionRequest
+
+	// Bind JSON request
+	if err := c.Bind(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	// La clave es la de la ruta
+	req.Key = c.Param("key")
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	attribute, err := cc.categories(c).UpdateAttribute(id, req.Key, req)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":   translate(c, "attribute.updated"),
+		"attribute": attribute,
+	})
+}
+
+// DeleteAttribute elimina un atributo de la categoría
+// @Summary Eliminar atributo de categoría
+// @Description Elimina la definición y quita su valor de los productos de la categoría y sus subcategorías
+// @Tags categories
+// @Security Bearer
+// @Param id path int true "Category ID"
+// @Param key path string true "Clave del atributo"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} problem.Problem
+// @Router /categories/{id}/attributes/{key} [delete]
+func (cc *CategoryController) DeleteAttribute(c echo.Context) error {
+	id, err := pathID(c, "id", "category")
+	if err != nil {
+		return err
+	}
+
+	if err := cc.categories(c).DeleteAttribute(id, c.Param("key")); err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "attribute.deleted"),
+	})
+}
@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"inventory-api/internal/middleware"
+	"inventory-api/internal/models"
+	"inventory-api/internal/problem"
+	"inventory-api/internal/services"
+	"inventory-api/internal/validation"
+
+	"github.com/labstack/echo/v4"
+)
+
+// serviceErrors traduce los errores de los servicios a tipos del catálogo, con el código del
+// mensaje del detalle que ve el cliente (vacío si basta el título) y sus argumentos
+var serviceErrors = []struct {
+	err    error
+	typ    problem.Type
+	detail string
+	args   []interface{}
+}{
+	// Organizaciones y miembros
+	{services.ErrOrganizationRequired, problem.OrganizationRequired, "", nil},
+	{services.ErrOrganizationNotFound, problem.OrganizationNotFound, "", nil},
+	{services.ErrInvalidOrganizationSlug, problem.InvalidSlug, "error.invalid_slug", nil},
+	{services.ErrOrganizationSlugExists, problem.SlugExists, "error.organization_slug_exists", nil},
+	{services.ErrMembershipNotFound, problem.MemberNotFound, "", nil},
+	{services.ErrAlreadyMember, problem.AlreadyMember, "", nil},
+	{services.ErrInvalidRole, problem.InvalidRole, "error.invalid_role", nil},
+	{services.ErrOwnerRequired, problem.OwnerRequired, "", nil},
+	{services.ErrLastOwner, problem.LastOwner, "", nil},
+
+	// Usuarios, sesiones y credenciales
+	{services.ErrUserNotFound, problem.UserNotFound, "", nil},
+	{services.ErrUserExists, problem.UserExists, "error.user_exists", nil},
+	{services.ErrInvalidCredentials, problem.InvalidCredentials, "", nil},
+	{services.ErrInvalidToken, problem.InvalidLinkToken, "", nil},
+	{services.ErrEmailAlreadyVerified, problem.EmailAlreadyVerified, "", nil},
+	{services.ErrInvalidMFAToken, problem.InvalidMFAToken, "error.invalid_mfa_token", nil},
+	{services.ErrInvalidMFACode, problem.InvalidMFACode, "", nil},
+	{services.ErrMFAAlreadyEnabled, problem.MFAAlreadyEnabled, "", nil},
+	{services.ErrMFANotEnabled, problem.MFANotEnabled, "", nil},
+	{services.ErrMFASetupNotStarted, problem.MFASetupNotStarted, "error.mfa_setup_not_started", nil},
+	{services.ErrInvalidAPIKey, problem.InvalidAPIKey, "", nil},
+	{services.ErrAPIKeyNotFound, problem.APIKeyNotFound, "", nil},
+	{services.ErrOIDCNotConfigured, problem.OIDCNotConfigured, "", nil},
+
+	// Productos
+	{services.ErrProductNotFound, problem.ProductNotFound, "", nil},
+	{services.ErrProductNotInTrash, problem.ProductNotFound, "error.product_not_in_trash", nil},
+	{services.ErrSKUExists, problem.SKUExists, "", nil},
+	{services.ErrInvalidCategory, problem.InvalidCategory, "", nil},
+	{services.ErrInvalidTag, problem.InvalidTags, "error.invalid_tags", []interface{}{models.MaxProductTags}},
+	{services.ErrTooManyTags, problem.InvalidTags, "error.invalid_tags", []interface{}{models.MaxProductTags}},
+	{services.ErrInvalidCursor, problem.InvalidCursor, "error.invalid_cursor", nil},
+	{services.ErrUnsupportedPatchType, problem.UnsupportedMediaType, "", nil},
+	{services.ErrPatchTestFailed, problem.PatchTestFailed, "", nil},
+	{services.ErrInvalidBulkMode, problem.InvalidBulk, "error.invalid_bulk_mode", nil},
+	{services.ErrNoOperations, problem.InvalidBulk, "error.no_operations", nil},
+	{services.ErrTooManyOperations, problem.InvalidBulk, "error.too_many_operations", []interface{}{models.MaxBulkOperations}},
+	{services.ErrNoChanges, problem.InvalidBulk, "error.no_changes", nil},
+	{services.ErrInvalidMassUpdate, problem.InvalidBulk, "error.invalid_mass_update", nil},
+	{services.ErrFiltersRequired, problem.FiltersRequired, "error.filters_required", nil},
+
+	// Categorías y atributos
+	{services.ErrCategoryNotFound, problem.CategoryNotFound, "", nil},
+	{services.ErrParentCategoryNotFound, problem.ParentCategoryNotFound, "", nil},
+	{services.ErrInvalidCategorySlug, problem.InvalidSlug, "error.invalid_slug", nil},
+	{services.ErrCategorySlugExists, problem.SlugExists, "error.category_slug_exists", nil},
+	{services.ErrCategoryCycle, problem.CategoryCycle, "error.category_cycle", nil},
+	{services.ErrCategoryHasChildren, problem.CategoryNotEmpty, "error.category_has_children", nil},
+	{services.ErrCategoryHasProducts, problem.CategoryNotEmpty, "error.category_has_products", nil},
+	{services.ErrAttributeNotFound, problem.AttributeNotFound, "", nil},
+	{services.ErrAttributeExists, problem.AttributeExists, "error.attribute_exists", nil},
+	{services.ErrAttributeTypeChange, problem.AttributeTypeImmutable, "error.attribute_type_change", nil},
+	{services.ErrInvalidAttributeKey, problem.InvalidAttributeDefinition, "error.invalid_attribute_key", nil},
+	{services.ErrInvalidAttributeType, problem.InvalidAttributeDefinition, "error.invalid_attribute_type", nil},
+	{services.ErrInvalidAttributeLabel, problem.InvalidAttributeDefinition, "error.invalid_attribute_label", nil},
+	{services.ErrEnumOptionsRequired, problem.InvalidAttributeDefinition, "error.enum_options_required", nil},
+
+	// Reglas de stock
+	{services.ErrStockRuleNotFound, problem.StockRuleNotFound, "", nil},
+	{services.ErrStockRuleExists, problem.StockRuleExists, "error.stock_rule_exists", nil},
+	{services.ErrStockRuleTargetChange, problem.StockRuleTargetImmutable, "error.stock_rule_target_change", nil},
+	{services.ErrInvalidStockRuleTarget, problem.InvalidStockRule, "error.invalid_stock_rule_target", nil},
+	{services.ErrInvalidStockThresholds, problem.InvalidStockRule, "error.invalid_stock_thresholds", nil},
+	{services.ErrInvalidStockPercent, problem.InvalidStockRule, "error.invalid_stock_percent", nil},
+}
+
+// HTTPErrorHandler es el manejador de errores de Echo: responde a cualquier error con un
+// application/problem+json. Los errores internos se registran con el ID de la petición y
+// el cliente solo recibe un mensaje genérico
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	p := toProblem(c, err)
+	p.Localize(middleware.GetLanguage(c))
+	if p.Status >= http.StatusInternalServerError {
+		requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+		c.Logger().Errorf("%s %s failed (request %s): %v", c.Request().Method, c.Request().URL.Path, requestID, err)
+		p.With("request_id", requestID)
+	}
+	if err := problem.Write(c, p); err != nil {
+		c.Logger().Error(err)
+	}
+}
+
+// toProblem traduce un error al problema que se envía al cliente
+func toProblem(c echo.Context, err error) *problem.Problem {
+	var p *problem.Problem
+	if errors.As(err, &p) {
+		return p
+	}
+
+	var fieldErrs validation.Errors
+	if errors.As(err, &fieldErrs) {
+		return problem.ValidationFailed.New().With("errors", fieldErrs.Localize(middleware.GetLanguage(c)))
+	}
+
+	var policyErr *services.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		return problem.PasswordPolicy.New().
+			With("violations", policyErr.Messages(middleware.GetLanguage(c))).
+			With("policy", services.LoadPasswordPolicy())
+	}
+
+	var blocked *services.LoginBlockedError
+	if errors.As(err, &blocked) {
+		retryAfter := int(math.Ceil(blocked.RetryAfter.Seconds()))
+		c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		if blocked.Locked {
+			return problem.AccountLocked.New().With("retry_after", retryAfter)
+		}
+		return problem.LoginThrottled.New().With("retry_after", retryAfter)
+	}
+
+	var filterErr *services.ProductFilterError
+	if errors.As(err, &filterErr) {
+		return problem.InvalidFilter.Message("error.invalid_filter", filterErr.Filter, filterErr.Reason).
+			With("filter", filterErr.Filter).
+			With("filters", services.ProductFilterSpec())
+	}
+	if errors.Is(err, services.ErrInvalidSortField) {
+		return problem.InvalidSort.New().With("fields", models.ProductSortFields)
+	}
+
+	var attributeErr *services.ProductAttributeError
+	if errors.As(err, &attributeErr) {
+		return problem.InvalidAttribute.Message("error.invalid_attribute_value", attributeErr.Attribute, attributeErr.Reason).
+			With("attribute", attributeErr.Attribute)
+	}
+
+	var importErr *services.ProductImportError
+	if errors.As(err, &importErr) {
+		return problem.InvalidImport.Message(importErr.Reason.Key, importErr.Reason.Args...).
+			With("fields", models.ProductImportFields)
+	}
+
+	var patchErr *services.JSONPatchError
+	if errors.As(err, &patchErr) {
+		return problem.InvalidPatch.Message(patchErr.Reason.Key, patchErr.Reason.Args...)
+	}
+
+	var productErr *services.ProductValidationError
+	if errors.As(err, &productErr) {
+		return problem.InvalidProduct.Message("error.invalid_product_field", productErr.Field, productErr.Reason).
+			With("field", productErr.Field)
+	}
+
+	var permissionErr *services.APIKeyPermissionError
+	if errors.As(err, &permissionErr) {
+		return problem.InvalidPermission.Message(permissionErr.Reason.Key, permissionErr.Reason.Args...).
+			With("permission", permissionErr.Permission)
+	}
+
+	for _, known := range serviceErrors {
+		if errors.Is(err, known.err) {
+			if known.detail == "" {
+				return known.typ.New()
+			}
+			return known.typ.Message(known.detail, known.args...)
+		}
+	}
+
+	// Errores de Echo: rutas inexistentes, límite de peticiones, cuerpos demasiado grandes...
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) && httpErr.Code < http.StatusInternalServerError {
+		p := problem.ForStatus(httpErr.Code).New()
+		if message, ok := httpErr.Message.(string); ok && message != http.StatusText(httpErr.Code) {
+			p.Detail = message
+		}
+		return p
+	}
+
+	return problem.Internal.Message("error.internal")
+}
+
+// invalidRequest responde a un cuerpo que no se pudo leer (JSON mal formado, tipos incorrectos...)
+func invalidRequest(err error) *problem.Problem {
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		if message, ok := httpErr.Message.(string); ok {
+			return problem.InvalidRequest.Detail(message)
+		}
+	}
+	return problem.InvalidRequest.Message("error.unreadable_body")
+}
+
+// bindRequest lee el cuerpo JSON en req y lo valida con las etiquetas validate
+func bindRequest(c echo.Context, req interface{}) error {
+	if err := c.Bind(req); err != nil {
+		return invalidRequest(err)
+	}
+	return c.Validate(req)
+}
+
+// invalidParameter responde a un parámetro de la ruta o de la query string con un valor no válido;
+// key es el código del mensaje del detalle
+func invalidParameter(name, key string, args ...interface{}) *problem.Problem {
+	return problem.InvalidParameter.Message(key, args...).With("parameter", name)
+}
+
+// pathID lee un ID numérico de la ruta; resource nombra el recurso en el mensaje de error
+func pathID(c echo.Context, param, resource string) (uint, error) {
+	id, err := strconv.ParseUint(c.Param(param), 10, 32)
+	if err != nil {
+		return 0, invalidParameter(param, "error.invalid_id", resource, param)
+	}
+	return uint(id), nil
+}
+
+// currentUserID obtiene el usuario autenticado; sin él la petición no está autenticada
+func currentUserID(c echo.Context) (uint, error) {
+	userID, ok := c.Get("user_id").(uint)
+	if !ok {
+		return 0, problem.InvalidToken.New()
+	}
+	return userID, nil
+}
+
+// ListProblemTypes documenta el catálogo de tipos de problema
+// @Summary Tipos de error
+// @Description Lista los tipos de problema (RFC 7807) que puede retornar la API con su código estable
+// @Tags errors
+// @Produce json
+// @Success 200 {array} problem.Type
+// @Router /problems [get]
+func ListProblemTypes(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"problems": problem.Catalogue(),
+	})
+}
+
+// GetProblemType describe un tipo de problema; es el destino de la URI del miembro type
+// @Summary Describir tipo de error
+// @Description Retorna el estado HTTP y el título de un tipo de problema
+// @Tags errors
+// @Produce json
+// @Param code path string true "Código del problema"
+// @Success 200 {object} problem.Type
+// @Failure 404 {object} problem.Problem
+// @Router /problems/{code} [get]
+func GetProblemType(c echo.Context) error {
+	t, ok := problem.Lookup(c.Param("code"))
+	if !ok {
+		return problem.NotFound.Message("error.unknown_problem_type")
+	}
+	return c.JSON(http.StatusOK, t)
+}
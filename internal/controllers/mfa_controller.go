@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"net/http"
+
+	"inventory-api/internal/models"
+	"inventory-api/internal/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// MFAController maneja los endpoints del segundo factor de autenticación
+type MFAController struct {
+	mfaService *services.MFAService
+}
+
+// NewMFAController crea una nueva instancia del controlador de MFA
+func NewMFAController(db *gorm.DB) *MFAController {
+	return &MFAController{
+		mfaService: services.NewMFAService(db),
+	}
+}
+
+// Setup inicia la activación de TOTP
+// @Summary Iniciar activación de MFA
+// @Description Genera un secreto TOTP y la URI otpauth:// para mostrar como código QR
+// @Tags mfa
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} models.MFASetupResponse
+// @Failure 401 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Router /auth/mfa/setup [post]
+func (mc *MFAController) Setup(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	setup, err := mc.mfaService.SetupMFA(userID)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "mfa.setup_started"),
+		"mfa":     setup,
+	})
+}
+
+// Enable confirma la activación de TOTP con un código
+// @Summary Activar MFA
+// @Description Verifica el primer código TOTP y retorna los códigos de recuperación
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param code body models.MFACodeRequest true "Código TOTP"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Router /auth/mfa/enable [post]
+func (mc *MFAController) Enable(c echo.Context) error {
+	userID, req, err := mc.bindCode(c)
+	if err != nil {
+		return err
+	}
+
+	codes, err := mc.mfaService.EnableMFA(userID, req.Code, auditContext(c))
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":        translate(c, "mfa.enabled"),
+		"recovery_codes": codes,
+	})
+}
+
+// Disable desactiva TOTP
+// @Summary Desactivar MFA
+// @Description Desactiva el segundo factor tras verificar un código TOTP o de recuperación
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param code body models.MFACodeRequest true "Código TOTP o de recuperación"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Router /auth/mfa/disable [post]
+func (mc *MFAController) Disable(c echo.Context) error {
+	userID, req, err := mc.bindCode(c)
+	if err != nil {
+		return err
+	}
+
+	if err := mc.mfaService.DisableMFA(userID, req.Code, auditContext(c)); err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "mfa.disabled"),
+	})
+}
+
+// RegenerateRecoveryCodes genera nuevos códigos de recuperación
+// @Summary Regenerar códigos de recuperación
+// @Description Invalida los códigos anteriores y genera nuevos
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param code body models.MFACodeRequest true "Código TOTP o de recuperación"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Router /auth/mfa/recovery-codes [post]
+func (mc *MFAController) RegenerateRecoveryCodes(c echo.Context) error {
+	userID, req, err := mc.bindCode(c)
+	if err != nil {
+		return err
+	}
+
+	codes, err := mc.mfaService.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":        translate(c, "mfa.recovery_codes_regenerated"),
+		"recovery_codes": codes,
+	})
+}
+
+// bindCode obtiene el usuario del contexto y el código del cuerpo
+func (mc *MFAController) bindCode(c echo.Context) (uint, *models.MFACodeRequest, error) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req models.MFACodeRequest
+	if err := bindRequest(c, &req); err != nil {
+		return 0, nil, err
+	}
+	return userID, &req, nil
+}
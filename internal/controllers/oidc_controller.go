@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"inventory-api/internal/middleware"
+	"inventory-api/internal/problem"
+	"inventory-api/internal/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// oidcStateCookie liga el state del login al navegador que lo inició: sin ella, un enlace
+// de callback obtenido por otra persona iniciaría sesión con su cuenta (login CSRF)
+const oidcStateCookie = "oidc_state"
+
+// OIDCController maneja el login con un proveedor de identidad externo
+type OIDCController struct {
+	oidcService       *services.OIDCService
+	postLoginRedirect string
+}
+
+// NewOIDCController crea una nueva instancia del controlador OIDC
+func NewOIDCController(db *gorm.DB) *OIDCController {
+	return &OIDCController{
+		oidcService:       services.NewOIDCService(db),
+		postLoginRedirect: os.Getenv("OIDC_POST_LOGIN_REDIRECT"),
+	}
+}
+
+// Login redirige al proveedor de identidad
+// @Summary Iniciar login OIDC
+// @Description Redirige al proveedor de identidad con el flujo authorization code + PKCE
+// @Tags auth
+// @Success 302
+// @Failure 404 {object} problem.Problem
+// @Failure 502 {object} problem.Problem
+// @Router /auth/oidc/login [get]
+func (oc *OIDCController) Login(c echo.Context) error {
+	if !oc.oidcService.Enabled() {
+		return problem.OIDCNotConfigured.New()
+	}
+
+	authURL, state, err := oc.oidcService.AuthorizationURL()
+	if err != nil {
+		c.Logger().Errorf("oidc login failed: %v", err)
+		return problem.IdentityProviderUnavailable.New()
+	}
+
+	// SameSite=Lax: la cookie viaja en la redirección de vuelta del proveedor (GET de nivel superior)
+	setOIDCStateCookie(c, state, int(services.OIDCStateTTL/time.Second))
+	return c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback recibe el código del proveedor y emite el JWT de la API
+// @Summary Callback OIDC
+// @Description Valida el state contra la cookie del navegador que inició el login, canjea el código, verifica el ID token y retorna el JWT de la API
+// @Tags auth
+// @Produce json
+// @Param code query string true "Código de autorización"
+// @Param state query string true "State generado en /auth/oidc/login"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Router /auth/oidc/callback [get]
+func (oc *OIDCController) Callback(c echo.Context) error {
+	if !oc.oidcService.Enabled() {
+		return problem.OIDCNotConfigured.New()
+	}
+
+	// El proveedor informa errores (p. ej. acceso denegado) en los parámetros
+	if providerErr := c.QueryParam("error"); providerErr != "" {
+		return problem.OIDCLoginFailed.Message("error.oidc_login_rejected").With("provider_error", providerErr)
+	}
+
+	code := c.QueryParam("code")
+	state := c.QueryParam("state")
+	if code == "" || state == "" {
+		return problem.BadRequest.Message("error.oidc_callback_params")
+	}
+
+	// El state debe coincidir con el del navegador que inició el login; la cookie es de un solo uso
+	cookie, err := c.Cookie(oidcStateCookie)
+	setOIDCStateCookie(c, "", -1)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		return problem.OIDCLoginFailed.Message("error.oidc_state_mismatch")
+	}
+
+	token, user, err := oc.oidcService.HandleCallback(code, state, clientInfo(c))
+	if err != nil {
+		c.Logger().Errorf("oidc callback failed: %v", err)
+		return problem.OIDCLoginFailed.New()
+	}
+
+	// Para aplicaciones web, entregar el token en el fragmento (no llega a los logs del servidor)
+	if oc.postLoginRedirect != "" {
+		fragment := url.Values{}
+		fragment.Set("token", token)
+		return c.Redirect(http.StatusFound, oc.postLoginRedirect+"#"+fragment.Encode())
+	}
+
+	// Respuesta exitosa, en el idioma preferido del usuario
+	middleware.SetUserLanguage(c, user.Language)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "auth.login_successful"),
+		"token":   token,
+		"user":    user,
+	})
+}
+
+// setOIDCStateCookie guarda (o, con maxAge negativo, borra) la cookie con el state del login
+func setOIDCStateCookie(c echo.Context, state string, maxAge int) {
+	c.SetCookie(&http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
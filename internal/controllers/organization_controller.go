@@ -0,0 +1,232 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"inventory-api/internal/models"
+	"inventory-api/internal/services"
+	"inventory-api/internal/validation"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// OrganizationController maneja los endpoints de organizaciones y miembros
+type OrganizationController struct {
+	orgService *services.OrganizationService
+}
+
+// NewOrganizationController crea una nueva instancia del controlador de organizaciones
+func NewOrganizationController(db *gorm.DB) *OrganizationController {
+	return &OrganizationController{
+		orgService: services.NewOrganizationService(db),
+	}
+}
+
+// ListOrganizations lista las organizaciones del usuario autenticado
+// @Summary Listar organizaciones
+// @Description Obtiene las organizaciones a las que pertenece el usuario y su rol en cada una
+// @Tags organizations
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} models.OrganizationResponse
+// @Failure 401 {object} problem.Problem
+// @Router /orgs [get]
+func (oc *OrganizationController) ListOrganizations(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	orgs, err := oc.orgService.ListOrganizations(userID)
+	if err != nil {
+		return err
+	}
+
+	activeOrgID, _ := c.Get("org_id").(uint)
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"organizations":          orgs,
+		"total":                  len(orgs),
+		"active_organization_id": activeOrgID,
+	})
+}
+
+// CreateOrganization crea una organización con el usuario como propietario
+// @Summary Crear organización
+// @Description Crea una organización nueva; el usuario autenticado queda como propietario
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param organization body models.OrganizationRequest true "Datos de la organización"
+// @Success 201 {object} models.OrganizationResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Router /orgs [post]
+func (oc *OrganizationController) CreateOrganization(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req models.OrganizationRequest
+
+	// Bind JSON request
+	if err := c.Bind(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	org, err := oc.orgService.CreateOrganization(userID, req)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message":      translate(c, "organization.created"),
+		"organization": org,
+	})
+}
+
+// ListMembers lista los miembros de la organización activa
+// @Summary Listar miembros
+// @Description Obtiene los miembros de la organización activa y sus roles
+// @Tags organizations
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} models.MemberResponse
+// @Failure 403 {object} problem.Problem
+// @Router /orgs/current/members [get]
+func (oc *OrganizationController) ListMembers(c echo.Context) error {
+	orgID, _ := c.Get("org_id").(uint)
+
+	members, err := oc.orgService.ListMembers(orgID)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"members": members,
+		"total":   len(members),
+	})
+}
+
+// AddMember añade un usuario registrado a la organización activa
+// @Summary Añadir miembro
+// @Description Añade un usuario existente (por email) a la organización activa con el rol indicado
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param member body models.MemberRequest true "Email y rol (owner, admin, member, viewer)"
+// @Success 201 {object} models.MemberResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Router /orgs/current/members [post]
+func (oc *OrganizationController) AddMember(c echo.Context) error {
+	orgID, _ := c.Get("org_id").(uint)
+	actorRole, _ := c.Get("org_role").(string)
+
+	var req models.MemberRequest
+
+	// Bind JSON request
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+	if req.Email == "" {
+		return validation.Required("email")
+	}
+
+	member, err := oc.orgService.AddMember(orgID, actorRole, req)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": translate(c, "organization.member_added"),
+		"member":  member,
+	})
+}
+
+// UpdateMember cambia el rol de un miembro de la organización activa
+// @Summary Cambiar rol de miembro
+// @Description Cambia el rol de un miembro de la organización activa
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param user_id path int true "User ID"
+// @Param member body models.MemberRequest true "Nuevo rol"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /orgs/current/members/{user_id} [put]
+func (oc *OrganizationController) UpdateMember(c echo.Context) error {
+	orgID, _ := c.Get("org_id").(uint)
+	actorRole, _ := c.Get("org_role").(string)
+
+	userID, err := pathID(c, "user_id", "user")
+	if err != nil {
+		return err
+	}
+
+	var req models.MemberRequest
+
+	// Bind JSON request
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	if err := oc.orgService.UpdateMemberRole(orgID, actorRole, uint(userID), req.Role); err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "organization.member_updated"),
+	})
+}
+
+// RemoveMember quita a un usuario de la organización activa
+// @Summary Quitar miembro
+// @Description Quita a un usuario de la organización activa y revoca sus API keys en ella
+// @Tags organizations
+// @Produce json
+// @Security Bearer
+// @Param user_id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /orgs/current/members/{user_id} [delete]
+func (oc *OrganizationController) RemoveMember(c echo.Context) error {
+	orgID, _ := c.Get("org_id").(uint)
+	actorRole, _ := c.Get("org_role").(string)
+
+	userID, err := pathID(c, "user_id", "user")
+	if err != nil {
+		return err
+	}
+
+	if err := oc.orgService.RemoveMember(orgID, actorRole, uint(userID)); err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "organization.member_removed"),
+	})
+}
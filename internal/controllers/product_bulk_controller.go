@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"net/http"
+
+	"inventory-api/internal/middleware"
+	"inventory-api/internal/models"
+	"inventory-api/internal/problem"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BulkProducts maneja un lote de operaciones sobre productos
+// @Summary Operaciones por lotes
+// @Description Crea, actualiza, elimina y ajusta el stock de varios productos en una petición. En modo atomic se aplican todas o ninguna; en per_item cada una por separado. Las eliminaciones requieren el permiso products:delete
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body models.ProductBulkRequest true "Modo y operaciones"
+// @Success 200 {object} models.ProductBulkResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 422 {object} models.ProductBulkResponse
+// @Router /products/bulk [post]
+func (pc *ProductController) BulkProducts(c echo.Context) error {
+	var req models.ProductBulkRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	// Las eliminaciones exigen lo mismo que DELETE /products/:id
+	for _, op := range req.Operations {
+		if op.Op != models.BulkOpDelete {
+			continue
+		}
+		if !middleware.HasPermission(c, models.PermissionProductsDelete) {
+			return problem.InsufficientPermissions.New().With("permission", models.PermissionProductsDelete)
+		}
+		if !middleware.MFASatisfied(c) {
+			return problem.MFARequired.New()
+		}
+		break
+	}
+
+	response, err := pc.products(c).BulkProducts(req)
+	if err != nil {
+		return err
+	}
+
+	// Sin simulación, un lote no confirmado significa que atomic encontró errores
+	status := http.StatusOK
+	if !response.Committed && !response.DryRun {
+		status = http.StatusUnprocessableEntity
+	}
+	return c.JSON(status, response)
+}
+
+// MassUpdateProducts maneja la actualización masiva de precio o stock
+// @Summary Actualización masiva de productos
+// @Description Cambia el precio o el stock de todos los productos que cumplen los filtros (p. ej. +5% de precio en una categoría). Con dry_run devuelve la vista previa sin guardar
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param filter[field][operator] query string false "Los mismos filtros que GET /products"
+// @Param request body models.ProductMassUpdateRequest true "Cambios a aplicar"
+// @Success 200 {object} models.ProductMassUpdateResult
+// @Failure 400 {object} problem.Problem
+// @Failure 422 {object} models.ProductMassUpdateResult
+// @Router /products/bulk/update [post]
+func (pc *ProductController) MassUpdateProducts(c echo.Context) error {
+	query, err := parseProductListQuery(c)
+	if err != nil {
+		return err
+	}
+
+	var req models.ProductMassUpdateRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	result, err := pc.products(c).MassUpdateProducts(query, req)
+	if err != nil {
+		return err
+	}
+
+	// Sin simulación, un resultado no confirmado significa que algún producto quedaría con valores negativos
+	status := http.StatusOK
+	if !result.Committed && !result.DryRun {
+		status = http.StatusUnprocessableEntity
+	}
+	return c.JSON(status, result)
+}
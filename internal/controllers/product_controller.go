@@ -1,10 +1,15 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"inventory-api/internal/middleware"
 	"inventory-api/internal/models"
+	"inventory-api/internal/problem"
 	"inventory-api/internal/services"
 
 	"github.com/labstack/echo/v4"
@@ -23,6 +28,13 @@ func NewProductController(db *gorm.DB) *ProductController {
 	}
 }
 
+// products retorna el servicio de productos limitado a la organización de la petición
+// y que atribuye los cambios al usuario autenticado
+func (pc *ProductController) products(c echo.Context) *services.ProductService {
+	orgID, _ := c.Get("org_id").(uint)
+	return pc.productService.WithTenant(orgID).WithActor(auditContext(c)).WithLanguage(middleware.GetLanguage(c))
+}
+
 // CreateProduct maneja la creación de nuevos productos
 // @Summary Crear un nuevo producto
 // @Description Crea un producto en el inventario
@@ -32,152 +44,185 @@ func NewProductController(db *gorm.DB) *ProductController {
 // @Security Bearer
 // @Param product body models.ProductRequest true "Datos del producto"
 // @Success 201 {object} models.ProductResponse
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
 // @Router /products [post]
 func (pc *ProductController) CreateProduct(c echo.Context) error {
 	var req models.ProductRequest
 
 	// Bind JSON request
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error":   "Invalid request format",
-			"details": err.Error(),
-		})
+	if err := bindRequest(c, &req); err != nil {
+		return err
 	}
 
-	// Validar campos requeridos
-	if req.Name == "" {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error": "Product name is required",
-		})
+	// Crear producto
+	product, err := pc.products(c).CreateProduct(req)
+	if err != nil {
+		return productWriteError(err)
 	}
 
-	if req.Price < 0 {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error": "Price cannot be negative",
-		})
+	// Respuesta exitosa
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": translate(c, "product.created"),
+		"product": product,
+	})
+}
+
+// GetAllProducts maneja el listado paginado de productos
+// @Summary Listar productos
+// @Description Lista los productos con paginación por cursor, orden y filtros
+// @Tags products
+// @Produce json
+// @Security Bearer
+// @Param limit query int false "Productos por página (por defecto 50, máximo 500)"
+// @Param cursor query string false "Token next_cursor de la página anterior"
+// @Param sort query string false "Orden: name, price, quantity, created_at, updated_at"
+// @Param order query string false "Dirección: asc (por defecto) o desc"
+// @Param filter[field][operator] query string false "Filtros combinables, p. ej. filter[price][gte]=10 o filter[category]=tools,garden"
+// @Param search query string false "Atajo de filter[search]"
+// @Param category query string false "Atajo de filter[category]"
+// @Param min_price query number false "Atajo de filter[price][gte]"
+// @Param max_price query number false "Atajo de filter[price][lte]"
+// @Param min_quantity query int false "Atajo de filter[quantity][gte]"
+// @Param max_quantity query int false "Atajo de filter[quantity][lte]"
+// @Param stock_status query string false "Atajo de filter[stock_status]"
+// @Param updated_since query string false "Atajo de filter[updated_at][gte]"
+// @Success 200 {object} models.ProductPage
+// @Failure 400 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /products [get]
+func (pc *ProductController) GetAllProducts(c echo.Context) error {
+	query, err := parseProductListQuery(c)
+	if err != nil {
+		return err
 	}
 
-	if req.Quantity < 0 {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error": "Quantity cannot be negative",
-		})
+	page, err := pc.products(c).ListProducts(query)
+	if err != nil {
+		return err
 	}
 
-	if req.Category == "" {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error": "Category is required",
-		})
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, page)
+}
+
+// SuggestProducts maneja las sugerencias de autocompletado de productos
+// @Summary Sugerir productos
+// @Description Mejores coincidencias por prefijo de SKU o nombre y por similitud de trigramas, servidas desde un índice en memoria
+// @Tags products
+// @Produce json
+// @Security Bearer
+// @Param q query string true "Texto escrito por el usuario"
+// @Param limit query int false "Máximo de sugerencias (por defecto 10, máximo 25)"
+// @Success 200 {array} models.ProductSuggestion
+// @Failure 400 {object} problem.Problem
+// @Router /products/suggest [get]
+func (pc *ProductController) SuggestProducts(c echo.Context) error {
+	query := strings.TrimSpace(c.QueryParam("q"))
+	if query == "" {
+		return invalidParameter("q", "error.query_required")
 	}
 
-	// Crear producto
-	product, err := pc.productService.CreateProduct(req)
+	limit, ok := queryInt(c, "limit")
+	if !ok {
+		return invalidParameter("limit", "error.invalid_value", "limit")
+	}
+
+	suggestions, err := pc.products(c).SuggestProducts(query, limit)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"error":   "Failed to create product",
-			"details": err.Error(),
-		})
+		return err
 	}
 
 	// Respuesta exitosa
-	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"message": "Product created successfully",
-		"product": product,
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"query":       query,
+		"suggestions": suggestions,
 	})
 }
 
-// GetAllProducts maneja la obtención de todos los productos
-// @Summary Listar todos los productos
-// @Description Obtiene una lista de todos los productos del inventario
+// ListTags lista las etiquetas de productos de la organización
+// @Summary Listar etiquetas
+// @Description Obtiene las etiquetas usadas en la organización con el número de productos activos de cada una
 // @Tags products
 // @Produce json
-// @Success 200 {array} models.ProductResponse
-// @Failure 500 {object} map[string]interface{}
-// @Router /products [get]
-func (pc *ProductController) GetAllProducts(c echo.Context) error {
-	// Verificar si hay parámetro de búsqueda
-	search := c.QueryParam("search")
-	if search != "" {
-		products, err := pc.productService.SearchProducts(search)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-				"error":   "Failed to search products",
-				"details": err.Error(),
-			})
-		}
-
-		return c.JSON(http.StatusOK, map[string]interface{}{
-			"products": products,
-			"total":    len(products),
-		})
+// @Security Bearer
+// @Success 200 {array} models.TagResponse
+// @Router /tags [get]
+func (pc *ProductController) ListTags(c echo.Context) error {
+	tags, err := pc.products(c).ListTags()
+	if err != nil {
+		return err
 	}
 
-	// Verificar si hay filtro por categoría
-	category := c.QueryParam("category")
-	if category != "" {
-		products, err := pc.productService.GetProductsByCategory(category)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-				"error":   "Failed to get products by category",
-				"details": err.Error(),
-			})
-		}
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"tags": tags,
+	})
+}
 
-		return c.JSON(http.StatusOK, map[string]interface{}{
-			"products": products,
-			"total":    len(products),
-		})
+// SearchProducts maneja la búsqueda de productos por relevancia
+// @Summary Buscar productos
+// @Description Búsqueda de texto completo (español e inglés) ordenada por relevancia, con fragmentos resaltados; si no hay coincidencias tolera errores de escritura
+// @Tags products
+// @Produce json
+// @Security Bearer
+// @Param q query string true "Texto a buscar (admite \"frase exacta\", OR y -palabra)"
+// @Param limit query int false "Máximo de resultados (por defecto 20, máximo 100)"
+// @Success 200 {array} models.ProductSearchResult
+// @Failure 400 {object} problem.Problem
+// @Router /products/search [get]
+func (pc *ProductController) SearchProducts(c echo.Context) error {
+	query := strings.TrimSpace(c.QueryParam("q"))
+	if query == "" {
+		return invalidParameter("q", "error.query_required")
+	}
+
+	limit, ok := queryInt(c, "limit")
+	if !ok {
+		return invalidParameter("limit", "error.invalid_value", "limit")
 	}
 
-	// Obtener todos los productos
-	products, err := pc.productService.GetAllProducts()
+	results, err := pc.products(c).SearchProducts(query, limit)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"error":   "Failed to fetch products",
-			"details": err.Error(),
-		})
+		return err
 	}
 
 	// Respuesta exitosa
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"products": products,
-		"total":    len(products),
+		"query":   query,
+		"results": results,
+		"total":   len(results),
 	})
 }
 
 // GetProductByID maneja la obtención de un producto por ID
 // @Summary Obtener producto por ID
-// @Description Obtiene los detalles de un producto específico
+// @Description Obtiene los detalles de un producto específico, o su estado en una fecha con as_of
 // @Tags products
 // @Produce json
+// @Security Bearer
 // @Param id path int true "Product ID"
+// @Param as_of query string false "Fecha (RFC3339, o YYYY-MM-DD para el final de ese día)"
 // @Success 200 {object} models.ProductResponse
-// @Failure 400 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
 // @Router /products/{id} [get]
 func (pc *ProductController) GetProductByID(c echo.Context) error {
 	// Obtener ID del parámetro URL
-	idParam := c.Param("id")
-	id, err := strconv.ParseUint(idParam, 10, 32)
+	id, err := pathID(c, "id", "product")
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error": "Invalid product ID",
-		})
+		return err
+	}
+
+	// Vista histórica del producto
+	if asOfParam := c.QueryParam("as_of"); asOfParam != "" {
+		return pc.getProductAsOf(c, id, asOfParam)
 	}
 
 	// Obtener producto
-	product, err := pc.productService.GetProductByID(uint(id))
+	product, err := pc.products(c).GetProductByID(id)
 	if err != nil {
-		if err.Error() == "product not found" {
-			return c.JSON(http.StatusNotFound, map[string]interface{}{
-				"error": "Product not found",
-			})
-		}
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"error":   "Failed to fetch product",
-			"details": err.Error(),
-		})
+		return err
 	}
 
 	// Respuesta exitosa
@@ -196,129 +241,150 @@ func (pc *ProductController) GetProductByID(c echo.Context) error {
 // @Param id path int true "Product ID"
 // @Param product body models.ProductRequest true "Datos actualizados del producto"
 // @Success 200 {object} models.ProductResponse
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
 // @Router /products/{id} [put]
 func (pc *ProductController) UpdateProduct(c echo.Context) error {
 	// Obtener ID del parámetro URL
-	idParam := c.Param("id")
-	id, err := strconv.ParseUint(idParam, 10, 32)
+	id, err := pathID(c, "id", "product")
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error": "Invalid product ID",
-		})
+		return err
 	}
 
 	var req models.ProductRequest
 
 	// Bind JSON request
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error":   "Invalid request format",
-			"details": err.Error(),
-		})
+	if err := bindRequest(c, &req); err != nil {
+		return err
 	}
 
-	// Validar campos requeridos
-	if req.Name == "" {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error": "Product name is required",
-		})
+	// Actualizar producto
+	product, err := pc.products(c).UpdateProduct(id, req)
+	if err != nil {
+		return productWriteError(err)
 	}
 
-	if req.Price < 0 {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error": "Price cannot be negative",
-		})
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "product.updated"),
+		"product": product,
+	})
+}
+
+// DeleteProduct maneja la eliminación de productos
+// @Summary Eliminar producto
+// @Description Mueve un producto a la papelera, o lo elimina definitivamente con permanent=true (requiere org:manage)
+// @Tags products
+// @Security Bearer
+// @Param id path int true "Product ID"
+// @Param permanent query bool false "Eliminar definitivamente, incluido su historial"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /products/{id} [delete]
+func (pc *ProductController) DeleteProduct(c echo.Context) error {
+	// Obtener ID del parámetro URL
+	id, err := pathID(c, "id", "product")
+	if err != nil {
+		return err
 	}
 
-	if req.Quantity < 0 {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error": "Quantity cannot be negative",
+	// Eliminación definitiva (solo administradores de la organización)
+	if c.QueryParam("permanent") == "true" {
+		permissions, _ := c.Get("permissions").([]string)
+		if !models.HasPermission(permissions, models.PermissionOrgManage) {
+			return problem.InsufficientPermissions.New().With("permission", models.PermissionOrgManage)
+		}
+
+		if err := pc.products(c).PurgeProduct(id); err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"message": translate(c, "product.deleted_permanently"),
 		})
 	}
 
-	if req.Category == "" {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error": "Category is required",
-		})
+	// Eliminar producto (a la papelera)
+	err = pc.products(c).DeleteProduct(id)
+	if err != nil {
+		return err
 	}
 
-	// Actualizar producto
-	product, err := pc.productService.UpdateProduct(uint(id), req)
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "product.trashed"),
+	})
+}
+
+// ListTrash maneja el listado de productos eliminados
+// @Summary Papelera de productos
+// @Description Lista los productos eliminados de la organización y cuándo se purgarán
+// @Tags products
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} models.TrashedProductResponse
+// @Failure 500 {object} problem.Problem
+// @Router /products/trash [get]
+func (pc *ProductController) ListTrash(c echo.Context) error {
+	products, err := pc.products(c).ListTrash()
 	if err != nil {
-		if err.Error() == "product not found" {
-			return c.JSON(http.StatusNotFound, map[string]interface{}{
-				"error": "Product not found",
-			})
-		}
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"error":   "Failed to update product",
-			"details": err.Error(),
-		})
+		return err
 	}
 
 	// Respuesta exitosa
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"message": "Product updated successfully",
-		"product": product,
+		"products": products,
+		"total":    len(products),
 	})
 }
 
-// DeleteProduct maneja la eliminación de productos
-// @Summary Eliminar producto
-// @Description Elimina un producto del inventario
+// RestoreProduct maneja la recuperación de un producto eliminado
+// @Summary Restaurar producto
+// @Description Recupera un producto de la papelera
 // @Tags products
+// @Produce json
 // @Security Bearer
 // @Param id path int true "Product ID"
-// @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Router /products/{id} [delete]
-func (pc *ProductController) DeleteProduct(c echo.Context) error {
+// @Success 200 {object} models.ProductResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /products/{id}/restore [post]
+func (pc *ProductController) RestoreProduct(c echo.Context) error {
 	// Obtener ID del parámetro URL
-	idParam := c.Param("id")
-	id, err := strconv.ParseUint(idParam, 10, 32)
+	id, err := pathID(c, "id", "product")
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error": "Invalid product ID",
-		})
+		return err
 	}
 
-	// Eliminar producto
-	err = pc.productService.DeleteProduct(uint(id))
+	product, err := pc.products(c).RestoreProduct(id)
 	if err != nil {
-		if err.Error() == "product not found" {
-			return c.JSON(http.StatusNotFound, map[string]interface{}{
-				"error": "Product not found",
-			})
-		}
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"error":   "Failed to delete product",
-			"details": err.Error(),
-		})
+		return err
 	}
 
 	// Respuesta exitosa
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"message": "Product deleted successfully",
+		"message": translate(c, "product.restored"),
+		"product": product,
 	})
 }
 
 // GetLowStockProducts maneja la obtención de productos con stock bajo
 // @Summary Productos con stock bajo
-// @Description Obtiene productos con cantidad menor al umbral especificado
+// @Description Obtiene los productos que no están en estado normal según las reglas de stock, o con cantidad menor al umbral si se especifica
 // @Tags products
 // @Produce json
-// @Param threshold query int false "Umbral de stock bajo (default: 5)"
+// @Security Bearer
+// @Param threshold query int false "Umbral de stock bajo (por defecto, las reglas de stock)"
 // @Success 200 {array} models.ProductResponse
-// @Failure 500 {object} map[string]interface{}
+// @Failure 500 {object} problem.Problem
 // @Router /products/low-stock [get]
 func (pc *ProductController) GetLowStockProducts(c echo.Context) error {
-	// Obtener umbral de los query parameters
-	threshold := 5 // Valor por defecto
+	// Obtener umbral de los query parameters (0: reglas de stock)
+	threshold := 0
 	if thresholdParam := c.QueryParam("threshold"); thresholdParam != "" {
 		if t, err := strconv.Atoi(thresholdParam); err == nil && t > 0 {
 			threshold = t
@@ -326,36 +392,33 @@ func (pc *ProductController) GetLowStockProducts(c echo.Context) error {
 	}
 
 	// Obtener productos con stock bajo
-	products, err := pc.productService.GetLowStockProducts(threshold)
+	products, err := pc.products(c).GetLowStockProducts(threshold)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"error":   "Failed to fetch low stock products",
-			"details": err.Error(),
-		})
+		return err
 	}
 
 	// Respuesta exitosa
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"products":  products,
 		"total":     len(products),
-		"threshold": threshold,
+		"threshold": optionalThreshold(threshold),
 	})
 }
 
 // GenerateAlerts maneja la generación de alertas usando concurrencia
 // @Summary Generar alertas de stock
-// @Description Genera alertas de productos con stock bajo usando goroutines
+// @Description Genera alertas de productos con stock bajo usando goroutines, con la severidad de sus reglas de stock
 // @Tags products
 // @Produce json
 // @Security Bearer
-// @Param threshold query int false "Umbral para alertas (default: 5)"
+// @Param threshold query int false "Umbral para alertas (por defecto, las reglas de stock)"
 // @Success 200 {array} models.ProductAlert
-// @Failure 401 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /products/alerts [get]
 func (pc *ProductController) GenerateAlerts(c echo.Context) error {
-	// Obtener umbral de los query parameters
-	threshold := 5 // Valor por defecto
+	// Obtener umbral de los query parameters (0: reglas de stock)
+	threshold := 0
 	if thresholdParam := c.QueryParam("threshold"); thresholdParam != "" {
 		if t, err := strconv.Atoi(thresholdParam); err == nil && t > 0 {
 			threshold = t
@@ -363,39 +426,42 @@ func (pc *ProductController) GenerateAlerts(c echo.Context) error {
 	}
 
 	// Generar alertas con concurrencia
-	alerts, err := pc.productService.GenerateAlertsWithConcurrency(threshold)
+	alerts, err := pc.products(c).GenerateAlertsWithConcurrency(threshold)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"error":   "Failed to generate alerts",
-			"details": err.Error(),
-		})
+		return err
 	}
 
 	// Respuesta exitosa
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"alerts":    alerts,
 		"total":     len(alerts),
-		"threshold": threshold,
-		"message":   "Alerts generated using concurrent processing",
+		"threshold": optionalThreshold(threshold),
+		"message":   translate(c, "product.alerts_generated"),
 	})
 }
 
+// optionalThreshold retorna el umbral explícito de la petición, o nil si se usan las reglas de stock
+func optionalThreshold(threshold int) interface{} {
+	if threshold <= 0 {
+		return nil
+	}
+	return threshold
+}
+
 // GetInventoryStats maneja la obtención de estadísticas del inventario
 // @Summary Estadísticas del inventario
 // @Description Obtiene estadísticas generales del inventario
 // @Tags products
 // @Produce json
+// @Security Bearer
 // @Success 200 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Failure 500 {object} problem.Problem
 // @Router /products/stats [get]
 func (pc *ProductController) GetInventoryStats(c echo.Context) error {
 	// Obtener estadísticas
-	stats, err := pc.productService.GetInventoryStats()
+	stats, err := pc.products(c).GetInventoryStats()
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"error":   "Failed to fetch inventory statistics",
-			"details": err.Error(),
-		})
+		return err
 	}
 
 	// Respuesta exitosa
@@ -414,56 +480,185 @@ func (pc *ProductController) GetInventoryStats(c echo.Context) error {
 // @Param id path int true "Product ID"
 // @Param stock body map[string]int true "Nueva cantidad"
 // @Success 200 {object} models.ProductResponse
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
 // @Router /products/{id}/stock [put]
 func (pc *ProductController) UpdateStock(c echo.Context) error {
 	// Obtener ID del parámetro URL
-	idParam := c.Param("id")
-	id, err := strconv.ParseUint(idParam, 10, 32)
+	id, err := pathID(c, "id", "product")
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error": "Invalid product ID",
-		})
+		return err
 	}
 
 	var req struct {
-		Quantity int `json:"quantity" validate:"required,min=0"`
+		Quantity int `json:"quantity" validate:"min=0"`
 	}
 
 	// Bind JSON request
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error":   "Invalid request format",
-			"details": err.Error(),
-		})
+	if err := bindRequest(c, &req); err != nil {
+		return err
 	}
 
-	// Validar cantidad
-	if req.Quantity < 0 {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error": "Quantity cannot be negative",
-		})
+	// Actualizar stock
+	product, err := pc.products(c).UpdateStock(id, req.Quantity)
+	if err != nil {
+		return err
 	}
 
-	// Actualizar stock
-	product, err := pc.productService.UpdateStock(uint(id), req.Quantity)
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "product.stock_updated"),
+		"product": product,
+	})
+}
+
+// GetProductHistory maneja la consulta del historial de versiones de un producto
+// @Summary Historial de un producto
+// @Description Lista todas las versiones de un producto (altas, ediciones y cambios de stock)
+// @Tags products
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Product ID"
+// @Success 200 {array} models.ProductVersion
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /products/{id}/history [get]
+func (pc *ProductController) GetProductHistory(c echo.Context) error {
+	// Obtener ID del parámetro URL
+	id, err := pathID(c, "id", "product")
 	if err != nil {
-		if err.Error() == "product not found" {
-			return c.JSON(http.StatusNotFound, map[string]interface{}{
-				"error": "Product not found",
-			})
+		return err
+	}
+
+	versions, err := pc.products(c).GetProductHistory(id)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"product_id": id,
+		"versions":   versions,
+		"total":      len(versions),
+	})
+}
+
+// getProductAsOf responde con el estado de un producto en la fecha indicada
+// Una fecha sin hora se interpreta como el final de ese día
+func (pc *ProductController) getProductAsOf(c echo.Context, id uint, asOfParam string) error {
+	asOf, dateOnly, err := parseTimeParam(asOfParam)
+	if err != nil {
+		return invalidParameter("as_of", "error.invalid_date", "as_of")
+	}
+	if dateOnly {
+		asOf = asOf.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	product, version, err := pc.products(c).GetProductAsOf(id, asOf)
+	if err != nil {
+		if errors.Is(err, services.ErrProductNotFound) {
+			return problem.ProductNotFound.Message("error.product_not_found_as_of")
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"error":   "Failed to update stock",
-			"details": err.Error(),
-		})
+		return err
 	}
 
 	// Respuesta exitosa
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"message": "Stock updated successfully",
 		"product": product,
+		"as_of":   asOf,
+		"version": version.Version,
 	})
 }
+
+// productWriteError traduce los errores de crear o actualizar un producto: una categoría que
+// no existe es un dato no válido de la petición, no un recurso que falta
+func productWriteError(err error) error {
+	if errors.Is(err, services.ErrCategoryNotFound) {
+		return problem.InvalidCategory.New()
+	}
+	return err
+}
+
+// legacyProductFilters traduce los parámetros de filtro sueltos a la sintaxis filter[campo][operador]
+var legacyProductFilters = map[string]models.ProductFilter{
+	"search":        {Field: "search", Op: models.FilterOpEq},
+	"category":      {Field: "category", Op: models.FilterOpEq},
+	"stock_status":  {Field: "stock_status", Op: models.FilterOpEq},
+	"min_price":     {Field: "price", Op: models.FilterOpGte},
+	"max_price":     {Field: "price", Op: models.FilterOpLte},
+	"min_quantity":  {Field: "quantity", Op: models.FilterOpGte},
+	"max_quantity":  {Field: "quantity", Op: models.FilterOpLte},
+	"updated_since": {Field: "updated_at", Op: models.FilterOpGte},
+}
+
+// parseProductListQuery lee la paginación, el orden y los filtros del listado de productos
+// Los filtros usan la sintaxis filter[campo][operador]=valor; filter[campo]=valor equivale a eq,
+// y una lista separada por comas, a in
+func parseProductListQuery(c echo.Context) (models.ProductListQuery, error) {
+	query := models.ProductListQuery{
+		Cursor: c.QueryParam("cursor"),
+		Sort:   c.QueryParam("sort"),
+	}
+
+	var ok bool
+	if query.Limit, ok = queryInt(c, "limit"); !ok {
+		return query, invalidParameter("limit", "error.invalid_value", "limit")
+	}
+
+	switch strings.ToLower(c.QueryParam("order")) {
+	case "", "asc":
+	case "desc":
+		query.Desc = true
+	default:
+		return query, invalidParameter("order", "error.invalid_order")
+	}
+
+	for key, values := range c.QueryParams() {
+		filter, ok := legacyProductFilters[key]
+		if !ok {
+			if !strings.HasPrefix(key, "filter[") || !strings.HasSuffix(key, "]") {
+				continue
+			}
+			parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(key, "filter["), "]"), "][")
+			if len(parts) > 2 || parts[0] == "" {
+				return query, invalidParameter(key, "error.invalid_filter_syntax", key)
+			}
+			filter = models.ProductFilter{Field: parts[0], Op: models.FilterOpEq}
+			if len(parts) == 2 {
+				filter.Op = parts[1]
+			}
+		}
+
+		// Cada aparición del parámetro es una condición más; los vacíos se ignoran
+		for _, value := range values {
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+			condition := filter
+			condition.Values = splitFilterValues(filter.Field, value)
+			if len(condition.Values) > 1 && condition.Op == models.FilterOpEq {
+				condition.Op = models.FilterOpIn
+			}
+			query.Filters = append(query.Filters, condition)
+		}
+	}
+
+	return query, nil
+}
+
+// splitFilterValues separa una lista de valores separada por comas
+// El texto de búsqueda no se separa porque puede contener comas
+func splitFilterValues(field, value string) []string {
+	if field == "search" {
+		return []string{strings.TrimSpace(value)}
+	}
+
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"inventory-api/internal/models"
+	"inventory-api/internal/problem"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TestParseProductListQuery cubre la sintaxis filter[campo][operador] y sus atajos
+func TestParseProductListQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    []models.ProductFilter
+		wantBad string // Parámetro rechazado, vacío si la query es válida
+	}{
+		{
+			name:  "field with operator",
+			query: "filter[price][gte]=10",
+			want:  []models.ProductFilter{{Field: "price", Op: models.FilterOpGte, Values: []string{"10"}}},
+		},
+		{
+			name:  "field without operator is eq",
+			query: "filter[sku]=AB-1",
+			want:  []models.ProductFilter{{Field: "sku", Op: models.FilterOpEq, Values: []string{"AB-1"}}},
+		},
+		{
+			name:  "comma separated list is in",
+			query: "filter[category]=tools,%20garden,,",
+			want:  []models.ProductFilter{{Field: "category", Op: models.FilterOpIn, Values: []string{"tools", "garden"}}},
+		},
+		{
+			name:  "explicit operator keeps the list",
+			query: "filter[category][ne]=tools,garden",
+			want:  []models.ProductFilter{{Field: "category", Op: models.FilterOpNe, Values: []string{"tools", "garden"}}},
+		},
+		{
+			name:  "search text is not split",
+			query: "filter[search]=red,%20blue",
+			want:  []models.ProductFilter{{Field: "search", Op: models.FilterOpEq, Values: []string{"red, blue"}}},
+		},
+		{
+			name:  "repeated parameters are separate conditions",
+			query: "filter[tag]=a&filter[tag]=b",
+			want: []models.ProductFilter{
+				{Field: "tag", Op: models.FilterOpEq, Values: []string{"a"}},
+				{Field: "tag", Op: models.FilterOpEq, Values: []string{"b"}},
+			},
+		},
+		{
+			name:  "attribute filter",
+			query: "filter[attr.color][in]=red",
+			want:  []models.ProductFilter{{Field: "attr.color", Op: models.FilterOpIn, Values: []string{"red"}}},
+		},
+		{
+			name:  "legacy shortcuts",
+			query: "min_price=5&updated_since=2026-01-31",
+			want: []models.ProductFilter{
+				{Field: "price", Op: models.FilterOpGte, Values: []string{"5"}},
+				{Field: "updated_at", Op: models.FilterOpGte, Values: []string{"2026-01-31"}},
+			},
+		},
+		{
+			name:  "empty values and unrelated parameters are ignored",
+			query: "filter[sku]=&filter[name]=%20&page=2&filters[x]=1",
+		},
+		{name: "too many brackets", query: "filter[price][gte][x]=1", wantBad: "filter[price][gte][x]"},
+		{name: "empty field", query: "filter[][eq]=1", wantBad: "filter[][eq]"},
+		{name: "invalid order", query: "order=up", wantBad: "order"},
+		{name: "invalid limit", query: "limit=ten", wantBad: "limit"},
+	}
+
+	e := echo.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/products?"+tt.query, nil)
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			query, err := parseProductListQuery(c)
+			if tt.wantBad != "" {
+				var p *problem.Problem
+				if !errors.As(err, &p) || p.Extensions["parameter"] != tt.wantBad {
+					t.Fatalf("got error %v, want an invalid parameter %s", err, tt.wantBad)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Los parámetros de la query no tienen orden
+			sort.SliceStable(query.Filters, func(i, j int) bool { return query.Filters[i].Field < query.Filters[j].Field })
+			if !reflect.DeepEqual(query.Filters, tt.want) {
+				t.Fatalf("got filters %+v, want %+v", query.Filters, tt.want)
+			}
+		})
+	}
+}
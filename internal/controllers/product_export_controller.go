@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"inventory-api/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Formatos de exportación de productos y su tipo de contenido
+var productExportContentTypes = map[string]string{
+	"csv":   "text/csv; charset=utf-8",
+	"jsonl": "application/x-ndjson",
+	"xlsx":  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// Columnas fijas de las exportaciones tabulares; les siguen las columnas attr.<clave>
+var productExportColumns = []string{
+	"id", "sku", "name", "description", "category", "category_id", "tags",
+	"quantity", "reorder_point", "price", "stock_status", "created_at", "updated_at",
+}
+
+// productExportWriter escribe los productos exportados en un formato
+type productExportWriter interface {
+	WriteProduct(product models.ProductResponse) error
+	Close() error
+}
+
+// ExportProducts maneja la exportación del catálogo
+// @Summary Exportar productos
+// @Description Descarga los productos que cumplen los filtros del listado, en CSV, JSON Lines o XLSX, incluido stock_status. La respuesta se genera mientras se leen los productos
+// @Tags products
+// @Produce text/csv,application/x-ndjson,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security Bearer
+// @Param format query string false "csv (por defecto), jsonl o xlsx"
+// @Param sort query string false "Orden: name, price, quantity, created_at, updated_at"
+// @Param order query string false "Dirección: asc (por defecto) o desc"
+// @Param filter[field][operator] query string false "Los mismos filtros que GET /products"
+// @Success 200 {file} file
+// @Failure 400 {object} problem.Problem
+// @Router /products/export [get]
+func (pc *ProductController) ExportProducts(c echo.Context) error {
+	format := strings.ToLower(c.QueryParam("format"))
+	if format == "" {
+		format = "csv"
+	}
+	contentType, ok := productExportContentTypes[format]
+	if !ok {
+		return invalidParameter("format", "error.invalid_export_format")
+	}
+
+	query, err := parseProductListQuery(c)
+	if err != nil {
+		return err
+	}
+
+	products := pc.products(c)
+	attributeKeys, err := products.AttributeKeys()
+	if err != nil {
+		return err
+	}
+
+	// La respuesta empieza con el primer producto (o al terminar si no hay ninguno), así
+	// los errores de la consulta todavía pueden responderse como JSON
+	var writer productExportWriter
+	start := func() error {
+		filename := fmt.Sprintf("products-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+		header := c.Response().Header()
+		header.Set(echo.HeaderContentType, contentType)
+		header.Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+		c.Response().WriteHeader(http.StatusOK)
+
+		started, err := newProductExportWriter(format, c.Response(), attributeKeys)
+		if err != nil {
+			return err
+		}
+		writer = started
+		return nil
+	}
+
+	err = products.ExportProducts(query, func(product models.ProductResponse) error {
+		if writer == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return writer.WriteProduct(product)
+	})
+	if err == nil && writer == nil {
+		err = start()
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		return nil
+	}
+
+	// Con la descarga empezada ya no se puede cambiar la respuesta: se corta y se registra
+	if c.Response().Committed {
+		log.Printf("Warning: product export interrupted: %v", err)
+		return nil
+	}
+	return err
+}
+
+// newProductExportWriter crea el escritor del formato indicado sobre la respuesta
+func newProductExportWriter(format string, w io.Writer, attributeKeys []string) (productExportWriter, error) {
+	header := append([]string{}, productExportColumns...)
+	for _, key := range attributeKeys {
+		header = append(header, "attr."+key)
+	}
+
+	switch format {
+	case "jsonl":
+		return &jsonlExportWriter{encoder: json.NewEncoder(w)}, nil
+	case "xlsx":
+		sheet, err := newXLSXWriter(w, "Products")
+		if err != nil {
+			return nil, err
+		}
+		cells := make([]interface{}, len(header))
+		for i, column := range header {
+			cells[i] = column
+		}
+		if err := sheet.WriteRow(cells); err != nil {
+			return nil, err
+		}
+		return &xlsxExportWriter{sheet: sheet, attributeKeys: attributeKeys}, nil
+	default:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(header); err != nil {
+			return nil, err
+		}
+		return &csvExportWriter{writer: writer, attributeKeys: attributeKeys}, nil
+	}
+}
+
+// productExportRow retorna los valores de las columnas de un producto, en el orden de la cabecera
+// Las etiquetas se unen con el mismo separador que usa la importación
+func productExportRow(product models.ProductResponse, attributeKeys []string) []interface{} {
+	var categoryID, reorderPoint interface{}
+	if product.CategoryID != nil {
+		categoryID = *product.CategoryID
+	}
+	if product.ReorderPoint != nil {
+		reorderPoint = *product.ReorderPoint
+	}
+
+	row := []interface{}{
+		product.ID, product.SKU, product.Name, product.Description, product.Category, categoryID,
+		strings.Join(product.Tags, models.ProductImportTagSeparator),
+		product.Quantity, reorderPoint, product.Price, product.StockStatus,
+		product.CreatedAt.UTC().Format(time.RFC3339), product.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	for _, key := range attributeKeys {
+		row = append(row, product.Attributes[key])
+	}
+	return row
+}
+
+// csvExportWriter escribe los productos como CSV, compatible con POST /products/import
+type csvExportWriter struct {
+	writer        *csv.Writer
+	attributeKeys []string
+}
+
+// WriteProduct implementa productExportWriter
+func (w *csvExportWriter) WriteProduct(product models.ProductResponse) error {
+	values := productExportRow(product, w.attributeKeys)
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return w.writer.Write(record)
+}
+
+// Close implementa productExportWriter
+func (w *csvExportWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// jsonlExportWriter escribe un producto por línea con el mismo JSON que la API
+type jsonlExportWriter struct {
+	encoder *json.Encoder
+}
+
+// WriteProduct implementa productExportWriter
+func (w *jsonlExportWriter) WriteProduct(product models.ProductResponse) error {
+	return w.encoder.Encode(product)
+}
+
+// Close implementa productExportWriter
+func (w *jsonlExportWriter) Close() error {
+	return nil
+}
+
+// xlsxExportWriter escribe los productos en una hoja de cálculo con una fila por producto
+type xlsxExportWriter struct {
+	sheet         *xlsxWriter
+	attributeKeys []string
+}
+
+// WriteProduct implementa productExportWriter
+func (w *xlsxExportWriter) WriteProduct(product models.ProductResponse) error {
+	return w.sheet.WriteRow(productExportRow(product, w.attributeKeys))
+}
+
+// Close implementa productExportWriter
+func (w *xlsxExportWriter) Close() error {
+	return w.sheet.Close()
+}
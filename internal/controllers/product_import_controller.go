@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"inventory-api/internal/models"
+	"inventory-api/internal/problem"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ImportProducts maneja la importación masiva de productos desde CSV
+// @Summary Importar productos
+// @Description Crea o actualiza (por SKU) productos desde un CSV, leído fila a fila. Acepta el CSV como cuerpo (text/csv) o en el campo file de un multipart/form-data
+// @Tags products
+// @Accept text/csv,multipart/form-data
+// @Produce json,text/csv
+// @Security Bearer
+// @Param mapping[column] query string false "Asigna una columna del CSV a un campo, p. ej. mapping[Precio]=price"
+// @Param mode query string false "all_or_nothing (por defecto) o best_effort"
+// @Param dry_run query bool false "Valida y simula la importación sin guardar nada"
+// @Param delimiter query string false "Separador de columnas: , (por defecto), ; o tab"
+// @Param report query string false "csv para descargar el informe de errores como CSV"
+// @Success 200 {object} models.ProductImportResult
+// @Failure 400 {object} problem.Problem
+// @Failure 422 {object} models.ProductImportResult
+// @Router /products/import [post]
+func (pc *ProductController) ImportProducts(c echo.Context) error {
+	opts, err := parseProductImportOptions(c)
+	if err != nil {
+		return err
+	}
+
+	body, err := productImportBody(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := pc.products(c).ImportProducts(body, opts)
+	if err != nil {
+		return err
+	}
+
+	// Sin simulación, un resultado no confirmado significa que all_or_nothing encontró errores
+	status := http.StatusOK
+	if !result.Committed && !result.DryRun {
+		status = http.StatusUnprocessableEntity
+	}
+
+	if c.QueryParam("report") == "csv" {
+		return writeProductImportReport(c, status, result)
+	}
+	return c.JSON(status, result)
+}
+
+// parseProductImportOptions lee las opciones de la importación de la query string
+// Se leen de la URL para no tener que leer el cuerpo antes de procesar el CSV
+func parseProductImportOptions(c echo.Context) (models.ProductImportOptions, error) {
+	opts := models.ProductImportOptions{
+		Mode:    c.QueryParam("mode"),
+		Mapping: make(map[string]string),
+	}
+
+	if raw := c.QueryParam("dry_run"); raw != "" {
+		dryRun, err := strconv.ParseBool(raw)
+		if err != nil {
+			return opts, invalidParameter("dry_run", "error.invalid_dry_run")
+		}
+		opts.DryRun = dryRun
+	}
+
+	switch delimiter := c.QueryParam("delimiter"); delimiter {
+	case "":
+	case "tab", `\t`:
+		opts.Delimiter = '\t'
+	default:
+		r, size := utf8.DecodeRuneInString(delimiter)
+		if size != len(delimiter) || r == '"' || r == '\r' || r == '\n' {
+			return opts, invalidParameter("delimiter", "error.invalid_delimiter")
+		}
+		opts.Delimiter = r
+	}
+
+	switch c.QueryParam("report") {
+	case "", "json", "csv":
+	default:
+		return opts, invalidParameter("report", "error.invalid_report_format")
+	}
+
+	for key, values := range c.QueryParams() {
+		if !strings.HasPrefix(key, "mapping[") || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		column := strings.TrimSuffix(strings.TrimPrefix(key, "mapping["), "]")
+		opts.Mapping[column] = strings.TrimSpace(values[0])
+	}
+	return opts, nil
+}
+
+// productImportBody retorna el CSV de la petición sin leerlo entero: el cuerpo si es text/csv,
+// o la parte file si es multipart/form-data
+func productImportBody(c echo.Context) (io.Reader, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if mediaType != echo.MIMEMultipartForm {
+		return c.Request().Body, nil
+	}
+
+	reader, err := c.Request().MultipartReader()
+	if err != nil {
+		return nil, problem.InvalidRequest.Message("error.invalid_multipart")
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, problem.InvalidRequest.Message("error.missing_file")
+		}
+		if err != nil {
+			return nil, problem.InvalidRequest.Message("error.invalid_multipart")
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+	}
+}
+
+// writeProductImportReport responde con el informe de errores como CSV descargable
+// El resumen de la importación se envía en las cabeceras X-Import-*
+func writeProductImportReport(c echo.Context, status int, result *models.ProductImportResult) error {
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	header.Set(echo.HeaderContentDisposition, `attachment; filename="product-import-errors.csv"`)
+	header.Set("X-Import-Rows", strconv.Itoa(result.Rows))
+	header.Set("X-Import-Created", strconv.Itoa(result.Created))
+	header.Set("X-Import-Updated", strconv.Itoa(result.Updated))
+	header.Set("X-Import-Failed", strconv.Itoa(result.Failed))
+	header.Set("X-Import-Committed", strconv.FormatBool(result.Committed))
+	c.Response().WriteHeader(status)
+
+	writer := csv.NewWriter(c.Response())
+	if err := writer.Write([]string{"row", "sku", "column", "error"}); err != nil {
+		return err
+	}
+	for _, rowErr := range result.Errors {
+		if err := writer.Write([]string{strconv.Itoa(rowErr.Row), rowErr.SKU, rowErr.Column, rowErr.Error}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
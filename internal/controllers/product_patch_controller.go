@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"io"
+	"mime"
+	"net/http"
+
+	"inventory-api/internal/models"
+	"inventory-api/internal/problem"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxProductPatchSize es el tamaño máximo del cuerpo de PATCH /products/:id
+const maxProductPatchSize = 1 << 20
+
+// acceptPatch es el valor de la cabecera Accept-Patch (RFC 5789) de los productos
+var acceptPatch = models.MergePatchContentType + ", " + models.JSONPatchContentType
+
+// PatchProduct maneja la actualización parcial de productos
+// @Summary Actualizar parcialmente un producto
+// @Description Cambia solo los campos que incluye el parche, con JSON Merge Patch (application/merge-patch+json) o JSON Patch (application/json-patch+json). El producto resultante se valida con las mismas reglas que PUT
+// @Tags products
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Product ID"
+// @Param patch body object true "Parche sobre los campos de models.ProductRequest"
+// @Success 200 {object} models.ProductResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 415 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Router /products/{id} [patch]
+func (pc *ProductController) PatchProduct(c echo.Context) error {
+	id, err := pathID(c, "id", "product")
+	if err != nil {
+		return err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if mediaType != models.MergePatchContentType && mediaType != models.JSONPatchContentType {
+		c.Response().Header().Set("Accept-Patch", acceptPatch)
+		return problem.UnsupportedMediaType.Message("error.unsupported_patch").
+			With("accepted", []string{models.MergePatchContentType, models.JSONPatchContentType})
+	}
+
+	patch, err := io.ReadAll(io.LimitReader(c.Request().Body, maxProductPatchSize+1))
+	if err != nil {
+		return problem.InvalidRequest.Message("error.unreadable_body")
+	}
+	if len(patch) > maxProductPatchSize {
+		return problem.PayloadTooLarge.Message("error.patch_too_large")
+	}
+
+	product, err := pc.products(c).PatchProduct(id, mediaType, patch)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "product.updated"),
+		"product": product,
+	})
+}
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"net/http"
+
+	"inventory-api/internal/models"
+	"inventory-api/internal/services"
+	"inventory-api/internal/validation"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// StockRuleController maneja los endpoints de las reglas de stock
+type StockRuleController struct {
+	stockRuleService *services.StockRuleService
+}
+
+// NewStockRuleController crea una nueva instancia del controlador de reglas de stock
+func NewStockRuleController(db *gorm.DB) *StockRuleController {
+	return &StockRuleController{
+		stockRuleService: services.NewStockRuleService(db),
+	}
+}
+
+// stockRules retorna el servicio de reglas de stock limitado a la organización de la petición
+// y que atribuye los cambios al usuario autenticado
+func (sc *StockRuleController) stockRules(c echo.Context) *services.StockRuleService {
+	orgID, _ := c.Get("org_id").(uint)
+	return sc.stockRuleService.WithTenant(orgID).WithActor(auditContext(c))
+}
+
+// ListStockRules lista las reglas de stock de la organización
+// @Summary Listar reglas de stock
+// @Description Obtiene la regla global y las reglas por categoría y por producto que calculan el estado de stock y la severidad de las alertas
+// @Tags stock-rules
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} models.StockRule
+// @Router /stock-rules [get]
+func (sc *StockRuleController) ListStockRules(c echo.Context) error {
+	rules, err := sc.stockRules(c).ListStockRules()
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"rules":    rules,
+		"defaults": models.DefaultStockBands,
+	})
+}
+
+// CreateStockRule crea una regla de stock
+// @Summary Crear regla de stock
+// @Description Define las bandas de estado (en unidades o en porcentaje del punto de pedido) y sus severidades, para toda la organización, una categoría y sus subcategorías o un producto
+// @Tags stock-rules
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param rule body models.StockRuleRequest true "Regla de stock"
+// @Success 201 {object} models.StockRule
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Router /stock-rules [post]
+func (sc *StockRuleController) CreateStockRule(c echo.Context) error {
+	var req models.StockRuleRequest
+
+	// Bind JSON request
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+	if req.Scope == "" {
+		return validation.Required("scope")
+	}
+
+	rule, err := sc.stockRules(c).CreateStockRule(req)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": translate(c, "stock_rule.created"),
+		"rule":    rule,
+	})
+}
+
+// UpdateStockRule cambia una regla de stock
+// @Summary Actualizar regla de stock
+// @Description Cambia el modo, los umbrales o las severidades; el ámbito y su categoría o producto no se pueden cambiar
+// @Tags stock-rules
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Stock rule ID"
+// @Param rule body models.StockRuleRequest true "Regla de stock"
+// @Success 200 {object} models.StockRule
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /stock-rules/{id} [put]
+func (sc *StockRuleController) UpdateStockRule(c echo.Context) error {
+	id, err := pathID(c, "id", "stock rule")
+	if err != nil {
+		return err
+	}
+
+	var req models.StockRuleRequest
+
+	// Bind JSON request
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	rule, err := sc.stockRules(c).UpdateStockRule(id, req)
+	if err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "stock_rule.updated"),
+		"rule":    rule,
+	})
+}
+
+// DeleteStockRule elimina una regla de stock
+// @Summary Eliminar regla de stock
+// @Description Elimina la regla; sus productos pasan a usar la regla del siguiente ámbito o los umbrales por defecto
+// @Tags stock-rules
+// @Security Bearer
+// @Param id path int true "Stock rule ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} problem.Problem
+// @Router /stock-rules/{id} [delete]
+func (sc *StockRuleController) DeleteStockRule(c echo.Context) error {
+	id, err := pathID(c, "id", "stock rule")
+	if err != nil {
+		return err
+	}
+
+	if err := sc.stockRules(c).DeleteStockRule(id); err != nil {
+		return err
+	}
+
+	// Respuesta exitosa
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": translate(c, "stock_rule.deleted"),
+	})
+}
@@ -79,16 +79,120 @@ func AutoMigrate(db *gorm.DB) error {
 	err := db.AutoMigrate(
 		&models.User{},
 		&models.Product{},
+		&models.APIKey{},
+		&models.MFARecoveryCode{},
+		&models.UserToken{},
+		&models.AuthEvent{},
+		&models.LoginThrottle{},
+		&models.OIDCLoginState{},
+		&models.Organization{},
+		&models.Membership{},
+		&models.AuditLog{},
+		&models.ProductVersion{},
+		&models.Category{},
+		&models.Tag{},
+		&models.AttributeDefinition{},
+		&models.StockRule{},
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	// Las migraciones SQL (p. ej. 0002_product_categories) agrupan los productos por
+	// organización, así que los datos anteriores a los tenants deben tener una antes
+	if err := backfillDefaultOrganization(db); err != nil {
+		return fmt.Errorf("failed to assign existing data to an organization: %w", err)
+	}
+
+	if err := RunMigrations(db); err != nil {
+		return err
+	}
+
+	if err := backfillProductVersions(db); err != nil {
+		return fmt.Errorf("failed to create initial product versions: %w", err)
+	}
+
 	log.Println("✅ Migrations completed successfully")
 	return nil
 }
 
+// backfillDefaultOrganization asigna a una organización "default" los datos creados
+// antes de introducir los tenants: usuarios, productos y API keys sin organización
+// Solo se ejecuta mientras no exista ninguna organización; después, un usuario sin
+// membresías (p. ej. expulsado de todas) no debe volver a ganar acceso a ningún tenant
+func backfillDefaultOrganization(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var orgCount int64
+		if err := tx.Model(&models.Organization{}).Count(&orgCount).Error; err != nil {
+			return err
+		}
+		if orgCount > 0 {
+			return nil
+		}
+
+		var orphanUsers, orphanProducts, orphanKeys int64
+		if err := tx.Model(&models.User{}).Count(&orphanUsers).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Product{}).
+			Where("organization_id IS NULL OR organization_id = 0").
+			Count(&orphanProducts).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.APIKey{}).
+			Where("organization_id IS NULL OR organization_id = 0").
+			Count(&orphanKeys).Error; err != nil {
+			return err
+		}
+		if orphanUsers == 0 && orphanProducts == 0 && orphanKeys == 0 {
+			return nil
+		}
+
+		log.Printf("🏢 Assigning %d users, %d products and %d API keys to the default organization...",
+			orphanUsers, orphanProducts, orphanKeys)
+
+		org := models.Organization{Name: "Default organization", Slug: "default"}
+		if err := tx.Create(&org).Error; err != nil {
+			return err
+		}
+
+		// Los administradores globales pasan a propietarios y el resto a miembros
+		if err := tx.Exec(`
+			INSERT INTO memberships (organization_id, user_id, role, created_at, updated_at)
+			SELECT ?, users.id, CASE WHEN users.role = ? THEN ? ELSE ? END, NOW(), NOW()
+			FROM users`,
+			org.ID, models.RoleAdmin, models.OrgRoleOwner, models.OrgRoleMember,
+		).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("UPDATE products SET organization_id = ? WHERE organization_id IS NULL OR organization_id = 0", org.ID).Error; err != nil {
+			return err
+		}
+		return tx.Exec("UPDATE api_keys SET organization_id = ? WHERE organization_id IS NULL OR organization_id = 0", org.ID).Error
+	})
+}
+
+// backfillProductVersions crea la versión inicial de los productos anteriores al historial
+// El historial de esos productos empieza con su estado actual, vigente desde su creación
+func backfillProductVersions(db *gorm.DB) error {
+	result := db.Exec(`
+		INSERT INTO product_versions (product_id, organization_id, version, change_type, sku, name, description, quantity, price, category, attributes, valid_from)
+		SELECT p.id, p.organization_id, 1, ?, p.sku, p.name, p.description, p.quantity, p.price, p.category, p.attributes, p.created_at
+		FROM products p
+		WHERE NOT EXISTS (SELECT 1 FROM product_versions v WHERE v.product_id = p.id)`,
+		models.ProductChangeCreate,
+	)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("🕓 Created initial versions for %d existing products", result.RowsAffected)
+	}
+	return nil
+}
+
 // CreateIndexes crea índices para optimizar consultas
 func CreateIndexes(db *gorm.DB) error {
 	log.Println("🔍 Creating database indexes...")
@@ -103,6 +207,14 @@ func CreateIndexes(db *gorm.DB) error {
 		log.Printf("Warning: Failed to create quantity index: %v", err)
 	}
 
+	// Índices para la paginación por cursor de cada orden del listado (organización, campo, id)
+	for _, field := range models.ProductSortFields {
+		sql := fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_products_org_%s_id ON products(organization_id, %s, id)", field, field)
+		if err := db.Exec(sql).Error; err != nil {
+			log.Printf("Warning: Failed to create %s sort index: %v", field, err)
+		}
+	}
+
 	// Índice para emails de usuarios (único)
 	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users(email)").Error; err != nil {
 		log.Printf("Warning: Failed to create email index: %v", err)
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// migration es un cambio de esquema en SQL que AutoMigrate no puede expresar
+// (extensiones, columnas generadas, índices GIN...). Cada una se aplica una sola vez
+type migration struct {
+	ID         string
+	Statements []string
+}
+
+// migrations se aplican en orden; nunca se modifica una ya publicada, se añade otra
+var migrations = []migration{
+	{
+		// Búsqueda de texto completo (español e inglés) y por similitud de trigramas
+		// La columna generada se calcula también para las filas existentes al crearla
+		ID: "0001_product_search",
+		Statements: []string{
+			`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+			`ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector
+				GENERATED ALWAYS AS (
+					setweight(to_tsvector('spanish', coalesce(name, '')), 'A') ||
+					setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+					setweight(to_tsvector('spanish', coalesce(description, '')), 'B') ||
+					setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+					setweight(to_tsvector('simple', coalesce(category, '')), 'C')
+				) STORED`,
+			`CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector)`,
+			`CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (name gin_trgm_ops)`,
+			`CREATE INDEX IF NOT EXISTS idx_products_description_trgm ON products USING GIN (description gin_trgm_ops)`,
+		},
+	},
+	{
+		// Convierte las categorías de texto libre en filas de categories. Las variantes con
+		// el mismo slug ("Electronics", "electronics ") se unen bajo el nombre más usado
+		// El slug se calcula como slugify: minúsculas, sin tildes y con guiones
+		// Las categorías vacías o sin letras ni números se llaman "Uncategorized"
+		ID: "0002_product_categories",
+		Statements: []string{
+			`INSERT INTO categories (organization_id, name, slug, created_at, updated_at)
+				SELECT DISTINCT ON (organization_id, slug) organization_id,
+					CASE WHEN name ~ '[[:alnum:]]' THEN left(name, 50) ELSE 'Uncategorized' END, slug, now(), now()
+				FROM (
+					SELECT organization_id, trim(category) AS name, ` + categorySlugSQL + ` AS slug, COUNT(*) AS total
+					FROM products
+					WHERE category_id IS NULL
+					GROUP BY organization_id, trim(category), ` + categorySlugSQL + `
+				) variants
+				ORDER BY organization_id, slug, total DESC, name
+				ON CONFLICT (organization_id, slug) DO NOTHING`,
+			`UPDATE products p SET category_id = c.id, category = c.name
+				FROM categories c
+				WHERE p.category_id IS NULL AND c.organization_id = p.organization_id
+					AND c.slug = ` + productCategorySlugSQL,
+		},
+	},
+	{
+		// Índice para los filtros filter[attr.clave], que usan la contención de JSONB (@>)
+		ID: "0003_product_attributes",
+		Statements: []string{
+			`CREATE INDEX IF NOT EXISTS idx_products_attributes ON products USING GIN (attributes jsonb_path_ops)`,
+		},
+	},
+	{
+		// Una sola regla de stock por destino: la global de cada organización, y una por
+		// categoría y por producto. Los índices parciales no se pueden declarar con AutoMigrate
+		ID: "0004_stock_rules",
+		Statements: []string{
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_rules_global ON stock_rules (organization_id) WHERE scope = 'global'`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_rules_category ON stock_rules (category_id) WHERE scope = 'category'`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_rules_product ON stock_rules (product_id) WHERE scope = 'product'`,
+		},
+	},
+}
+
+// categorySlugSQL calcula en SQL el mismo slug que slugify para la columna category
+// Las categorías sin letras ni números quedan como "uncategorized"
+const categorySlugSQL = `coalesce(nullif(trim(trailing '-' from left(trim(both '-' from
+	regexp_replace(lower(translate(category, 'ÁÉÍÓÚÜÑÀÈÌÒÙÇáéíóúüñàèìòùç', 'AEIOUUNAEIOUCaeiouunaeiouc')), '[^a-z0-9]+', '-', 'g')
+), 64)), ''), 'uncategorized')`
+
+// productCategorySlugSQL es categorySlugSQL sobre la columna de la tabla con alias p
+var productCategorySlugSQL = strings.ReplaceAll(categorySlugSQL, "(category,", "(p.category,")
+
+// schemaMigration registra las migraciones SQL ya aplicadas
+type schemaMigration struct {
+	ID string `gorm:"primaryKey;size:128"`
+}
+
+// TableName especifica el nombre de la tabla
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// RunMigrations aplica las migraciones SQL pendientes, cada una en su transacción
+func RunMigrations(db *gorm.DB) error {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	var applied []string
+	if err := db.Model(&schemaMigration{}).Pluck("id", &applied).Error; err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	done := make(map[string]bool, len(applied))
+	for _, id := range applied {
+		done[id] = true
+	}
+
+	for _, m := range migrations {
+		if done[m.ID] {
+			continue
+		}
+
+		log.Printf("📜 Applying migration %s...", m.ID)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, statement := range m.Statements {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Create(&schemaMigration{ID: m.ID}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %s failed: %w", m.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package i18n
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Language es un idioma de la API con su etiqueta BCP 47 principal
+type Language string
+
+// Idiomas con catálogo de mensajes
+const (
+	English Language = "en"
+	Spanish Language = "es"
+)
+
+// Languages son los idiomas disponibles
+var Languages = []Language{English, Spanish}
+
+// catalogues son los mensajes de cada idioma por código; el inglés es el catálogo de referencia
+var catalogues = map[Language]map[string]string{
+	English: english,
+	Spanish: spanish,
+}
+
+// defaultLanguage es el idioma de las peticiones sin preferencia (DEFAULT_LANGUAGE)
+var defaultLanguage = loadDefaultLanguage()
+
+func loadDefaultLanguage() Language {
+	value := os.Getenv("DEFAULT_LANGUAGE")
+	if value == "" {
+		return English
+	}
+	lang, ok := Parse(value)
+	if !ok {
+		log.Printf("Warning: unsupported DEFAULT_LANGUAGE=%q, using %s", value, English)
+		return English
+	}
+	return lang
+}
+
+// Default retorna el idioma de las peticiones que no indican ninguno
+func Default() Language {
+	return defaultLanguage
+}
+
+// Parse convierte una etiqueta de idioma (es, en-US, ES-mx...) en un idioma disponible
+func Parse(tag string) (Language, bool) {
+	primary := strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(primary, "-_"); i >= 0 {
+		primary = primary[:i]
+	}
+	lang := Language(primary)
+	if _, ok := catalogues[lang]; !ok {
+		return "", false
+	}
+	return lang, true
+}
+
+// Negotiate elige el idioma disponible que el cliente prefiere según la cabecera
+// Accept-Language (p. ej. "es-ES,es;q=0.9,en;q=0.8"). Sin coincidencias retorna Default
+func Negotiate(acceptLanguage string) Language {
+	type candidate struct {
+		lang    Language
+		quality float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, quality := part, 1.0
+		if i := strings.Index(part, ";"); i >= 0 {
+			tag = part[:i]
+			param := strings.TrimSpace(part[i+1:])
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			if err != nil {
+				continue
+			}
+			quality = q
+		}
+		if quality <= 0 {
+			continue
+		}
+		if strings.TrimSpace(tag) == "*" {
+			candidates = append(candidates, candidate{defaultLanguage, quality})
+			continue
+		}
+		if lang, ok := Parse(tag); ok {
+			candidates = append(candidates, candidate{lang, quality})
+		}
+	}
+	if len(candidates) == 0 {
+		return defaultLanguage
+	}
+
+	// A igual calidad gana el primero de la cabecera
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	return candidates[0].lang
+}
+
+// Lookup retorna el mensaje de un código en el idioma indicado, sin recurrir a otro idioma
+func Lookup(lang Language, key string) (string, bool) {
+	message, ok := catalogues[lang][key]
+	return message, ok
+}
+
+// T retorna el mensaje de un código en el idioma indicado, con los argumentos aplicados
+// (fmt). Si el idioma no lo traduce se usa el inglés, y si tampoco existe el propio código
+// Los argumentos de tipo Message se traducen al mismo idioma
+func T(lang Language, key string, args ...interface{}) string {
+	message, ok := Lookup(lang, key)
+	if !ok {
+		if message, ok = Lookup(English, key); !ok {
+			log.Printf("Warning: missing message %q", key)
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return message
+	}
+	localized := make([]interface{}, len(args))
+	for i, arg := range args {
+		if nested, ok := arg.(Message); ok {
+			arg = nested.In(lang)
+		}
+		localized[i] = arg
+	}
+	return fmt.Sprintf(message, localized...)
+}
+
+// Message es un mensaje del catálogo con sus argumentos, pendiente de traducir
+// Los errores de los servicios lo usan porque el idioma solo se conoce al responder
+type Message struct {
+	Key  string
+	Args []interface{}
+}
+
+// Msg crea un mensaje del catálogo con sus argumentos
+func Msg(key string, args ...interface{}) Message {
+	return Message{Key: key, Args: args}
+}
+
+// In retorna el mensaje en el idioma indicado
+func (m Message) In(lang Language) string {
+	return T(lang, m.Key, m.Args...)
+}
+
+// String retorna el mensaje en inglés, p. ej. para los logs
+func (m Message) String() string {
+	return m.In(English)
+}
@@ -0,0 +1,150 @@
+package i18n
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want Language
+		ok   bool
+	}{
+		{"es", Spanish, true},
+		{"en", English, true},
+		{" ES-mx ", Spanish, true},
+		{"en_GB", English, true},
+		{"fr", "", false},
+		{"", "", false},
+		{"esperanto", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := Parse(tt.tag); got != tt.want || ok != tt.ok {
+			t.Errorf("Parse(%q) = %q, %v, want %q, %v", tt.tag, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	defer func(lang Language) { defaultLanguage = lang }(defaultLanguage)
+
+	tests := []struct {
+		header   string
+		fallback Language
+		want     Language
+	}{
+		{"", English, English},
+		{"", Spanish, Spanish},
+		{"es", English, Spanish},
+		{"es-ES,es;q=0.9,en;q=0.8", English, Spanish},
+		{"en;q=0.5,es;q=0.8", English, Spanish},
+		{"fr-FR,fr;q=0.9,en;q=0.7,es;q=0.6", Spanish, English},
+		{"es;q=0.8,en;q=0.8", English, Spanish}, // A igual calidad, el primero
+		{"fr,de", Spanish, Spanish},
+		{"*", Spanish, Spanish},
+		{"fr,*;q=0.5", English, English},
+		{"es;q=0,en", Spanish, English},         // q=0 excluye el idioma
+		{"es;q=abc,en;q=0.1", Spanish, English}, // Calidad no válida
+		{"es;level=1,en", Spanish, English},     // Parámetro que no es q
+		{" es-419 ; q=1 ", English, Spanish},
+	}
+	for _, tt := range tests {
+		defaultLanguage = tt.fallback
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) with default %s = %s, want %s", tt.header, tt.fallback, got, tt.want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T(Spanish, "filter.invalid_operator", "eq, in"); got == T(English, "filter.invalid_operator", "eq, in") {
+		t.Errorf("Spanish message is not translated: %q", got)
+	}
+	if got, want := T(English, "error.too_many_operations", 500), "At most 500 operations are allowed per request"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := T(Spanish, "no.such.key"); got != "no.such.key" {
+		t.Errorf("missing key: got %q, want the key itself", got)
+	}
+
+	// Los mensajes anidados se traducen al idioma del mensaje que los contiene
+	nested := Msg("patch.operation", 2, Msg("patch.path_required"))
+	for _, lang := range Languages {
+		want := T(lang, "patch.operation", 2, T(lang, "patch.path_required"))
+		if got := nested.In(lang); got != want {
+			t.Errorf("%s: got %q, want %q", lang, got, want)
+		}
+	}
+	if nested.String() != nested.In(English) {
+		t.Errorf("String() = %q, want the English message", nested.String())
+	}
+}
+
+// formatVerbs extrae los verbos de formato de un mensaje, p. ej. %s, %d o %[1]s
+var formatVerbs = regexp.MustCompile(`%(?:\[(\d+)\])?([a-zA-Z%])`)
+
+// sampleArgs construye argumentos de ejemplo del tipo que espera cada verbo del mensaje
+func sampleArgs(message string) []interface{} {
+	var args []interface{}
+	next := 0
+	for _, verb := range formatVerbs.FindAllStringSubmatch(message, -1) {
+		if verb[2] == "%" {
+			continue
+		}
+		index := next
+		if verb[1] != "" {
+			index, _ = strconv.Atoi(verb[1])
+			index--
+		}
+		next = index + 1
+		for len(args) <= index {
+			args = append(args, nil)
+		}
+		if verb[2] == "d" {
+			args[index] = 1
+		} else {
+			args[index] = "x"
+		}
+	}
+	return args
+}
+
+// unusedArgs son los argumentos que recibe un mensaje y que el inglés no usa, pero alguna
+// traducción sí (p. ej. el nombre del parámetro en error.invalid_id)
+var unusedArgs = map[string][]interface{}{
+	"error.invalid_id": {"x"},
+}
+
+// TestCataloguesMatch comprueba que todos los catálogos tienen los mismos códigos que el inglés
+// y que sus traducciones se pueden formatear con los argumentos de los mensajes en inglés
+// Los títulos de los problemas (problem.*) solo se traducen: en inglés están en internal/problem
+func TestCataloguesMatch(t *testing.T) {
+	for lang, catalogue := range catalogues {
+		if lang == English {
+			continue
+		}
+		for key, message := range english {
+			translated, ok := catalogue[key]
+			if !ok {
+				t.Errorf("%s: missing %q", lang, key)
+				continue
+			}
+			args := append(sampleArgs(message), unusedArgs[key]...)
+			if formatted := fmt.Sprintf(message, args...); strings.Contains(formatted, "%!") {
+				t.Errorf("en: %q cannot be formatted: %s", key, formatted)
+			}
+			if formatted := fmt.Sprintf(translated, args...); strings.Contains(formatted, "%!") {
+				t.Errorf("%s: %q does not take the English arguments: %s", lang, key, formatted)
+			}
+		}
+		for key := range catalogue {
+			if _, ok := english[key]; !ok && !strings.HasPrefix(key, "problem.") {
+				t.Errorf("%s: %q is not in the English catalogue", lang, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package i18n
+
+// english es el catálogo de referencia: todo código usado por la API debe estar aquí
+// Los títulos de los tipos de problema no se repiten: en inglés son los de internal/problem
+var english = map[string]string{
+	// Errores de validación; siguen al nombre del campo ("name is required")
+	"validation.required":         "is required",
+	"validation.required_without": "is required when %s is not set",
+	"validation.email":            "must be a valid email address",
+	"validation.oneof":            "must be one of: %s",
+	"validation.sku":              "must contain only letters, digits, '.', '-' and '_', starting with a letter or digit",
+	"validation.currency":         "must be an ISO 4217 currency code, e.g. EUR",
+	"validation.min":              "must be %s or greater",
+	"validation.min_length":       "must have at least %s characters",
+	"validation.min_items":        "must have at least %s items",
+	"validation.max":              "must be %s or less",
+	"validation.max_length":       "must have at most %s characters",
+	"validation.max_items":        "must have at most %s items",
+	"validation.len_length":       "must have exactly %s characters",
+	"validation.len_items":        "must have exactly %s items",
+	"validation.rule":             "does not satisfy the %s rule",
+
+	// Alertas de stock
+	"alert.low_stock":      "Stock below threshold",
+	"alert.critical_stock": "Critical stock level",
+	"alert.out_of_stock":   "Product out of stock",
+
+	// Autenticación y cuentas
+	"auth.user_created":              "User created successfully",
+	"auth.mfa_required":              "Two-factor authentication required",
+	"auth.login_successful":          "Login successful",
+	"auth.token_refreshed":           "Token refreshed successfully",
+	"auth.organization_switched":     "Organization switched successfully",
+	"auth.language_updated":          "Language preference updated successfully",
+	"auth.verification_email_sent":   "Verification email sent",
+	"auth.email_verified":            "Email verified successfully",
+	"auth.password_reset_requested":  "If the email is registered, a password reset link has been sent",
+	"auth.password_reset":            "Password reset successfully",
+	"auth.user_unlocked":             "User unlocked successfully",
+	"mfa.setup_started":              "Scan the provisioning URI and confirm with a code at /auth/mfa/enable",
+	"mfa.enabled":                    "MFA enabled successfully. Store the recovery codes in a safe place",
+	"mfa.disabled":                   "MFA disabled successfully",
+	"mfa.recovery_codes_regenerated": "Recovery codes regenerated successfully",
+	"api_key.created":                "API key created successfully. Store it now, it will not be shown again",
+	"api_key.revoked":                "API key revoked successfully",
+
+	// Organizaciones
+	"organization.created":        "Organization created successfully",
+	"organization.member_added":   "Member added successfully",
+	"organization.member_updated": "Member updated successfully",
+	"organization.member_removed": "Member removed successfully",
+
+	// Productos y categorías
+	"product.created":             "Product created successfully",
+	"product.updated":             "Product updated successfully",
+	"product.deleted_permanently": "Product permanently deleted",
+	"product.trashed":             "Product moved to trash",
+	"product.restored":            "Product restored successfully",
+	"product.stock_updated":       "Stock updated successfully",
+	"product.alerts_generated":    "Alerts generated using concurrent processing",
+	"category.created":            "Category created successfully",
+	"category.updated":            "Category updated successfully",
+	"category.deleted":            "Category deleted successfully",
+	"attribute.created":           "Attribute created successfully",
+	"attribute.updated":           "Attribute updated successfully",
+	"attribute.deleted":           "Attribute deleted successfully",
+	"stock_rule.created":          "Stock rule created successfully",
+	"stock_rule.updated":          "Stock rule updated successfully",
+	"stock_rule.deleted":          "Stock rule deleted successfully",
+
+	// Detalles de los errores (miembro detail de los problemas)
+	"error.internal":                  "An unexpected error occurred. Quote the request_id when reporting it",
+	"error.unreadable_body":           "The request body could not be read",
+	"error.invalid_id":                "Invalid %[1]s ID",
+	"error.invalid_value":             "Invalid %s",
+	"error.invalid_date":              "Invalid %s date, use RFC3339 or YYYY-MM-DD",
+	"error.unknown_problem_type":      "Unknown problem type",
+	"error.auth_header_required":      "Authorization header or X-API-Key required",
+	"error.auth_header_format":        "Invalid authorization header format, use Bearer <token>",
+	"error.token_required":            "Token is required",
+	"error.no_active_organization":    "No active organization for this session, switch to one at /auth/switch-organization",
+	"error.user_exists":               "A user with this email already exists",
+	"error.invalid_mfa_token":         "Log in again to get a new MFA token",
+	"error.mfa_setup_not_started":     "Start the setup at /auth/mfa/setup first",
+	"error.oidc_login_rejected":       "Identity provider rejected the login",
+	"error.oidc_callback_params":      "code and state are required",
+	"error.oidc_state_mismatch":       "The login was not started in this browser",
+	"error.invalid_slug":              "Slug may only contain lowercase letters, digits and hyphens",
+	"error.organization_slug_exists":  "An organization with this slug already exists",
+	"error.invalid_role":              "Role must be one of: owner, admin, member, viewer",
+	"error.product_not_in_trash":      "Product not found in trash",
+	"error.product_not_found_as_of":   "Product not found at the requested date",
+	"error.invalid_tags":              "Tags must be non-empty names of up to 50 characters, at most %d per product",
+	"error.invalid_filter":            "Invalid filter %s: %s",
+	"error.invalid_filter_syntax":     "Invalid filter %s, use filter[field] or filter[field][operator]",
+	"error.invalid_attribute_value":   "Invalid attribute %s: %s",
+	"error.invalid_product_field":     "%s %s",
+	"error.invalid_permission":        "Invalid permission %s",
+	"error.permission_not_allowed":    "You do not have the permission %s, so an API key cannot have it either",
+	"error.invalid_order":             "order must be asc or desc",
+	"error.invalid_cursor":            "Invalid cursor, it must come from a request with the same sort and order",
+	"error.query_required":            "Query parameter q is required",
+	"error.invalid_export_format":     "Format must be csv, jsonl or xlsx",
+	"error.invalid_dry_run":           "dry_run must be true or false",
+	"error.invalid_delimiter":         "delimiter must be a single character",
+	"error.invalid_report_format":     "report must be json or csv",
+	"error.invalid_multipart":         "Invalid multipart body",
+	"error.missing_file":              "Multipart body has no file field",
+	"error.unsupported_patch":         "Unsupported patch format",
+	"error.patch_too_large":           "Patch document is too large",
+	"error.invalid_bulk_mode":         "mode must be atomic or per_item",
+	"error.no_operations":             "At least one operation is required",
+	"error.too_many_operations":       "At most %d operations are allowed per request",
+	"error.no_changes":                "A price or quantity change is required",
+	"error.invalid_mass_update":       "Invalid price or quantity change",
+	"error.filters_required":          "Send at least one filter, or \"all\": true to update every product",
+	"error.category_slug_exists":      "A category with this slug already exists",
+	"error.category_cycle":            "A category cannot be moved under itself or one of its subcategories",
+	"error.category_has_children":     "Category has subcategories, move or delete them first",
+	"error.category_has_products":     "Category has products, move them to another category first",
+	"error.attribute_exists":          "Attribute is already defined in this category branch",
+	"error.attribute_type_change":     "Delete and recreate the attribute to change its type",
+	"error.invalid_attribute_key":     "Key must start with a lowercase letter and contain only lowercase letters, digits and underscores",
+	"error.invalid_attribute_type":    "Type must be one of: string, number, bool, enum",
+	"error.invalid_attribute_label":   "Label cannot be longer than 100 characters",
+	"error.enum_options_required":     "Enum attributes require at least one option",
+	"error.stock_rule_exists":         "A rule for this scope and target already exists, update it instead",
+	"error.stock_rule_target_change":  "Delete and recreate the rule to change its scope, category or product",
+	"error.invalid_stock_rule_target": "Category rules require category_id, product rules require product_id and global rules neither",
+	"error.invalid_stock_thresholds":  "critical_threshold cannot be greater than low_threshold",
+	"error.invalid_stock_percent":     "Percent thresholds cannot be greater than 100",
+
+	// Infracciones de la política de contraseñas; siguen a "password"
+	"password.min_length": "must be at least %d characters long",
+	"password.max_length": "must be at most %d characters long",
+	"password.upper":      "must contain an uppercase letter",
+	"password.lower":      "must contain a lowercase letter",
+	"password.digit":      "must contain a digit",
+	"password.symbol":     "must contain a symbol",
+	"password.email":      "must not be the same as the email",
+	"password.common":     "is too common",
+
+	// Motivos de los filtros no válidos (error.invalid_filter)
+	"filter.invalid_attribute_key": "invalid attribute key",
+	"filter.unknown_field":         "unknown field",
+	"filter.invalid_operator":      "operator must be one of: %s",
+	"filter.single_value":          "expected a single value",
+	"filter.category_id":           "expected a category ID",
+	"filter.number":                "expected a number",
+	"filter.integer":               "expected an integer",
+	"filter.date":                  "expected RFC3339 or YYYY-MM-DD",
+	"filter.invalid_value":         "invalid value",
+	"filter.stock_status":          "stock status must be one of: out_of_stock, critical, low, normal",
+
+	// Motivos de los atributos de producto no válidos (error.invalid_attribute_value)
+	"attribute_value.not_defined": "not defined for the product category",
+	"attribute_value.string":      "expected a string",
+	"attribute_value.too_long":    "cannot be longer than %d characters",
+	"attribute_value.number":      "expected a number",
+	"attribute_value.bool":        "expected true or false",
+	"attribute_value.oneof":       "must be one of: %s",
+	"attribute_value.required":    "is required",
+
+	// Motivos de los campos de producto no válidos en parches, importaciones y operaciones
+	// por lotes; siguen al nombre del campo
+	"product_input.not_found":          "product not found",
+	"product_input.insufficient_stock": "stock cannot go below zero",
+	"product_input.sku_exists":         "already used by another product (including the trash)",
+	"product_input.invalid_category":   "category not found or invalid",
+	"product_input.invalid_tags":       "tags must be non-empty names of up to 50 characters, at most %d per product",
+	"product_input.invalid_op":         "must be create, update, delete or adjust_stock",
+	"product_input.whole_number":       "must be a whole number of at least 0",
+	"product_input.number":             "must be a number of at least 0",
+	"product_input.category_id":        "must be a category ID",
+	"product_input.not_patchable":      "is not a product field that can be changed",
+	"product_input.invalid_type":       "has an invalid type",
+
+	// Motivos de las importaciones no válidas
+	"import.invalid_mode":          "mode must be all_or_nothing or best_effort",
+	"import.empty_file":            "file is empty",
+	"import.invalid_header":        "invalid header: %s",
+	"import.too_many_rows":         "file has more than %d rows",
+	"import.unknown_mapping_field": "unknown field %q in mapping for column %q",
+	"import.duplicate_field":       "more than one column for field %q",
+	"import.column_not_found":      "mapped column %q not found in the header",
+	"import.name_or_sku_required":  "the file needs a name or sku column",
+
+	// Motivos de los parches no válidos
+	"patch.not_json":           "the patch must be a JSON document",
+	"patch.not_array":          "the patch must be an array of operations",
+	"patch.not_object":         "the patched product must be a JSON object",
+	"patch.operation":          "operation %d: %s",
+	"patch.path_required":      "path is required",
+	"patch.value_required":     "value is required for %s",
+	"patch.from_required":      "from is required for %s",
+	"patch.invalid_value":      "invalid value",
+	"patch.invalid_op":         "op must be add, remove, replace, move, copy or test",
+	"patch.move_into_itself":   "cannot move a value into itself",
+	"patch.invalid_path":       "invalid path %q",
+	"patch.path_not_found":     "path %q does not exist",
+	"patch.add_to_scalar":      "cannot add %q to a scalar value",
+	"patch.remove_from_scalar": "cannot remove %q from a scalar value",
+	"patch.remove_document":    "cannot remove the whole document",
+	"patch.invalid_index":      "invalid array index %q",
+}
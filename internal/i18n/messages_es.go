@@ -0,0 +1,282 @@
+package i18n
+
+// spanish traduce el catálogo de referencia; los códigos sin traducir se envían en inglés
+var spanish = map[string]string{
+	// Errores de validación; siguen al nombre del campo ("name es obligatorio")
+	"validation.required":         "es obligatorio",
+	"validation.required_without": "es obligatorio si no se indica %s",
+	"validation.email":            "debe ser un email válido",
+	"validation.oneof":            "debe ser uno de: %s",
+	"validation.sku":              "solo puede contener letras, dígitos, '.', '-' y '_', y debe empezar por letra o dígito",
+	"validation.currency":         "debe ser un código de moneda ISO 4217, p. ej. EUR",
+	"validation.min":              "debe ser %s o mayor",
+	"validation.min_length":       "debe tener al menos %s caracteres",
+	"validation.min_items":        "debe tener al menos %s elementos",
+	"validation.max":              "debe ser %s o menor",
+	"validation.max_length":       "debe tener como máximo %s caracteres",
+	"validation.max_items":        "debe tener como máximo %s elementos",
+	"validation.len_length":       "debe tener exactamente %s caracteres",
+	"validation.len_items":        "debe tener exactamente %s elementos",
+	"validation.rule":             "no cumple la regla %s",
+
+	// Alertas de stock
+	"alert.low_stock":      "Stock por debajo del umbral",
+	"alert.critical_stock": "Nivel de stock crítico",
+	"alert.out_of_stock":   "Producto agotado",
+
+	// Autenticación y cuentas
+	"auth.user_created":              "Usuario creado correctamente",
+	"auth.mfa_required":              "Se requiere autenticación en dos pasos",
+	"auth.login_successful":          "Sesión iniciada correctamente",
+	"auth.token_refreshed":           "Token renovado correctamente",
+	"auth.organization_switched":     "Organización cambiada correctamente",
+	"auth.language_updated":          "Preferencia de idioma actualizada correctamente",
+	"auth.verification_email_sent":   "Email de verificación enviado",
+	"auth.email_verified":            "Email verificado correctamente",
+	"auth.password_reset_requested":  "Si el email está registrado, se ha enviado un enlace para restablecer la contraseña",
+	"auth.password_reset":            "Contraseña restablecida correctamente",
+	"auth.user_unlocked":             "Usuario desbloqueado correctamente",
+	"mfa.setup_started":              "Escanea la URI de aprovisionamiento y confirma con un código en /auth/mfa/enable",
+	"mfa.enabled":                    "MFA activado correctamente. Guarda los códigos de recuperación en un lugar seguro",
+	"mfa.disabled":                   "MFA desactivado correctamente",
+	"mfa.recovery_codes_regenerated": "Códigos de recuperación regenerados correctamente",
+	"api_key.created":                "API key creada correctamente. Guárdala ahora, no se volverá a mostrar",
+	"api_key.revoked":                "API key revocada correctamente",
+
+	// Organizaciones
+	"organization.created":        "Organización creada correctamente",
+	"organization.member_added":   "Miembro añadido correctamente",
+	"organization.member_updated": "Miembro actualizado correctamente",
+	"organization.member_removed": "Miembro eliminado correctamente",
+
+	// Productos y categorías
+	"product.created":             "Producto creado correctamente",
+	"product.updated":             "Producto actualizado correctamente",
+	"product.deleted_permanently": "Producto eliminado definitivamente",
+	"product.trashed":             "Producto movido a la papelera",
+	"product.restored":            "Producto restaurado correctamente",
+	"product.stock_updated":       "Stock actualizado correctamente",
+	"product.alerts_generated":    "Alertas generadas con procesamiento concurrente",
+	"category.created":            "Categoría creada correctamente",
+	"category.updated":            "Categoría actualizada correctamente",
+	"category.deleted":            "Categoría eliminada correctamente",
+	"attribute.created":           "Atributo creado correctamente",
+	"attribute.updated":           "Atributo actualizado correctamente",
+	"attribute.deleted":           "Atributo eliminado correctamente",
+	"stock_rule.created":          "Regla de stock creada correctamente",
+	"stock_rule.updated":          "Regla de stock actualizada correctamente",
+	"stock_rule.deleted":          "Regla de stock eliminada correctamente",
+
+	// Detalles de los errores (miembro detail de los problemas)
+	"error.internal":                  "Se produjo un error inesperado. Indica el request_id al informar de él",
+	"error.unreadable_body":           "No se pudo leer el cuerpo de la petición",
+	"error.invalid_id":                "El parámetro %[2]s no es un ID válido",
+	"error.invalid_value":             "Valor de %s no válido",
+	"error.invalid_date":              "Fecha %s no válida, usa RFC3339 o AAAA-MM-DD",
+	"error.unknown_problem_type":      "Tipo de problema desconocido",
+	"error.auth_header_required":      "Se requiere la cabecera Authorization o X-API-Key",
+	"error.auth_header_format":        "Formato de la cabecera Authorization no válido, usa Bearer <token>",
+	"error.token_required":            "El token es obligatorio",
+	"error.no_active_organization":    "La sesión no tiene organización activa, cambia a una en /auth/switch-organization",
+	"error.user_exists":               "Ya existe un usuario con este email",
+	"error.invalid_mfa_token":         "Inicia sesión de nuevo para obtener otro token MFA",
+	"error.mfa_setup_not_started":     "Inicia primero la configuración en /auth/mfa/setup",
+	"error.oidc_login_rejected":       "El proveedor de identidad rechazó el login",
+	"error.oidc_callback_params":      "code y state son obligatorios",
+	"error.oidc_state_mismatch":       "El login no se inició en este navegador",
+	"error.invalid_slug":              "El slug solo puede contener letras minúsculas, dígitos y guiones",
+	"error.organization_slug_exists":  "Ya existe una organización con este slug",
+	"error.invalid_role":              "El rol debe ser uno de: owner, admin, member, viewer",
+	"error.product_not_in_trash":      "El producto no está en la papelera",
+	"error.product_not_found_as_of":   "El producto no existía en la fecha indicada",
+	"error.invalid_tags":              "Las etiquetas deben ser nombres no vacíos de hasta 50 caracteres, como máximo %d por producto",
+	"error.invalid_filter":            "Filtro %s no válido: %s",
+	"error.invalid_filter_syntax":     "Filtro %s no válido, usa filter[campo] o filter[campo][operador]",
+	"error.invalid_attribute_value":   "Atributo %s no válido: %s",
+	"error.invalid_product_field":     "%s %s",
+	"error.invalid_permission":        "Permiso %s no válido",
+	"error.permission_not_allowed":    "No tienes el permiso %s, así que una API key tampoco puede tenerlo",
+	"error.invalid_order":             "order debe ser asc o desc",
+	"error.invalid_cursor":            "Cursor no válido, debe proceder de una petición con el mismo sort y order",
+	"error.query_required":            "El parámetro q es obligatorio",
+	"error.invalid_export_format":     "El formato debe ser csv, jsonl o xlsx",
+	"error.invalid_dry_run":           "dry_run debe ser true o false",
+	"error.invalid_delimiter":         "delimiter debe ser un único carácter",
+	"error.invalid_report_format":     "report debe ser json o csv",
+	"error.invalid_multipart":         "Cuerpo multipart no válido",
+	"error.missing_file":              "El cuerpo multipart no tiene el campo file",
+	"error.unsupported_patch":         "Formato de parche no soportado",
+	"error.patch_too_large":           "El documento del parche es demasiado grande",
+	"error.invalid_bulk_mode":         "mode debe ser atomic o per_item",
+	"error.no_operations":             "Se requiere al menos una operación",
+	"error.too_many_operations":       "Se permiten como máximo %d operaciones por petición",
+	"error.no_changes":                "Se requiere un cambio de precio o cantidad",
+	"error.invalid_mass_update":       "Cambio de precio o cantidad no válido",
+	"error.filters_required":          "Envía al menos un filtro, o \"all\": true para actualizar todos los productos",
+	"error.category_slug_exists":      "Ya existe una categoría con este slug",
+	"error.category_cycle":            "Una categoría no puede moverse bajo sí misma ni bajo una de sus subcategorías",
+	"error.category_has_children":     "La categoría tiene subcategorías, muévelas o elimínalas primero",
+	"error.category_has_products":     "La categoría tiene productos, muévelos primero a otra categoría",
+	"error.stock_rule_exists":         "Ya existe una regla para este ámbito y destino, actualízala",
+	"error.stock_rule_target_change":  "Elimina y vuelve a crear la regla para cambiar su ámbito, categoría o producto",
+	"error.invalid_stock_rule_target": "Las reglas de categoría requieren category_id, las de producto product_id y las globales ninguno",
+	"error.invalid_stock_thresholds":  "critical_threshold no puede ser mayor que low_threshold",
+	"error.invalid_stock_percent":     "Los umbrales en porcentaje no pueden ser mayores que 100",
+	"error.attribute_exists":          "El atributo ya está definido en esta rama de categorías",
+	"error.attribute_type_change":     "Elimina y vuelve a crear el atributo para cambiar su tipo",
+	"error.invalid_attribute_key":     "La clave debe empezar por letra minúscula y contener solo letras minúsculas, dígitos y guiones bajos",
+	"error.invalid_attribute_type":    "El tipo debe ser uno de: string, number, bool, enum",
+	"error.invalid_attribute_label":   "La etiqueta no puede tener más de 100 caracteres",
+	"error.enum_options_required":     "Los atributos enum requieren al menos una opción",
+
+	// Títulos de los tipos de problema (internal/problem)
+	"problem.bad_request":              "Petición incorrecta",
+	"problem.invalid_request":          "Formato de petición no válido",
+	"problem.validation_failed":        "La validación ha fallado",
+	"problem.invalid_parameter":        "Parámetro no válido",
+	"problem.unauthorized":             "Se requiere autenticación",
+	"problem.invalid_token":            "Token no válido o caducado",
+	"problem.invalid_api_key":          "API key no válida o revocada",
+	"problem.forbidden":                "Prohibido",
+	"problem.insufficient_permissions": "Permisos insuficientes",
+	"problem.mfa_required":             "Esta acción requiere autenticación en dos pasos",
+	"problem.session_required":         "Este endpoint requiere un token de sesión de usuario",
+	"problem.organization_required":    "No hay organización activa",
+	"problem.not_a_member":             "No eres miembro de esta organización",
+	"problem.not_found":                "Recurso no encontrado",
+	"problem.method_not_allowed":       "Método no permitido",
+	"problem.payload_too_large":        "El cuerpo de la petición es demasiado grande",
+	"problem.unsupported_media_type":   "Tipo de contenido no soportado",
+	"problem.too_many_requests":        "Demasiadas peticiones",
+	"problem.internal_error":           "Error interno del servidor",
+
+	"problem.user_exists":                   "El usuario ya existe",
+	"problem.user_not_found":                "Usuario no encontrado",
+	"problem.invalid_credentials":           "Credenciales no válidas",
+	"problem.login_throttled":               "Demasiados intentos de login fallidos",
+	"problem.account_locked":                "Cuenta bloqueada temporalmente",
+	"problem.password_policy":               "La contraseña no cumple la política de contraseñas",
+	"problem.invalid_link_token":            "Token de verificación o recuperación no válido o caducado",
+	"problem.email_already_verified":        "El email ya está verificado",
+	"problem.invalid_mfa_token":             "Token MFA no válido o caducado",
+	"problem.invalid_mfa_code":              "Código MFA no válido",
+	"problem.mfa_already_enabled":           "MFA ya está activado",
+	"problem.mfa_not_enabled":               "MFA no está activado",
+	"problem.mfa_setup_not_started":         "No se ha iniciado la configuración de MFA",
+	"problem.api_key_not_found":             "API key no encontrada",
+	"problem.invalid_permission":            "Permiso de API key no válido",
+	"problem.oidc_not_configured":           "El login con OIDC no está configurado",
+	"problem.oidc_login_failed":             "El login con OIDC ha fallado",
+	"problem.identity_provider_unavailable": "Proveedor de identidad no disponible",
+
+	"problem.organization_not_found": "Organización no encontrada",
+	"problem.member_not_found":       "Miembro no encontrado",
+	"problem.already_member":         "El usuario ya es miembro",
+	"problem.invalid_role":           "Rol no válido",
+	"problem.owner_required":         "Solo los owners pueden añadir, cambiar o quitar owners",
+	"problem.last_owner":             "Una organización debe conservar al menos un owner",
+	"problem.invalid_slug":           "Slug no válido",
+	"problem.slug_exists":            "El slug ya existe",
+
+	"problem.category_not_found":           "Categoría no encontrada",
+	"problem.parent_category_not_found":    "Categoría padre no encontrada",
+	"problem.category_cycle":               "Categoría padre no válida",
+	"problem.category_not_empty":           "La categoría no está vacía",
+	"problem.attribute_not_found":          "Atributo no encontrado",
+	"problem.attribute_exists":             "El atributo ya está definido",
+	"problem.attribute_type_immutable":     "El tipo del atributo no se puede cambiar",
+	"problem.invalid_attribute_definition": "Definición de atributo no válida",
+
+	"problem.product_not_found":    "Producto no encontrado",
+	"problem.sku_exists":           "Ya existe un producto con este SKU",
+	"problem.invalid_category":     "Categoría no encontrada o no válida",
+	"problem.invalid_tags":         "Etiquetas no válidas",
+	"problem.invalid_attribute":    "Atributo de producto no válido",
+	"problem.invalid_product":      "Producto no válido",
+	"problem.invalid_filter":       "Filtro no válido",
+	"problem.invalid_sort":         "Campo de orden no válido",
+	"problem.invalid_cursor":       "Cursor no válido",
+	"problem.invalid_patch":        "Documento de parche no válido",
+	"problem.patch_test_failed":    "Ha fallado una operación test del parche",
+	"problem.invalid_import":       "Fichero de importación no válido",
+	"problem.invalid_bulk_request": "Petición por lotes no válida",
+	"problem.filters_required":     "Se requiere al menos un filtro",
+
+	"problem.stock_rule_not_found":        "Regla de stock no encontrada",
+	"problem.stock_rule_exists":           "La regla de stock ya está definida",
+	"problem.stock_rule_target_immutable": "El ámbito de la regla de stock no se puede cambiar",
+	"problem.invalid_stock_rule":          "Regla de stock no válida",
+
+	// Infracciones de la política de contraseñas; siguen a "password"
+	"password.min_length": "debe tener al menos %d caracteres",
+	"password.max_length": "debe tener como máximo %d caracteres",
+	"password.upper":      "debe contener una letra mayúscula",
+	"password.lower":      "debe contener una letra minúscula",
+	"password.digit":      "debe contener un dígito",
+	"password.symbol":     "debe contener un símbolo",
+	"password.email":      "no puede ser igual al email",
+	"password.common":     "es demasiado común",
+
+	// Motivos de los filtros no válidos (error.invalid_filter)
+	"filter.invalid_attribute_key": "clave de atributo no válida",
+	"filter.unknown_field":         "campo desconocido",
+	"filter.invalid_operator":      "el operador debe ser uno de: %s",
+	"filter.single_value":          "se esperaba un solo valor",
+	"filter.category_id":           "se esperaba un ID de categoría",
+	"filter.number":                "se esperaba un número",
+	"filter.integer":               "se esperaba un número entero",
+	"filter.date":                  "se esperaba RFC3339 o AAAA-MM-DD",
+	"filter.invalid_value":         "valor no válido",
+	"filter.stock_status":          "el estado de stock debe ser uno de: out_of_stock, critical, low, normal",
+
+	// Motivos de los atributos de producto no válidos (error.invalid_attribute_value)
+	"attribute_value.not_defined": "no está definido para la categoría del producto",
+	"attribute_value.string":      "se esperaba un texto",
+	"attribute_value.too_long":    "no puede tener más de %d caracteres",
+	"attribute_value.number":      "se esperaba un número",
+	"attribute_value.bool":        "se esperaba true o false",
+	"attribute_value.oneof":       "debe ser uno de: %s",
+	"attribute_value.required":    "es obligatorio",
+
+	// Motivos de los campos de producto no válidos en parches, importaciones y operaciones
+	// por lotes; siguen al nombre del campo
+	"product_input.not_found":          "producto no encontrado",
+	"product_input.insufficient_stock": "el stock no puede quedar por debajo de cero",
+	"product_input.sku_exists":         "ya lo usa otro producto (incluida la papelera)",
+	"product_input.invalid_category":   "categoría no encontrada o no válida",
+	"product_input.invalid_tags":       "las etiquetas deben ser nombres no vacíos de hasta 50 caracteres, como máximo %d por producto",
+	"product_input.invalid_op":         "debe ser create, update, delete o adjust_stock",
+	"product_input.whole_number":       "debe ser un número entero mayor o igual que 0",
+	"product_input.number":             "debe ser un número mayor o igual que 0",
+	"product_input.category_id":        "debe ser un ID de categoría",
+	"product_input.not_patchable":      "no es un campo del producto que se pueda cambiar",
+	"product_input.invalid_type":       "tiene un tipo no válido",
+
+	// Motivos de las importaciones no válidas
+	"import.invalid_mode":          "mode debe ser all_or_nothing o best_effort",
+	"import.empty_file":            "el archivo está vacío",
+	"import.invalid_header":        "cabecera no válida: %s",
+	"import.too_many_rows":         "el archivo tiene más de %d filas",
+	"import.unknown_mapping_field": "campo %q desconocido en la asignación de la columna %q",
+	"import.duplicate_field":       "más de una columna para el campo %q",
+	"import.column_not_found":      "la columna asignada %q no está en la cabecera",
+	"import.name_or_sku_required":  "el archivo necesita una columna name o sku",
+
+	// Motivos de los parches no válidos
+	"patch.not_json":           "el parche debe ser un documento JSON",
+	"patch.not_array":          "el parche debe ser un array de operaciones",
+	"patch.not_object":         "el producto parcheado debe ser un objeto JSON",
+	"patch.operation":          "operación %d: %s",
+	"patch.path_required":      "path es obligatorio",
+	"patch.value_required":     "value es obligatorio en %s",
+	"patch.from_required":      "from es obligatorio en %s",
+	"patch.invalid_value":      "valor no válido",
+	"patch.invalid_op":         "op debe ser add, remove, replace, move, copy o test",
+	"patch.move_into_itself":   "no se puede mover un valor dentro de sí mismo",
+	"patch.invalid_path":       "path %q no válido",
+	"patch.path_not_found":     "el path %q no existe",
+	"patch.add_to_scalar":      "no se puede añadir %q a un valor escalar",
+	"patch.remove_from_scalar": "no se puede eliminar %q de un valor escalar",
+	"patch.remove_document":    "no se puede eliminar el documento entero",
+	"patch.invalid_index":      "índice de array %q no válido",
+}
@@ -0,0 +1,67 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileMailer guarda cada correo como archivo .eml (desarrollo local y pruebas)
+type FileMailer struct {
+	dir  string
+	from string
+	mu   sync.Mutex
+	seq  int
+}
+
+// NewFileMailer crea un mailer que escribe en el directorio indicado
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+// Send guarda el mensaje en disco
+func (m *FileMailer) Send(msg Message) error {
+	if err := validateHeader(msg.To, msg.Subject); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail directory: %w", err)
+	}
+
+	m.mu.Lock()
+	m.seq++
+	name := fmt.Sprintf("%s-%04d.eml", time.Now().Format("20060102T150405"), m.seq)
+	m.mu.Unlock()
+
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, buildMessage(m.from, msg), 0o600); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+
+	log.Printf("📧 Email to %s saved to %s", msg.To, path)
+	return nil
+}
+
+// LogMailer escribe los correos en el log de la aplicación
+type LogMailer struct {
+	from string
+}
+
+// NewLogMailer crea un mailer que solo registra los mensajes
+func NewLogMailer(from string) *LogMailer {
+	return &LogMailer{from: from}
+}
+
+// Send registra el mensaje en el log
+func (m *LogMailer) Send(msg Message) error {
+	if err := validateHeader(msg.To, msg.Subject); err != nil {
+		return err
+	}
+
+	log.Printf("📧 Email (not sent)\nFrom: %s\nTo: %s\nSubject: %s\n\n%s", m.from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
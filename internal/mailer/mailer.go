@@ -0,0 +1,129 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message representa un correo de texto plano
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer define el contrato para enviar correos
+type Mailer interface {
+	Send(msg Message) error
+}
+
+var (
+	defaultOnce   sync.Once
+	defaultMailer Mailer
+)
+
+// Default retorna el mailer configurado por variables de entorno
+//
+// MAIL_DRIVER puede ser "smtp", "file" o "log". Si no se indica, se usa SMTP
+// cuando SMTP_HOST está definido, archivos si MAIL_FILE_DIR está definido y
+// en otro caso el log de la aplicación
+func Default() Mailer {
+	defaultOnce.Do(func() {
+		defaultMailer = fromEnv()
+	})
+	return defaultMailer
+}
+
+// fromEnv construye el mailer según la configuración del entorno
+func fromEnv() Mailer {
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = os.Getenv("SMTP_USER")
+	}
+	if from == "" {
+		from = "no-reply@inventory-api.local"
+	}
+
+	driver := os.Getenv("MAIL_DRIVER")
+	if driver == "" {
+		switch {
+		case os.Getenv("SMTP_HOST") != "":
+			driver = "smtp"
+		case os.Getenv("MAIL_FILE_DIR") != "":
+			driver = "file"
+		default:
+			driver = "log"
+		}
+	}
+
+	switch driver {
+	case "smtp":
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		return NewSMTPMailer(os.Getenv("SMTP_HOST"), port, os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASSWORD"), from)
+	case "file":
+		dir := os.Getenv("MAIL_FILE_DIR")
+		if dir == "" {
+			dir = "mail"
+		}
+		return NewFileMailer(dir, from)
+	default:
+		if driver != "log" {
+			log.Printf("Warning: unknown MAIL_DRIVER %q, falling back to log", driver)
+		}
+		return NewLogMailer(from)
+	}
+}
+
+// buildMessage construye el mensaje RFC 5322 listo para enviar o guardar
+func buildMessage(from string, msg Message) []byte {
+	var buf bytes.Buffer
+	headers := []string{
+		"From: " + from,
+		"To: " + msg.To,
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"Message-ID: " + messageID(from),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Transfer-Encoding: 8bit",
+	}
+	for _, h := range headers {
+		buf.WriteString(h + "\r\n")
+	}
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return buf.Bytes()
+}
+
+// messageID genera un identificador único para el header Message-ID
+func messageID(from string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = strings.Trim(from[i+1:], "> ")
+	}
+
+	id := make([]byte, 12)
+	_, _ = rand.Read(id)
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(id), domain)
+}
+
+// validateHeader evita inyección de headers a través de destinatario o asunto
+func validateHeader(values ...string) error {
+	for _, v := range values {
+		if strings.ContainsAny(v, "\r\n") {
+			return fmt.Errorf("invalid header value %q", v)
+		}
+	}
+	return nil
+}
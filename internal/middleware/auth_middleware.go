@@ -1,55 +1,118 @@
 package middleware
 
 import (
-	"net/http"
+	"errors"
 	"strings"
 
+	"inventory-api/internal/models"
+	"inventory-api/internal/problem"
 	"inventory-api/internal/services"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
 
-// JWTMiddleware crea un middleware para validar tokens JWT
+// Métodos de autenticación almacenados en el contexto
+const (
+	AuthMethodJWT    = "jwt"
+	AuthMethodAPIKey = "api_key"
+)
+
+// JWTMiddleware crea un middleware para validar tokens JWT o API keys
 func JWTMiddleware(db *gorm.DB) echo.MiddlewareFunc {
 	authService := services.NewAuthService(db)
+	apiKeyService := services.NewAPIKeyService(db)
+	orgService := services.NewOrganizationService(db)
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			// Las integraciones pueden autenticarse con una API key
+			if rawKey := c.Request().Header.Get("X-API-Key"); rawKey != "" {
+				key, user, err := apiKeyService.AuthenticateAPIKey(rawKey)
+				if err != nil {
+					return problem.InvalidAPIKey.New()
+				}
+
+				// La clave conserva solo los permisos que su propietario aún tiene en la organización
+				ownerPermissions, err := apiKeyService.OwnerPermissions(user, key.OrganizationID)
+				if err != nil {
+					return err
+				}
+
+				var permissions []string
+				for _, p := range key.Permissions {
+					if models.HasPermission(ownerPermissions, p) {
+						permissions = append(permissions, p)
+					}
+				}
+
+				c.Set("user_id", user.ID)
+				c.Set("user_email", user.Email)
+				c.Set("user_role", user.Role)
+				c.Set("permissions", permissions)
+				c.Set("auth_method", AuthMethodAPIKey)
+				c.Set("api_key_id", key.ID)
+				SetUserLanguage(c, user.Language)
+				if key.OrganizationID != 0 {
+					c.Set("org_id", key.OrganizationID)
+				}
+
+				return next(c)
+			}
+
 			// Obtener el header Authorization
 			authHeader := c.Request().Header.Get("Authorization")
 			if authHeader == "" {
-				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-					"error": "Authorization header required",
-				})
+				return problem.Unauthorized.Message("error.auth_header_required")
 			}
 
 			// Verificar formato Bearer
 			if !strings.HasPrefix(authHeader, "Bearer ") {
-				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-					"error": "Invalid authorization header format",
-				})
+				return problem.Unauthorized.Message("error.auth_header_format")
 			}
 
 			// Extraer token
 			token := strings.TrimPrefix(authHeader, "Bearer ")
 			if token == "" {
-				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-					"error": "Token is required",
-				})
+				return problem.Unauthorized.Message("error.token_required")
 			}
 
 			// Validar token
 			claims, err := authService.ValidateJWT(token)
 			if err != nil {
-				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-					"error": "Invalid or expired token",
-				})
+				return problem.InvalidToken.New()
+			}
+
+			// Los tokens emitidos antes de introducir roles no incluyen la claim
+			role := claims.Role
+			if role == "" {
+				role = models.RoleUser
+			}
+
+			// Los permisos sobre datos dependen del rol en la organización activa,
+			// que se consulta en cada petición para aplicar altas y bajas al momento
+			permissions := models.PermissionsForRole(role)
+			if claims.OrgID != 0 {
+				membership, err := orgService.GetMembership(claims.OrgID, claims.UserID)
+				if err != nil {
+					if errors.Is(err, services.ErrMembershipNotFound) {
+						return problem.NotMember.New()
+					}
+					return err
+				}
+				permissions = models.EffectivePermissions(role, membership.Role)
+				c.Set("org_id", membership.OrganizationID)
+				c.Set("org_role", membership.Role)
 			}
 
 			// Almacenar información del usuario en el contexto
 			c.Set("user_id", claims.UserID)
 			c.Set("user_email", claims.Email)
+			c.Set("user_role", role)
+			c.Set("permissions", permissions)
+			c.Set("auth_method", AuthMethodJWT)
+			c.Set("mfa", claims.MFA)
+			SetUserLanguage(c, claims.Lang)
 
 			// Continuar con el siguiente handler
 			return next(c)
@@ -57,6 +120,55 @@ func JWTMiddleware(db *gorm.DB) echo.MiddlewareFunc {
 	}
 }
 
+// RequirePermission crea un middleware que exige un permiso concreto
+// Debe usarse después de JWTMiddleware
+func RequirePermission(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !HasPermission(c, permission) {
+				return problem.InsufficientPermissions.New().With("permission", permission)
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireUserSession crea un middleware que rechaza las peticiones hechas con API key
+// Se usa en endpoints que solo debe operar una persona (p. ej. gestionar API keys)
+func RequireUserSession() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if method, _ := c.Get("auth_method").(string); method != AuthMethodJWT {
+				return problem.SessionRequired.New()
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireMFA crea un middleware que exige segundo factor a los roles configurados
+// en MFA_REQUIRED_ROLES. Las API keys quedan exentas porque solo pueden crearse
+// desde una sesión que ya superó este control
+func RequireMFA() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !MFASatisfied(c) {
+				return problem.MFARequired.New()
+			}
+			return next(c)
+		}
+	}
+}
+
+// MFASatisfied indica si la petición cumple la exigencia de segundo factor de RequireMFA
+// Sirve a los handlers que solo lo exigen para algunas acciones
+func MFASatisfied(c echo.Context) bool {
+	method, _ := c.Get("auth_method").(string)
+	role, _ := c.Get("user_role").(string)
+	mfa, _ := c.Get("mfa").(bool)
+	return method != AuthMethodJWT || mfa || !services.MFARequiredForRole(role)
+}
+
 // GetUserID obtiene el ID del usuario desde el contexto
 func GetUserID(c echo.Context) (uint, bool) {
 	userID, ok := c.Get("user_id").(uint)
@@ -69,6 +181,41 @@ func GetUserEmail(c echo.Context) (string, bool) {
 	return email, ok
 }
 
+// GetOrganizationID obtiene la organización activa desde el contexto
+func GetOrganizationID(c echo.Context) (uint, bool) {
+	orgID, ok := c.Get("org_id").(uint)
+	return orgID, ok
+}
+
+// GetOrganizationRole obtiene el rol del usuario en la organización activa
+func GetOrganizationRole(c echo.Context) string {
+	role, _ := c.Get("org_role").(string)
+	return role
+}
+
+// RequireOrganization crea un middleware que exige una organización activa
+func RequireOrganization() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := GetOrganizationID(c); !ok {
+				return problem.OrganizationRequired.Message("error.no_active_organization")
+			}
+			return next(c)
+		}
+	}
+}
+
+// GetPermissions obtiene los permisos efectivos desde el contexto
+func GetPermissions(c echo.Context) []string {
+	permissions, _ := c.Get("permissions").([]string)
+	return permissions
+}
+
+// HasPermission verifica si la petición actual tiene el permiso indicado
+func HasPermission(c echo.Context, permission string) bool {
+	return models.HasPermission(GetPermissions(c), permission)
+}
+
 // RequireAuth es un alias más semántico para JWTMiddleware
 func RequireAuth(db *gorm.DB) echo.MiddlewareFunc {
 	return JWTMiddleware(db)
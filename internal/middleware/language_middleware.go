@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"inventory-api/internal/i18n"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Language crea un middleware que elige el idioma de la respuesta con la cabecera
+// Accept-Language. JWTMiddleware lo sustituye por la preferencia del usuario si la tiene
+func Language() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("lang", i18n.Negotiate(c.Request().Header.Get("Accept-Language")))
+
+			// El idioma final solo se conoce al responder, después de autenticar
+			c.Response().Header().Add(echo.HeaderVary, "Accept-Language")
+			c.Response().Before(func() {
+				c.Response().Header().Set("Content-Language", string(GetLanguage(c)))
+			})
+			return next(c)
+		}
+	}
+}
+
+// SetUserLanguage aplica la preferencia de idioma del usuario, que prevalece sobre Accept-Language
+// Sin preferencia (o con un idioma que ya no existe) se usa el idioma de la cabecera
+func SetUserLanguage(c echo.Context, preference string) {
+	lang, ok := i18n.Parse(preference)
+	if !ok {
+		lang = i18n.Negotiate(c.Request().Header.Get("Accept-Language"))
+	}
+	c.Set("lang", lang)
+}
+
+// GetLanguage obtiene el idioma de la respuesta desde el contexto
+func GetLanguage(c echo.Context) i18n.Language {
+	if lang, ok := c.Get("lang").(i18n.Language); ok {
+		return lang
+	}
+	return i18n.Default()
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+)
+
+// APIKey representa una clave de acceso para integraciones máquina a máquina
+type APIKey struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	UserID         uint       `gorm:"not null;index" json:"user_id"`
+	OrganizationID uint       `gorm:"index" json:"organization_id"` // La clave solo accede a esta organización
+	Name           string     `gorm:"not null" json:"name"`
+	Prefix         string     `gorm:"not null;uniqueIndex;size:32" json:"prefix"` // Parte visible de la clave
+	KeyHash        string     `gorm:"not null;uniqueIndex" json:"-"`              // SHA-256 de la clave completa
+	Permissions    []string   `gorm:"serializer:json;type:text;not null" json:"permissions"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// APIKeyRequest representa la estructura para crear una API key
+type APIKeyRequest struct {
+	Name          string   `json:"name" validate:"required,min=2,max=100"`
+	Permissions   []string `json:"permissions" validate:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"min=0"`
+}
+
+// APIKeyResponse representa una API key sin datos sensibles
+type APIKeyResponse struct {
+	ID             uint       `json:"id"`
+	Name           string     `json:"name"`
+	OrganizationID uint       `json:"organization_id"`
+	Prefix         string     `json:"prefix"`
+	Permissions    []string   `json:"permissions"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// APIKeyCreatedResponse incluye la clave en claro, que solo se muestra una vez
+type APIKeyCreatedResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// IsActive verifica si la clave no está revocada ni expirada
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// ToResponse convierte APIKey a APIKeyResponse
+func (k *APIKey) ToResponse() APIKeyResponse {
+	return APIKeyResponse{
+		ID:             k.ID,
+		Name:           k.Name,
+		OrganizationID: k.OrganizationID,
+		Prefix:         k.Prefix,
+		Permissions:    k.Permissions,
+		LastUsedAt:     k.LastUsedAt,
+		ExpiresAt:      k.ExpiresAt,
+		RevokedAt:      k.RevokedAt,
+		CreatedAt:      k.CreatedAt,
+	}
+}
+
+// TableName especifica el nombre de la tabla
+func (APIKey) TableName() string {
+	return "api_keys"
+}
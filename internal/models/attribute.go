@@ -0,0 +1,80 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Tipos de atributo personalizado
+const (
+	AttributeTypeString = "string"
+	AttributeTypeNumber = "number"
+	AttributeTypeBool   = "bool"
+	AttributeTypeEnum   = "enum" // Uno de los valores de Options
+)
+
+// ProductAttributes son los valores de los atributos personalizados de un producto (JSONB)
+type ProductAttributes map[string]interface{}
+
+// AttributeDefinition define un atributo personalizado de los productos de una categoría
+// y de sus subcategorías
+type AttributeDefinition struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	OrganizationID uint      `gorm:"not null;index" json:"-"`
+	CategoryID     uint      `gorm:"not null;uniqueIndex:idx_attribute_definitions_category_key" json:"category_id"`
+	Key            string    `gorm:"not null;size:64;uniqueIndex:idx_attribute_definitions_category_key" json:"key"`
+	Label          string    `gorm:"size:100" json:"label"`
+	Type           string    `gorm:"not null;size:16" json:"type"`
+	Options        []string  `gorm:"serializer:json;type:jsonb" json:"options,omitempty"` // Valores permitidos de los enum
+	Required       bool      `gorm:"not null;default:false" json:"required"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// AttributeDefinitionRequest representa la estructura para crear/actualizar definiciones de atributos
+type AttributeDefinitionRequest struct {
+	Key      string   `json:"key" validate:"required,max=64"`
+	Label    string   `json:"label" validate:"max=100"`
+	Type     string   `json:"type" validate:"omitempty,oneof=string number bool enum"` // Obligatorio al crear; no se puede cambiar
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+}
+
+// IsValidAttributeType verifica si el tipo de atributo existe
+func IsValidAttributeType(attributeType string) bool {
+	switch attributeType {
+	case AttributeTypeString, AttributeTypeNumber, AttributeTypeBool, AttributeTypeEnum:
+		return true
+	}
+	return false
+}
+
+// AuditValue retorna los atributos como JSON con las claves ordenadas, para compararlos en la auditoría
+func (a ProductAttributes) AuditValue() string {
+	if len(a) == 0 {
+		return "{}"
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// AuditFields retorna los campos de la definición que se comparan en la auditoría
+func (d *AttributeDefinition) AuditFields() map[string]interface{} {
+	options, _ := json.Marshal(d.Options)
+	return map[string]interface{}{
+		"category_id": d.CategoryID,
+		"key":         d.Key,
+		"label":       d.Label,
+		"type":        d.Type,
+		"options":     string(options),
+		"required":    d.Required,
+	}
+}
+
+// TableName especifica el nombre de la tabla
+func (AttributeDefinition) TableName() string {
+	return "attribute_definitions"
+}
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Acciones registradas en el log de auditoría
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore" // Recuperado de la papelera
+	AuditActionPurge   = "purge"   // Eliminado definitivamente
+)
+
+// Tipos de entidad auditados
+const (
+	AuditEntityProduct   = "product"
+	AuditEntityUser      = "user"
+	AuditEntityCategory  = "category"
+	AuditEntityAttribute = "attribute_definition"
+	AuditEntityStockRule = "stock_rule"
+)
+
+// AuditRedacted sustituye a los valores sensibles (p. ej. hashes de contraseña) en los diffs
+const AuditRedacted = "[redacted]"
+
+// AuditChange representa el valor de un campo antes y después del cambio
+type AuditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// AuditLog registra quién hizo cada cambio sobre una entidad y desde qué petición
+type AuditLog struct {
+	ID             uint                   `gorm:"primaryKey" json:"id"`
+	ActorID        *uint                  `gorm:"index" json:"actor_id,omitempty"` // Nulo en procesos del sistema
+	ActorEmail     string                 `json:"actor_email,omitempty"`
+	Action         string                 `gorm:"not null;index" json:"action"`
+	EntityType     string                 `gorm:"not null;index:idx_audit_logs_entity" json:"entity_type"`
+	EntityID       uint                   `gorm:"not null;index:idx_audit_logs_entity" json:"entity_id"`
+	OrganizationID uint                   `gorm:"index" json:"organization_id,omitempty"`
+	Changes        map[string]AuditChange `gorm:"serializer:json;type:jsonb" json:"changes"`
+	RequestID      string                 `gorm:"size:64;index" json:"request_id,omitempty"`
+	IP             string                 `gorm:"size:64" json:"ip,omitempty"`
+	CreatedAt      time.Time              `gorm:"index" json:"created_at"`
+}
+
+// AuditContext identifica al autor de un cambio y la petición que lo originó
+type AuditContext struct {
+	ActorID    *uint
+	ActorEmail string
+	RequestID  string
+	IP         string
+}
+
+// AuditFilter representa los filtros de consulta del log de auditoría
+type AuditFilter struct {
+	EntityType string
+	EntityID   uint
+	ActorID    uint
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+// WithActor retorna una copia del contexto con el usuario indicado como autor
+// Se usa en flujos sin sesión (registro, recuperación de contraseña, OIDC)
+func (ac AuditContext) WithActor(user *User) AuditContext {
+	id := user.ID
+	ac.ActorID = &id
+	ac.ActorEmail = user.Email
+	return ac
+}
+
+// Audit construye el contexto de auditoría de una petición sin sesión
+func (ci ClientInfo) Audit() AuditContext {
+	return AuditContext{
+		RequestID: ci.RequestID,
+		IP:        ci.IP,
+	}
+}
+
+// AuditFields retorna los campos del producto que se comparan en la auditoría
+func (p *Product) AuditFields() map[string]interface{} {
+	return map[string]interface{}{
+		"sku":           p.SKUValue(),
+		"name":          p.Name,
+		"description":   p.Description,
+		"quantity":      p.Quantity,
+		"reorder_point": p.reorderPointAuditValue(),
+		"price":         p.Price,
+		"category":      p.Category,
+		"tags":          strings.Join(p.TagNames(), ", "),
+		"attributes":    p.AttributeValues().AuditValue(),
+	}
+}
+
+// reorderPointAuditValue retorna el punto de pedido como valor (nil si no tiene), para que la
+// comparación de la auditoría no dependa del puntero
+func (p *Product) reorderPointAuditValue() interface{} {
+	if p.ReorderPoint == nil {
+		return nil
+	}
+	return *p.ReorderPoint
+}
+
+// AuditFields retorna los campos del usuario que se comparan en la auditoría
+// La contraseña se representa con su hash para detectar cambios, que luego se ocultan
+func (u *User) AuditFields() map[string]interface{} {
+	return map[string]interface{}{
+		"email":          u.Email,
+		"role":           u.Role,
+		"password":       u.Password,
+		"email_verified": u.EmailVerified,
+		"mfa_enabled":    u.MFAEnabled,
+		"locked_until":   u.LockedUntil,
+	}
+}
+
+// DiffAuditFields compara dos instantáneas y retorna solo los campos que cambiaron
+// before es nil en las altas y after es nil en las bajas
+func DiffAuditFields(before, after map[string]interface{}) map[string]AuditChange {
+	changes := make(map[string]AuditChange)
+	for field, newValue := range after {
+		oldValue, existed := before[field]
+		if existed && auditValueEqual(oldValue, newValue) {
+			continue
+		}
+		changes[field] = AuditChange{Old: oldValue, New: newValue}
+	}
+	for field, oldValue := range before {
+		if _, ok := after[field]; !ok {
+			changes[field] = AuditChange{Old: oldValue, New: nil}
+		}
+	}
+
+	// Nunca se guardan hashes de contraseña, solo el hecho de que cambió
+	if change, ok := changes["password"]; ok {
+		if change.Old != nil {
+			change.Old = AuditRedacted
+		}
+		if change.New != nil {
+			change.New = AuditRedacted
+		}
+		changes["password"] = change
+	}
+	return changes
+}
+
+// auditValueEqual compara dos valores de una instantánea de auditoría
+func auditValueEqual(a, b interface{}) bool {
+	ta, aIsTime := a.(*time.Time)
+	tb, bIsTime := b.(*time.Time)
+	if aIsTime || bIsTime {
+		switch {
+		case ta == nil || tb == nil:
+			return ta == nil && tb == nil
+		default:
+			return ta.Equal(*tb)
+		}
+	}
+	return a == b
+}
+
+// TableName especifica el nombre de la tabla
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
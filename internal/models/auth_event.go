@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+)
+
+// Tipos de eventos de autenticación
+const (
+	AuthEventLoginSuccess    = "login_success"
+	AuthEventLoginFailed     = "login_failed"
+	AuthEventLoginBlocked    = "login_blocked"
+	AuthEventMFAFailed       = "mfa_failed"
+	AuthEventAccountLocked   = "account_locked"
+	AuthEventAccountUnlocked = "account_unlocked"
+)
+
+// AuthEvent registra un evento de autenticación (logins correctos y fallidos)
+type AuthEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    *uint     `gorm:"index" json:"user_id,omitempty"` // Nulo si el email no existe
+	Email     string    `gorm:"index" json:"email"`
+	Event     string    `gorm:"not null;index" json:"event"`
+	Reason    string    `json:"reason,omitempty"`
+	IP        string    `gorm:"size:64" json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// LoginThrottle registra los intentos fallidos por dirección IP
+type LoginThrottle struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	IP            string     `gorm:"not null;uniqueIndex;size:64" json:"ip"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	BlockedUntil  *time.Time `json:"blocked_until"`
+}
+
+// ClientInfo identifica el origen de una petición de autenticación
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+// TableName especifica el nombre de la tabla
+func (AuthEvent) TableName() string {
+	return "auth_events"
+}
+
+// TableName especifica el nombre de la tabla
+func (LoginThrottle) TableName() string {
+	return "login_throttles"
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+)
+
+// Category es una categoría de productos de la organización, organizada en árbol
+type Category struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	OrganizationID uint      `gorm:"not null;uniqueIndex:idx_categories_org_slug" json:"-"`
+	ParentID       *uint     `gorm:"index" json:"parent_id"` // Nulo en las categorías raíz
+	Name           string    `gorm:"not null;size:50" json:"name"`
+	Slug           string    `gorm:"not null;size:64;uniqueIndex:idx_categories_org_slug" json:"slug"` // Único en la organización
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// CategoryRequest representa la estructura para crear/actualizar categorías
+type CategoryRequest struct {
+	Name     string `json:"name" validate:"required,min=2,max=50"`
+	Slug     string `json:"slug" validate:"omitempty,min=2,max=64"`
+	ParentID *uint  `json:"parent_id"` // Nulo para una categoría raíz
+}
+
+// CategoryResponse representa una categoría con su ruta y, en el árbol, sus subcategorías
+type CategoryResponse struct {
+	ID           uint               `json:"id"`
+	ParentID     *uint              `json:"parent_id"`
+	Name         string             `json:"name"`
+	Slug         string             `json:"slug"`
+	Path         string             `json:"path"`          // Nombres desde la raíz, p. ej. "Electronics / Laptops"
+	ProductCount int64              `json:"product_count"` // Productos activos en la categoría (sin subcategorías)
+	Children     []CategoryResponse `json:"children,omitempty"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+// ToResponse convierte Category a CategoryResponse con la ruta indicada
+func (c *Category) ToResponse(path string, productCount int64) CategoryResponse {
+	return CategoryResponse{
+		ID:           c.ID,
+		ParentID:     c.ParentID,
+		Name:         c.Name,
+		Slug:         c.Slug,
+		Path:         path,
+		ProductCount: productCount,
+		CreatedAt:    c.CreatedAt,
+		UpdatedAt:    c.UpdatedAt,
+	}
+}
+
+// AuditFields retorna los campos de la categoría que se comparan en la auditoría
+func (c *Category) AuditFields() map[string]interface{} {
+	var parentID uint
+	if c.ParentID != nil {
+		parentID = *c.ParentID
+	}
+	return map[string]interface{}{
+		"name":      c.Name,
+		"slug":      c.Slug,
+		"parent_id": parentID,
+	}
+}
+
+// TableName especifica el nombre de la tabla
+func (Category) TableName() string {
+	return "categories"
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+// MFARecoveryCode representa un código de recuperación de un solo uso
+type MFARecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// MFASetupResponse contiene el secreto TOTP pendiente de confirmar
+type MFASetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // Contenido del código QR
+}
+
+// MFACodeRequest representa un código TOTP o de recuperación
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// MFALoginRequest representa el segundo paso del login con MFA
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// TableName especifica el nombre de la tabla
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
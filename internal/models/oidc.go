@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// OIDCLoginState guarda los datos de un login OIDC en curso (state, nonce y PKCE)
+type OIDCLoginState struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	StateHash    string    `gorm:"not null;uniqueIndex;size:64" json:"-"`
+	Nonce        string    `gorm:"not null" json:"-"`
+	CodeVerifier string    `gorm:"not null" json:"-"`
+	ExpiresAt    time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName especifica el nombre de la tabla
+func (OIDCLoginState) TableName() string {
+	return "oidc_login_states"
+}
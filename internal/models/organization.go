@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+)
+
+// Organization representa un cliente (tenant) con su propio inventario
+type Organization struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	Slug      string    `gorm:"not null;uniqueIndex;size:64" json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Membership vincula un usuario con una organización y su rol en ella
+type Membership struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	OrganizationID uint      `gorm:"not null;uniqueIndex:idx_memberships_org_user" json:"organization_id"`
+	UserID         uint      `gorm:"not null;uniqueIndex:idx_memberships_org_user;index" json:"user_id"`
+	Role           string    `gorm:"not null" json:"role"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// OrganizationRequest representa la estructura para crear una organización
+type OrganizationRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=100"`
+	Slug string `json:"slug" validate:"omitempty,min=2,max=64"`
+}
+
+// MemberRequest representa la estructura para añadir un miembro o cambiar su rol
+type MemberRequest struct {
+	Email string `json:"email" validate:"omitempty,email"`
+	Role  string `json:"role" validate:"required"`
+}
+
+// SwitchOrganizationRequest representa la estructura para cambiar de organización activa
+type SwitchOrganizationRequest struct {
+	OrganizationID uint `json:"organization_id" validate:"required"`
+}
+
+// OrganizationResponse representa una organización junto al rol del usuario en ella
+type OrganizationResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	Role      string    `json:"role,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MemberResponse representa un miembro de la organización
+type MemberResponse struct {
+	UserID    uint      `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ToResponse convierte Organization a OrganizationResponse con el rol indicado
+func (o *Organization) ToResponse(role string) OrganizationResponse {
+	return OrganizationResponse{
+		ID:        o.ID,
+		Name:      o.Name,
+		Slug:      o.Slug,
+		Role:      role,
+		CreatedAt: o.CreatedAt,
+	}
+}
+
+// TableName especifica el nombre de la tabla
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// TableName especifica el nombre de la tabla
+func (Membership) TableName() string {
+	return "memberships"
+}
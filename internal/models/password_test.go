@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// phcPattern es el formato de los hashes Argon2id con los parámetros actuales
+var phcPattern = regexp.MustCompile(fmt.Sprintf(`^\$argon2id\$v=19\$m=%d,t=%d,p=%d\$[A-Za-z0-9+/]{22}\$[A-Za-z0-9+/]{43}$`,
+	PasswordHashParams.Memory, PasswordHashParams.Iterations, PasswordHashParams.Parallelism))
+
+func TestHashPasswordRoundTrip(t *testing.T) {
+	password := "correct horse battery staple ñ"
+	hash, err := HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !phcPattern.MatchString(hash) {
+		t.Fatalf("hash %q does not match the PHC format", hash)
+	}
+
+	params, salt, key, err := decodeArgon2Hash(hash)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if params != PasswordHashParams || uint32(len(salt)) != PasswordHashParams.SaltLength || uint32(len(key)) != PasswordHashParams.KeyLength {
+		t.Fatalf("decoded params %+v (salt %d, key %d bytes), want %+v", params, len(salt), len(key), PasswordHashParams)
+	}
+
+	if !verifyPassword(hash, password) {
+		t.Error("the password does not verify against its own hash")
+	}
+	if verifyPassword(hash, password+" ") {
+		t.Error("a different password verifies")
+	}
+	if passwordNeedsRehash(hash) {
+		t.Error("a hash with the current parameters needs a rehash")
+	}
+
+	// Cada hash lleva su propia sal
+	other, err := HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == hash {
+		t.Error("two hashes of the same password are equal")
+	}
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	hash, err := HashPassword("secret-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Los hashes con parámetros anteriores siguen siendo válidos, pero se regeneran
+	defer func(params Argon2Params) { PasswordHashParams = params }(PasswordHashParams)
+	PasswordHashParams.Iterations++
+	if !passwordNeedsRehash(hash) {
+		t.Error("a hash with old parameters does not need a rehash")
+	}
+	if !verifyPassword(hash, "secret-password") {
+		t.Error("a hash with old parameters no longer verifies")
+	}
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("secret-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verifyPassword(string(legacy), "secret-password") || verifyPassword(string(legacy), "other") {
+		t.Error("bcrypt hashes are not verified")
+	}
+	if !passwordNeedsRehash(string(legacy)) {
+		t.Error("a bcrypt hash does not need a rehash")
+	}
+}
+
+func TestDecodeArgon2HashRejects(t *testing.T) {
+	hash, err := HashPassword("secret-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(hash, "$")
+	with := func(i int, value string) string {
+		changed := append([]string(nil), parts...)
+		changed[i] = value
+		return strings.Join(changed, "$")
+	}
+
+	tests := map[string]string{
+		"argon2i":         with(1, "argon2i"),
+		"other version":   with(2, "v=16"),
+		"bad parameters":  with(3, "m=19456,t=2"),
+		"zero iterations": with(3, "m=19456,t=0,p=1"),
+		"bad salt":        with(4, "not*base64"),
+		"bad key":         with(5, "not*base64"),
+		"missing part":    strings.Join(parts[:5], "$"),
+	}
+	for name, bad := range tests {
+		if _, _, _, err := decodeArgon2Hash(bad); err == nil {
+			t.Errorf("%s: %q decodes", name, bad)
+		}
+		if verifyPassword(bad, "secret-password") {
+			t.Errorf("%s: %q verifies", name, bad)
+		}
+		if !passwordNeedsRehash(bad) {
+			t.Errorf("%s: %q does not need a rehash", name, bad)
+		}
+	}
+}
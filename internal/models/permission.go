@@ -0,0 +1,112 @@
+package models
+
+// Roles disponibles para los usuarios
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// Roles de un usuario dentro de una organización
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+	OrgRoleViewer = "viewer"
+)
+
+// Permisos que pueden concederse a roles y API keys
+const (
+	PermissionProductsRead   = "products:read"
+	PermissionProductsWrite  = "products:write"
+	PermissionProductsDelete = "products:delete"
+	PermissionOrgManage      = "org:manage"
+	PermissionUsersManage    = "users:manage"
+)
+
+// AllPermissions lista todos los permisos conocidos
+var AllPermissions = []string{
+	PermissionProductsRead,
+	PermissionProductsWrite,
+	PermissionProductsDelete,
+	PermissionOrgManage,
+	PermissionUsersManage,
+}
+
+// rolePermissions define los permisos de plataforma concedidos a cada rol global
+// Los permisos sobre datos de una organización los concede el rol en la organización
+var rolePermissions = map[string][]string{
+	RoleAdmin: {PermissionUsersManage},
+	RoleUser:  {},
+}
+
+// orgRolePermissions define los permisos concedidos a cada rol de organización
+var orgRolePermissions = map[string][]string{
+	OrgRoleOwner: {
+		PermissionProductsRead,
+		PermissionProductsWrite,
+		PermissionProductsDelete,
+		PermissionOrgManage,
+	},
+	OrgRoleAdmin: {
+		PermissionProductsRead,
+		PermissionProductsWrite,
+		PermissionProductsDelete,
+		PermissionOrgManage,
+	},
+	OrgRoleMember: {
+		PermissionProductsRead,
+		PermissionProductsWrite,
+		PermissionProductsDelete,
+	},
+	OrgRoleViewer: {
+		PermissionProductsRead,
+	},
+}
+
+// PermissionsForRole retorna los permisos de plataforma de un rol (vacío si no existe)
+func PermissionsForRole(role string) []string {
+	return rolePermissions[role]
+}
+
+// IsValidRole verifica si el rol existe
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// PermissionsForOrgRole retorna los permisos de un rol de organización (vacío si no existe)
+func PermissionsForOrgRole(role string) []string {
+	return orgRolePermissions[role]
+}
+
+// IsValidOrgRole verifica si el rol de organización existe
+func IsValidOrgRole(role string) bool {
+	_, ok := orgRolePermissions[role]
+	return ok
+}
+
+// EffectivePermissions combina los permisos del rol global y del rol en la organización actual
+func EffectivePermissions(role, orgRole string) []string {
+	permissions := append([]string{}, PermissionsForRole(role)...)
+	for _, p := range PermissionsForOrgRole(orgRole) {
+		if !HasPermission(permissions, p) {
+			permissions = append(permissions, p)
+		}
+	}
+	return permissions
+}
+
+// IsValidPermission verifica si el permiso existe
+func IsValidPermission(permission string) bool {
+	return HasPermission(AllPermissions, permission)
+}
+
+// HasPermission verifica si la lista contiene el permiso
+func HasPermission(permissions []string, permission string) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
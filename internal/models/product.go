@@ -1,44 +1,82 @@
 package models
 
 import (
+	"strings"
 	"time"
 
+	"inventory-api/internal/i18n"
+
 	"gorm.io/gorm"
 )
 
+// Umbrales por defecto del estado de stock, para los productos sin ninguna regla de stock
+const (
+	LowStockThreshold      = 5
+	CriticalStockThreshold = 2
+)
+
 // Product representa un producto en el inventario
 type Product struct {
-	ID          uint            `gorm:"primaryKey" json:"id"`
-	Name        string          `gorm:"not null;index" json:"name" validate:"required,min=2,max=100"`
-	Description string          `gorm:"type:text" json:"description" validate:"max=500"`
-	Quantity    int             `gorm:"not null;index" json:"quantity" validate:"required,min=0"`
-	Price       float64         `gorm:"not null;type:decimal(10,2)" json:"price" validate:"required,min=0"`
-	Category    string          `gorm:"not null;index" json:"category" validate:"required,min=2,max=50"`
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
-	DeletedAt   *gorm.DeletedAt `gorm:"index" json:"-"` // Soft delete
+	ID             uint              `gorm:"primaryKey" json:"id"`
+	OrganizationID uint              `gorm:"index;uniqueIndex:idx_products_org_sku" json:"-"`                          // Tenant propietario del producto
+	SKU            *string           `gorm:"column:sku;size:64;uniqueIndex:idx_products_org_sku" json:"sku,omitempty"` // Código único en la organización (opcional)
+	Name           string            `gorm:"not null;index" json:"name" validate:"required,min=2,max=100"`
+	Description    string            `gorm:"type:text" json:"description" validate:"max=500"`
+	Quantity       int               `gorm:"not null;index" json:"quantity" validate:"min=0"`
+	ReorderPoint   *int              `json:"reorder_point,omitempty"` // Base de las reglas de stock en porcentaje
+	Price          float64           `gorm:"not null;type:decimal(10,2)" json:"price" validate:"min=0"`
+	CategoryID     *uint             `gorm:"index" json:"category_id"`
+	Category       string            `gorm:"not null;index" json:"category" validate:"required,min=2,max=50"` // Nombre de la categoría, copiado de categories
+	Tags           []Tag             `gorm:"many2many:product_tags" json:"-"`
+	Attributes     ProductAttributes `gorm:"serializer:json;type:jsonb;not null;default:'{}'" json:"attributes"` // Valores de los atributos definidos en la categoría
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	DeletedAt      *gorm.DeletedAt   `gorm:"index" json:"-"` // Soft delete
 }
 
 // ProductRequest representa la estructura para crear/actualizar productos
 type ProductRequest struct {
-	Name        string  `json:"name" validate:"required,min=2,max=100"`
-	Description string  `json:"description" validate:"max=500"`
-	Quantity    int     `json:"quantity" validate:"required,min=0"`
-	Price       float64 `json:"price" validate:"required,min=0"`
-	Category    string  `json:"category" validate:"required,min=2,max=50"`
+	SKU          string                 `json:"sku" validate:"omitempty,max=64,sku"`
+	Name         string                 `json:"name" validate:"required,min=2,max=100"`
+	Description  string                 `json:"description" validate:"max=500"`
+	Quantity     int                    `json:"quantity" validate:"min=0"`
+	ReorderPoint *int                   `json:"reorder_point" validate:"omitempty,min=0"`
+	Price        float64                `json:"price" validate:"min=0"`
+	Category     string                 `json:"category" validate:"required_without=CategoryID,omitempty,min=2,max=50"` // Nombre o slug; se crea si no existe
+	CategoryID   *uint                  `json:"category_id"`                                                            // Tiene prioridad sobre category
+	Tags         []string               `json:"tags"`                                                                   // Al actualizar, si se omite se conservan las actuales
+	Attributes   map[string]interface{} `json:"attributes"`                                                             // Al actualizar, si se omite se conservan los actuales
 }
 
+// Tipos de contenido aceptados por PATCH /products/:id
+const (
+	MergePatchContentType = "application/merge-patch+json" // JSON Merge Patch (RFC 7386)
+	JSONPatchContentType  = "application/json-patch+json"  // JSON Patch (RFC 6902)
+)
+
 // ProductResponse representa la respuesta con información completa del producto
 type ProductResponse struct {
-	ID          uint      `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Quantity    int       `json:"quantity"`
-	Price       float64   `json:"price"`
-	Category    string    `json:"category"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	StockStatus string    `json:"stock_status"`
+	ID           uint              `json:"id"`
+	SKU          string            `json:"sku,omitempty"`
+	Name         string            `json:"name"`
+	Description  string            `json:"description"`
+	Quantity     int               `json:"quantity"`
+	ReorderPoint *int              `json:"reorder_point,omitempty"`
+	Price        float64           `json:"price"`
+	Category     string            `json:"category"`
+	CategoryID   *uint             `json:"category_id,omitempty"`
+	Tags         []string          `json:"tags"`
+	Attributes   ProductAttributes `json:"attributes"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	StockStatus  string            `json:"stock_status"`
+}
+
+// TrashedProductResponse representa un producto en la papelera
+type TrashedProductResponse struct {
+	ProductResponse
+	DeletedAt time.Time  `json:"deleted_at"`
+	PurgeAt   *time.Time `json:"purge_at,omitempty"` // Nulo si la purga automática está desactivada
 }
 
 // ProductSummary representa un resumen del producto para listas
@@ -56,9 +94,10 @@ type ProductAlert struct {
 	Name        string    `json:"name"`
 	Category    string    `json:"category"`
 	Quantity    int       `json:"quantity"`
-	Threshold   int       `json:"threshold"`
+	Threshold   float64   `json:"threshold"` // Umbral de stock bajo aplicado al producto
 	Severity    string    `json:"severity"`
-	Message     string    `json:"message"`
+	Code        string    `json:"code"`    // Código estable del mensaje: low_stock, critical_stock u out_of_stock
+	Message     string    `json:"message"` // Mensaje en el idioma de la petición
 	GeneratedAt time.Time `json:"generated_at"`
 }
 
@@ -79,35 +118,70 @@ func (p *Product) IsLowStock(threshold int) bool {
 	return p.Quantity < threshold
 }
 
-// GetStockStatus retorna el estado del stock
-func (p *Product) GetStockStatus(lowThreshold, criticalThreshold int) string {
-	switch {
-	case p.Quantity == 0:
-		return "out_of_stock"
-	case p.Quantity <= criticalThreshold:
-		return "critical"
-	case p.Quantity <= lowThreshold:
-		return "low"
-	default:
-		return "normal"
-	}
+// GetStockStatus retorna el estado del stock según las reglas de stock que se le aplican
+func (p *Product) GetStockStatus(rules *StockRules) string {
+	return rules.Bands(p).Status(p.Quantity)
 }
 
-// ToResponse convierte Product a ProductResponse
-func (p *Product) ToResponse() ProductResponse {
+// ToResponse convierte Product a ProductResponse; rules son las reglas de stock de la organización
+func (p *Product) ToResponse(rules *StockRules) ProductResponse {
 	return ProductResponse{
-		ID:          p.ID,
-		Name:        p.Name,
-		Description: p.Description,
-		Quantity:    p.Quantity,
-		Price:       p.Price,
-		Category:    p.Category,
-		CreatedAt:   p.CreatedAt,
-		UpdatedAt:   p.UpdatedAt,
-		StockStatus: p.GetStockStatus(5, 2), // Umbral bajo: 5, crítico: 2
+		ID:           p.ID,
+		SKU:          p.SKUValue(),
+		Name:         p.Name,
+		Description:  p.Description,
+		Quantity:     p.Quantity,
+		ReorderPoint: p.ReorderPoint,
+		Price:        p.Price,
+		Category:     p.Category,
+		CategoryID:   p.CategoryID,
+		Tags:         p.TagNames(),
+		Attributes:   p.AttributeValues(),
+		CreatedAt:    p.CreatedAt,
+		UpdatedAt:    p.UpdatedAt,
+		StockStatus:  p.GetStockStatus(rules),
 	}
 }
 
+// AttributeValues retorna los atributos del producto, o un mapa vacío si no tiene
+func (p *Product) AttributeValues() ProductAttributes {
+	if p.Attributes == nil {
+		return ProductAttributes{}
+	}
+	return p.Attributes
+}
+
+// SKUValue retorna el SKU del producto o una cadena vacía si no tiene
+func (p *Product) SKUValue() string {
+	if p.SKU == nil {
+		return ""
+	}
+	return *p.SKU
+}
+
+// NormalizeSKU limpia un SKU recibido; nil si está vacío
+func NormalizeSKU(sku string) *string {
+	sku = strings.TrimSpace(sku)
+	if sku == "" {
+		return nil
+	}
+	return &sku
+}
+
+// ToTrashResponse convierte un producto eliminado a TrashedProductResponse
+// retention es el tiempo que se conserva en la papelera (0 si no se purga)
+func (p *Product) ToTrashResponse(retention time.Duration, rules *StockRules) TrashedProductResponse {
+	response := TrashedProductResponse{ProductResponse: p.ToResponse(rules)}
+	if p.DeletedAt != nil && p.DeletedAt.Valid {
+		response.DeletedAt = p.DeletedAt.Time
+		if retention > 0 {
+			purgeAt := p.DeletedAt.Time.Add(retention)
+			response.PurgeAt = &purgeAt
+		}
+	}
+	return response
+}
+
 // ToSummary convierte Product a ProductSummary
 func (p *Product) ToSummary() ProductSummary {
 	return ProductSummary{
@@ -119,21 +193,46 @@ func (p *Product) ToSummary() ProductSummary {
 	}
 }
 
-// GenerateAlert crea una alerta para el producto si es necesario
-func (p *Product) GenerateAlert(threshold int) *ProductAlert {
-	if !p.IsLowStock(threshold) {
-		return nil
+// ToRequest convierte Product a la ProductRequest que lo describe; es el documento sobre el que
+// se aplican los parches de PATCH /products/:id
+func (p *Product) ToRequest() ProductRequest {
+	return ProductRequest{
+		SKU:          p.SKUValue(),
+		Name:         p.Name,
+		Description:  p.Description,
+		Quantity:     p.Quantity,
+		ReorderPoint: p.ReorderPoint,
+		Price:        p.Price,
+		Category:     p.Category,
+		CategoryID:   p.CategoryID,
+		Tags:         p.TagNames(),
+		Attributes:   p.AttributeValues(),
 	}
+}
 
-	severity := "low"
-	message := "Stock below threshold"
+// GenerateAlert crea una alerta para el producto si es necesario, con el mensaje en el idioma indicado
+// Sin umbral (threshold 0) alertan los productos que no están en estado normal según sus reglas de
+// stock; con umbral, los que tienen menos unidades. La severidad es siempre la de su regla
+func (p *Product) GenerateAlert(rules *StockRules, threshold int, lang i18n.Language) *ProductAlert {
+	bands := rules.Bands(p)
+	status := bands.Status(p.Quantity)
+
+	alertThreshold := bands.Low
+	if threshold > 0 {
+		if !p.IsLowStock(threshold) {
+			return nil
+		}
+		alertThreshold = float64(threshold)
+	} else if status == StockStatusNormal {
+		return nil
+	}
 
-	if p.Quantity == 0 {
-		severity = "critical"
-		message = "Product out of stock"
-	} else if p.Quantity <= 2 {
-		severity = "high"
-		message = "Critical stock level"
+	code := "low_stock"
+	switch status {
+	case StockStatusOutOfStock:
+		code = "out_of_stock"
+	case StockStatusCritical:
+		code = "critical_stock"
 	}
 
 	return &ProductAlert{
@@ -141,9 +240,10 @@ func (p *Product) GenerateAlert(threshold int) *ProductAlert {
 		Name:        p.Name,
 		Category:    p.Category,
 		Quantity:    p.Quantity,
-		Threshold:   threshold,
-		Severity:    severity,
-		Message:     message,
+		Threshold:   alertThreshold,
+		Severity:    bands.Severity(status),
+		Code:        code,
+		Message:     i18n.T(lang, "alert."+code),
 		GeneratedAt: time.Now(),
 	}
 }
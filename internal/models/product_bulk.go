@@ -0,0 +1,115 @@
+package models
+
+// Operaciones de POST /products/bulk
+const (
+	BulkOpCreate      = "create"
+	BulkOpUpdate      = "update"
+	BulkOpDelete      = "delete"
+	BulkOpAdjustStock = "adjust_stock"
+)
+
+// Modos de ejecución de las operaciones por lotes
+const (
+	BulkModeAtomic  = "atomic"   // Todas las operaciones en una transacción: si una falla no se aplica ninguna
+	BulkModePerItem = "per_item" // Cada operación se aplica o falla por separado
+)
+
+// MaxBulkOperations es el número máximo de operaciones por petición
+const MaxBulkOperations = 1000
+
+// ProductBulkOperation representa una operación sobre un producto
+type ProductBulkOperation struct {
+	Op      string          `json:"op"`
+	ID      uint            `json:"id,omitempty"`      // update, delete y adjust_stock
+	Product *ProductRequest `json:"product,omitempty"` // create y update (producto completo, como en PUT)
+	Delta   int             `json:"delta,omitempty"`   // adjust_stock: unidades a sumar (negativo para restar)
+}
+
+// ProductBulkRequest representa un lote de operaciones sobre productos
+type ProductBulkRequest struct {
+	Mode       string                 `json:"mode" validate:"omitempty,oneof=atomic per_item"` // BulkModeAtomic (por defecto) o BulkModePerItem
+	DryRun     bool                   `json:"dry_run"`
+	Operations []ProductBulkOperation `json:"operations" validate:"required,min=1,max=1000"` // Cada operación se valida y se informa por separado
+}
+
+// ProductBulkResult representa el resultado de una operación del lote
+type ProductBulkResult struct {
+	Index   int              `json:"index"` // Posición de la operación en la petición
+	Op      string           `json:"op"`
+	ID      uint             `json:"id,omitempty"`
+	Status  string           `json:"status"` // ok o error
+	Field   string           `json:"field,omitempty"`
+	Error   string           `json:"error,omitempty"`
+	Product *ProductResponse `json:"product,omitempty"`
+}
+
+// ProductBulkResponse representa el resultado de un lote de operaciones
+type ProductBulkResponse struct {
+	Mode      string              `json:"mode"`
+	DryRun    bool                `json:"dry_run"`
+	Committed bool                `json:"committed"` // Falso en simulaciones y en atomic con errores
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+	Results   []ProductBulkResult `json:"results"`
+}
+
+// Cambios de la actualización masiva de productos
+const (
+	MassUpdateSet             = "set"
+	MassUpdateIncrease        = "increase"
+	MassUpdateDecrease        = "decrease"
+	MassUpdateIncreasePercent = "increase_percent"
+	MassUpdateDecreasePercent = "decrease_percent"
+)
+
+// MassUpdatePreviewLimit es el máximo de productos que se detallan en la respuesta
+const MassUpdatePreviewLimit = 100
+
+// ProductMassUpdateChange representa el cambio de un campo numérico, p. ej. increase_percent 5
+type ProductMassUpdateChange struct {
+	Op    string  `json:"op" validate:"required,oneof=set increase decrease increase_percent decrease_percent"`
+	Value float64 `json:"value" validate:"min=0"`
+}
+
+// ProductMassUpdateRequest representa un cambio aplicado a todos los productos que cumplen los filtros
+type ProductMassUpdateRequest struct {
+	Price    *ProductMassUpdateChange `json:"price"`
+	Quantity *ProductMassUpdateChange `json:"quantity"`
+	All      bool                     `json:"all"`     // Confirma que se actualicen todos los productos si no hay filtros
+	DryRun   bool                     `json:"dry_run"` // Vista previa: calcula los cambios sin guardarlos
+}
+
+// ProductMassUpdateValues representa los campos que puede cambiar la actualización masiva
+type ProductMassUpdateValues struct {
+	Price    float64 `json:"price"`
+	Quantity int     `json:"quantity"`
+}
+
+// ProductMassUpdateItem representa el cambio calculado para un producto
+type ProductMassUpdateItem struct {
+	ID     uint                    `json:"id"`
+	Name   string                  `json:"name"`
+	Before ProductMassUpdateValues `json:"before"`
+	After  ProductMassUpdateValues `json:"after"`
+	Error  string                  `json:"error,omitempty"`
+}
+
+// ProductMassUpdateResult representa el resultado (o la vista previa) de una actualización masiva
+type ProductMassUpdateResult struct {
+	DryRun    bool                    `json:"dry_run"`
+	Committed bool                    `json:"committed"`
+	Matched   int                     `json:"matched"` // Productos que cumplen los filtros
+	Changed   int                     `json:"changed"` // Productos cuyo valor cambia
+	Failed    int                     `json:"failed"`  // Productos cuyo valor quedaría fuera de rango
+	Items     []ProductMassUpdateItem `json:"items"`   // Los productos con error y, hasta el límite, los que cambian
+	Truncated bool                    `json:"truncated"`
+}
+
+// IsValidMassUpdateOp verifica si el cambio de la actualización masiva existe
+func IsValidMassUpdateOp(op string) bool {
+	switch op {
+	case MassUpdateSet, MassUpdateIncrease, MassUpdateDecrease, MassUpdateIncreasePercent, MassUpdateDecreasePercent:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package models
+
+// Modos de confirmación de la importación de productos
+const (
+	ImportModeAllOrNothing = "all_or_nothing" // Un error en cualquier fila descarta toda la importación
+	ImportModeBestEffort   = "best_effort"    // Se guardan las filas válidas y se informan las erróneas
+)
+
+// Campos de producto a los que se puede asignar una columna del CSV
+// Además, las columnas attr.<clave> se asignan a los atributos personalizados
+var ProductImportFields = []string{"sku", "name", "description", "quantity", "reorder_point", "price", "category", "category_id", "tags"}
+
+// ProductImportTagSeparator separa las etiquetas dentro de la columna tags
+const ProductImportTagSeparator = "|"
+
+// ProductImportOptions representa las opciones de una importación de productos
+type ProductImportOptions struct {
+	Mapping   map[string]string // Cabecera del CSV -> campo; las cabeceras con el nombre del campo no lo necesitan
+	Mode      string            // ImportModeAllOrNothing (por defecto) o ImportModeBestEffort
+	DryRun    bool              // Valida y simula la importación sin guardar nada
+	Delimiter rune              // Separador de columnas (por defecto la coma)
+}
+
+// ProductImportRowError representa un error de validación de una fila del CSV
+type ProductImportRowError struct {
+	Row    int    `json:"row"` // Número de línea en el archivo, contando la cabecera
+	SKU    string `json:"sku,omitempty"`
+	Column string `json:"column,omitempty"`
+	Error  string `json:"error"`
+}
+
+// ProductImportResult representa el resultado de una importación de productos
+type ProductImportResult struct {
+	Mode           string                  `json:"mode"`
+	DryRun         bool                    `json:"dry_run"`
+	Committed      bool                    `json:"committed"` // Falso en simulaciones y en all_or_nothing con errores
+	Rows           int                     `json:"rows"`
+	Created        int                     `json:"created"`
+	Updated        int                     `json:"updated"`
+	Failed         int                     `json:"failed"`
+	IgnoredColumns []string                `json:"ignored_columns,omitempty"` // Cabeceras sin campo asignado
+	Errors         []ProductImportRowError `json:"errors"`
+}
+
+// IsValidImportMode verifica si el modo de importación existe
+func IsValidImportMode(mode string) bool {
+	return mode == ImportModeAllOrNothing || mode == ImportModeBestEffort
+}
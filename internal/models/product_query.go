@@ -0,0 +1,104 @@
+package models
+
+// Estados de stock calculados por GetStockStatus
+const (
+	StockStatusOutOfStock = "out_of_stock"
+	StockStatusCritical   = "critical"
+	StockStatusLow        = "low"
+	StockStatusNormal     = "normal"
+)
+
+// Campos por los que se puede ordenar el listado de productos
+var ProductSortFields = []string{"name", "price", "quantity", "created_at", "updated_at"}
+
+// Operadores de los filtros del listado de productos
+const (
+	FilterOpEq  = "eq"
+	FilterOpNe  = "ne"
+	FilterOpIn  = "in"
+	FilterOpGt  = "gt"
+	FilterOpGte = "gte"
+	FilterOpLt  = "lt"
+	FilterOpLte = "lte"
+)
+
+// ProductFilter representa una condición sobre un campo, p. ej. price gte 10
+// Values tiene un único valor salvo con el operador in
+type ProductFilter struct {
+	Field  string
+	Op     string
+	Values []string
+}
+
+// ProductListQuery representa la paginación, orden y filtros del listado de productos
+// Los filtros se combinan con AND
+type ProductListQuery struct {
+	Limit   int
+	Cursor  string // Token opaco devuelto como next_cursor en la página anterior
+	Sort    string // Uno de ProductSortFields (por defecto id)
+	Desc    bool
+	Filters []ProductFilter
+}
+
+// String representa el filtro con la sintaxis de la query string
+func (f ProductFilter) String() string {
+	return "filter[" + f.Field + "][" + f.Op + "]"
+}
+
+// ProductPage representa una página del listado de productos
+type ProductPage struct {
+	Products   []ProductResponse `json:"products"`
+	Total      int64             `json:"total"` // Productos que cumplen los filtros, sin paginar
+	Limit      int               `json:"limit"`
+	NextCursor string            `json:"next_cursor,omitempty"` // Vacío en la última página
+}
+
+// IsValidStockStatus verifica si el estado de stock existe
+func IsValidStockStatus(status string) bool {
+	switch status {
+	case StockStatusOutOfStock, StockStatusCritical, StockStatusLow, StockStatusNormal:
+		return true
+	}
+	return false
+}
+
+// IsValidProductSortField verifica si se puede ordenar por el campo indicado
+func IsValidProductSortField(field string) bool {
+	for _, f := range ProductSortFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Tipos de coincidencia de la búsqueda de productos
+const (
+	SearchMatchFullText = "fulltext" // Coincidencia de palabras (con raíces en español e inglés)
+	SearchMatchFuzzy    = "fuzzy"    // Similitud de trigramas, tolera errores de escritura
+)
+
+// ProductSearchResult representa un producto encontrado con su relevancia
+type ProductSearchResult struct {
+	ProductResponse
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"` // Fragmento con las coincidencias entre <mark> y </mark>
+	Match   string  `json:"match"`
+}
+
+// Tipos de coincidencia de las sugerencias de productos
+const (
+	SuggestMatchSKU     = "sku"     // El SKU empieza por el texto
+	SuggestMatchPrefix  = "prefix"  // El nombre o una de sus palabras empieza por el texto
+	SuggestMatchTrigram = "trigram" // Similitud de trigramas con el nombre
+)
+
+// ProductSuggestion representa una sugerencia de autocompletado de producto
+type ProductSuggestion struct {
+	ID       uint    `json:"id"`
+	SKU      string  `json:"sku,omitempty"`
+	Name     string  `json:"name"`
+	Category string  `json:"category"`
+	Score    float64 `json:"score"`
+	Match    string  `json:"match"`
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+)
+
+// Tipos de cambio que generan una versión de producto
+const (
+	ProductChangeCreate = "create"
+	ProductChangeUpdate = "update"
+	ProductChangeStock  = "stock"
+)
+
+// ProductVersion guarda el estado completo de un producto a partir de un instante
+// La versión vigente en una fecha es la última con ValidFrom anterior o igual a ella
+type ProductVersion struct {
+	ID             uint              `gorm:"primaryKey" json:"-"`
+	ProductID      uint              `gorm:"not null;uniqueIndex:idx_product_versions_product_version;index:idx_product_versions_valid_from,priority:1" json:"product_id"`
+	OrganizationID uint              `gorm:"not null;index" json:"-"`
+	Version        int               `gorm:"not null;uniqueIndex:idx_product_versions_product_version" json:"version"`
+	ChangeType     string            `gorm:"not null;size:16" json:"change_type"`
+	SKU            *string           `gorm:"column:sku;size:64" json:"sku,omitempty"`
+	Name           string            `gorm:"not null" json:"name"`
+	Description    string            `gorm:"type:text" json:"description"`
+	Quantity       int               `gorm:"not null" json:"quantity"`
+	Price          float64           `gorm:"not null;type:decimal(10,2)" json:"price"`
+	Category       string            `gorm:"not null" json:"category"`
+	Tags           []string          `gorm:"serializer:json;type:jsonb" json:"tags"`
+	Attributes     ProductAttributes `gorm:"serializer:json;type:jsonb" json:"attributes"`
+	ChangedBy      *uint             `json:"changed_by,omitempty"`
+	ChangedByEmail string            `json:"changed_by_email,omitempty"`
+	ValidFrom      time.Time         `gorm:"not null;index:idx_product_versions_valid_from,priority:2" json:"valid_from"`
+}
+
+// NewProductVersion crea la instantánea del estado actual de un producto
+func NewProductVersion(p *Product, changeType string, audit AuditContext, validFrom time.Time) ProductVersion {
+	return ProductVersion{
+		ProductID:      p.ID,
+		OrganizationID: p.OrganizationID,
+		ChangeType:     changeType,
+		SKU:            p.SKU,
+		Name:           p.Name,
+		Description:    p.Description,
+		Quantity:       p.Quantity,
+		Price:          p.Price,
+		Category:       p.Category,
+		Tags:           p.TagNames(),
+		Attributes:     p.AttributeValues(),
+		ChangedBy:      audit.ActorID,
+		ChangedByEmail: audit.ActorEmail,
+		ValidFrom:      validFrom,
+	}
+}
+
+// ToResponse convierte la versión en la respuesta del producto tal como era entonces
+// current es el producto actual: el estado de stock se calcula con su categoría y su punto de
+// pedido, que las versiones no guardan, y con las reglas de stock vigentes
+func (v *ProductVersion) ToResponse(current *Product, rules *StockRules) ProductResponse {
+	product := Product{
+		ID:           v.ProductID,
+		SKU:          v.SKU,
+		Name:         v.Name,
+		Description:  v.Description,
+		Quantity:     v.Quantity,
+		Price:        v.Price,
+		Category:     v.Category,
+		Attributes:   v.Attributes,
+		CategoryID:   current.CategoryID,
+		ReorderPoint: current.ReorderPoint,
+		CreatedAt:    current.CreatedAt,
+		UpdatedAt:    v.ValidFrom,
+	}
+	response := product.ToResponse(rules)
+	if v.Tags != nil {
+		response.Tags = v.Tags
+	}
+	return response
+}
+
+// TableName especifica el nombre de la tabla
+func (ProductVersion) TableName() string {
+	return "product_versions"
+}
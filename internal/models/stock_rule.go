@@ -0,0 +1,236 @@
+package models
+
+import "time"
+
+// Ámbitos de las reglas de stock. Prevalece la regla más concreta: la del producto, la de su
+// categoría o de la antecesora más cercana que tenga una, la global y, sin ninguna, los umbrales
+// por defecto (LowStockThreshold y CriticalStockThreshold)
+const (
+	StockRuleScopeGlobal   = "global"
+	StockRuleScopeCategory = "category" // Aplica también a las subcategorías sin regla propia
+	StockRuleScopeProduct  = "product"
+)
+
+// Modos de los umbrales de una regla de stock
+const (
+	StockRuleModeAbsolute = "absolute" // Unidades en stock
+	StockRuleModePercent  = "percent"  // Porcentaje del punto de pedido del producto
+)
+
+// Severidades de las alertas de stock
+const (
+	AlertSeverityLow      = "low"
+	AlertSeverityMedium   = "medium"
+	AlertSeverityHigh     = "high"
+	AlertSeverityCritical = "critical"
+)
+
+// StockRule define las bandas del estado de stock y la severidad de las alertas de cada banda
+// Una cantidad de 0 siempre es out_of_stock; hasta CriticalThreshold (incluido) es critical,
+// hasta LowThreshold es low y por encima normal
+type StockRule struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	OrganizationID     uint      `gorm:"not null;index" json:"-"`
+	Scope              string    `gorm:"not null;size:16" json:"scope"`
+	CategoryID         *uint     `gorm:"index" json:"category_id,omitempty"` // Solo en las reglas de categoría
+	ProductID          *uint     `gorm:"index" json:"product_id,omitempty"`  // Solo en las reglas de producto
+	Mode               string    `gorm:"not null;size:16" json:"mode"`
+	LowThreshold       float64   `gorm:"not null" json:"low_threshold"`
+	CriticalThreshold  float64   `gorm:"not null" json:"critical_threshold"`
+	LowSeverity        string    `gorm:"not null;size:16" json:"low_severity"`
+	CriticalSeverity   string    `gorm:"not null;size:16" json:"critical_severity"`
+	OutOfStockSeverity string    `gorm:"not null;size:16" json:"out_of_stock_severity"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// StockRuleRequest representa la estructura para crear/actualizar reglas de stock
+// Al crear, el modo y las severidades omitidos toman los valores por defecto (absolute; low, high
+// y critical); al actualizar conservan los de la regla. Los porcentajes no pueden superar 100
+type StockRuleRequest struct {
+	Scope              string  `json:"scope" validate:"omitempty,oneof=global category product"` // Obligatorio al crear; no se puede cambiar
+	CategoryID         *uint   `json:"category_id"`                                              // Obligatorio en las reglas de categoría
+	ProductID          *uint   `json:"product_id"`                                               // Obligatorio en las reglas de producto
+	Mode               string  `json:"mode" validate:"omitempty,oneof=absolute percent"`         // Por defecto absolute o el actual
+	LowThreshold       float64 `json:"low_threshold" validate:"min=0"`
+	CriticalThreshold  float64 `json:"critical_threshold" validate:"min=0"`
+	LowSeverity        string  `json:"low_severity" validate:"omitempty,oneof=low medium high critical"`
+	CriticalSeverity   string  `json:"critical_severity" validate:"omitempty,oneof=low medium high critical"`
+	OutOfStockSeverity string  `json:"out_of_stock_severity" validate:"omitempty,oneof=low medium high critical"`
+}
+
+// StockBands son los umbrales, ya en unidades, y las severidades que se aplican a un producto
+type StockBands struct {
+	Low                float64
+	Critical           float64
+	LowSeverity        string
+	CriticalSeverity   string
+	OutOfStockSeverity string
+}
+
+// DefaultStockBands son las bandas de los productos sin ninguna regla aplicable
+var DefaultStockBands = StockBands{
+	Low:                LowStockThreshold,
+	Critical:           CriticalStockThreshold,
+	LowSeverity:        AlertSeverityLow,
+	CriticalSeverity:   AlertSeverityHigh,
+	OutOfStockSeverity: AlertSeverityCritical,
+}
+
+// Status retorna el estado de stock de una cantidad según las bandas
+func (b StockBands) Status(quantity int) string {
+	switch {
+	case quantity == 0:
+		return StockStatusOutOfStock
+	case float64(quantity) <= b.Critical:
+		return StockStatusCritical
+	case float64(quantity) <= b.Low:
+		return StockStatusLow
+	default:
+		return StockStatusNormal
+	}
+}
+
+// Severity retorna la severidad de las alertas de un estado de stock
+// Los productos en estado normal solo generan alerta con un umbral explícito: usan la de low
+func (b StockBands) Severity(status string) string {
+	switch status {
+	case StockStatusOutOfStock:
+		return b.OutOfStockSeverity
+	case StockStatusCritical:
+		return b.CriticalSeverity
+	default:
+		return b.LowSeverity
+	}
+}
+
+// IsValidAlertSeverity verifica si la severidad existe
+func IsValidAlertSeverity(severity string) bool {
+	switch severity {
+	case AlertSeverityLow, AlertSeverityMedium, AlertSeverityHigh, AlertSeverityCritical:
+		return true
+	}
+	return false
+}
+
+// AppliesTo indica si la regla puede evaluarse para el producto: las de porcentaje necesitan
+// un punto de pedido mayor que 0; si no lo tiene se recurre a la regla del siguiente ámbito
+func (r *StockRule) AppliesTo(p *Product) bool {
+	return r.Mode != StockRuleModePercent || (p.ReorderPoint != nil && *p.ReorderPoint > 0)
+}
+
+// Bands retorna las bandas de la regla para el producto, con los porcentajes convertidos a unidades
+func (r *StockRule) Bands(p *Product) StockBands {
+	bands := StockBands{
+		Low:                r.LowThreshold,
+		Critical:           r.CriticalThreshold,
+		LowSeverity:        r.LowSeverity,
+		CriticalSeverity:   r.CriticalSeverity,
+		OutOfStockSeverity: r.OutOfStockSeverity,
+	}
+	if r.Mode == StockRuleModePercent && p.ReorderPoint != nil {
+		reorderPoint := float64(*p.ReorderPoint)
+		bands.Low = reorderPoint * r.LowThreshold / 100
+		bands.Critical = reorderPoint * r.CriticalThreshold / 100
+	}
+	return bands
+}
+
+// AuditFields retorna los campos de la regla que se comparan en la auditoría
+func (r *StockRule) AuditFields() map[string]interface{} {
+	return map[string]interface{}{
+		"scope":                 r.Scope,
+		"category_id":           optionalIDValue(r.CategoryID),
+		"product_id":            optionalIDValue(r.ProductID),
+		"mode":                  r.Mode,
+		"low_threshold":         r.LowThreshold,
+		"critical_threshold":    r.CriticalThreshold,
+		"low_severity":          r.LowSeverity,
+		"critical_severity":     r.CriticalSeverity,
+		"out_of_stock_severity": r.OutOfStockSeverity,
+	}
+}
+
+// optionalIDValue retorna el ID como valor (nil si no hay), para compararlo en la auditoría
+func optionalIDValue(id *uint) interface{} {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
+
+// TableName especifica el nombre de la tabla
+func (StockRule) TableName() string {
+	return "stock_rules"
+}
+
+// StockRules son las reglas de stock de una organización, indexadas para resolver las de cada
+// producto. Un *StockRules nil equivale a no tener reglas: se usan las bandas por defecto
+type StockRules struct {
+	Global     *StockRule
+	Categories map[uint]*StockRule // Por categoría
+	Products   map[uint]*StockRule // Por producto
+	Parents    map[uint]*uint      // Categoría padre de cada categoría, para heredar sus reglas
+}
+
+// NewStockRules indexa las reglas de una organización; parents es el árbol de sus categorías
+func NewStockRules(rules []StockRule, parents map[uint]*uint) *StockRules {
+	set := &StockRules{
+		Categories: make(map[uint]*StockRule),
+		Products:   make(map[uint]*StockRule),
+		Parents:    parents,
+	}
+	for i := range rules {
+		rule := &rules[i]
+		switch {
+		case rule.Scope == StockRuleScopeProduct && rule.ProductID != nil:
+			set.Products[*rule.ProductID] = rule
+		case rule.Scope == StockRuleScopeCategory && rule.CategoryID != nil:
+			set.Categories[*rule.CategoryID] = rule
+		case rule.Scope == StockRuleScopeGlobal:
+			set.Global = rule
+		}
+	}
+	return set
+}
+
+// CategoryChain retorna las reglas de la categoría y de sus antecesoras, de la más cercana a la
+// raíz. Se recorre como mucho tantas categorías como haya, por si el árbol tuviera un ciclo
+func (s *StockRules) CategoryChain(categoryID *uint) []*StockRule {
+	if s == nil || categoryID == nil {
+		return nil
+	}
+	var chain []*StockRule
+	id := categoryID
+	for steps := 0; id != nil && steps <= len(s.Parents); steps++ {
+		if rule, ok := s.Categories[*id]; ok {
+			chain = append(chain, rule)
+		}
+		id = s.Parents[*id]
+	}
+	return chain
+}
+
+// Rule retorna la regla que se aplica al producto, o nil si se usan las bandas por defecto
+func (s *StockRules) Rule(p *Product) *StockRule {
+	if s == nil {
+		return nil
+	}
+	candidates := []*StockRule{s.Products[p.ID]}
+	candidates = append(candidates, s.CategoryChain(p.CategoryID)...)
+	candidates = append(candidates, s.Global)
+	for _, rule := range candidates {
+		if rule != nil && rule.AppliesTo(p) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Bands retorna las bandas que se aplican al producto
+func (s *StockRules) Bands(p *Product) StockBands {
+	if rule := s.Rule(p); rule != nil {
+		return rule.Bands(p)
+	}
+	return DefaultStockBands
+}
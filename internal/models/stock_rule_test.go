@@ -0,0 +1,118 @@
+package models
+
+import "testing"
+
+func uintPtr(v uint) *uint { return &v }
+
+func intPtr(v int) *int { return &v }
+
+// testStockRules es el árbol de categorías y las reglas de los tests de resolución:
+//
+//	1 (porcentaje 50/20) → 2 (absoluta 8/3) → 3 (sin regla)
+//	4 (sin regla) → 5 (porcentaje 40/10)
+//	6 (sin regla)
+//
+// Además hay una regla global absoluta (6/1) y reglas para los productos 100 (porcentaje 30/10)
+// y 101 (absoluta 2/1)
+func testStockRules() *StockRules {
+	rules := []StockRule{
+		{ID: 1, Scope: StockRuleScopeGlobal, Mode: StockRuleModeAbsolute, LowThreshold: 6, CriticalThreshold: 1,
+			LowSeverity: AlertSeverityMedium, CriticalSeverity: AlertSeverityHigh, OutOfStockSeverity: AlertSeverityCritical},
+		{ID: 2, Scope: StockRuleScopeCategory, CategoryID: uintPtr(1), Mode: StockRuleModePercent, LowThreshold: 50, CriticalThreshold: 20,
+			LowSeverity: AlertSeverityLow, CriticalSeverity: AlertSeverityMedium, OutOfStockSeverity: AlertSeverityHigh},
+		{ID: 3, Scope: StockRuleScopeCategory, CategoryID: uintPtr(2), Mode: StockRuleModeAbsolute, LowThreshold: 8, CriticalThreshold: 3,
+			LowSeverity: AlertSeverityLow, CriticalSeverity: AlertSeverityCritical, OutOfStockSeverity: AlertSeverityCritical},
+		{ID: 4, Scope: StockRuleScopeCategory, CategoryID: uintPtr(5), Mode: StockRuleModePercent, LowThreshold: 40, CriticalThreshold: 10,
+			LowSeverity: AlertSeverityLow, CriticalSeverity: AlertSeverityHigh, OutOfStockSeverity: AlertSeverityCritical},
+		{ID: 5, Scope: StockRuleScopeProduct, ProductID: uintPtr(100), Mode: StockRuleModePercent, LowThreshold: 30, CriticalThreshold: 10,
+			LowSeverity: AlertSeverityLow, CriticalSeverity: AlertSeverityHigh, OutOfStockSeverity: AlertSeverityCritical},
+		{ID: 6, Scope: StockRuleScopeProduct, ProductID: uintPtr(101), Mode: StockRuleModeAbsolute, LowThreshold: 2, CriticalThreshold: 1,
+			LowSeverity: AlertSeverityLow, CriticalSeverity: AlertSeverityHigh, OutOfStockSeverity: AlertSeverityCritical},
+	}
+	parents := map[uint]*uint{1: nil, 2: uintPtr(1), 3: uintPtr(2), 4: nil, 5: uintPtr(4), 6: nil}
+	return NewStockRules(rules, parents)
+}
+
+func TestStockRulesResolution(t *testing.T) {
+	rules := testStockRules()
+	withoutGlobal := testStockRules()
+	withoutGlobal.Global = nil
+
+	tests := []struct {
+		name     string
+		rules    *StockRules
+		product  Product
+		wantRule uint // 0 si se usan las bandas por defecto
+		wantLow  float64
+		wantCrit float64
+	}{
+		{"product percent rule", rules, Product{ID: 100, CategoryID: uintPtr(3), ReorderPoint: intPtr(20)}, 5, 6, 2},
+		{"product percent rule without reorder point falls through to the category chain", rules, Product{ID: 100, CategoryID: uintPtr(3)}, 3, 8, 3},
+		{"product absolute rule without category", rules, Product{ID: 101}, 6, 2, 1},
+		{"product rule wins over the category rule", rules, Product{ID: 101, CategoryID: uintPtr(2)}, 6, 2, 1},
+		{"category rule", rules, Product{ID: 1, CategoryID: uintPtr(2)}, 3, 8, 3},
+		{"nearest ancestor rule", rules, Product{ID: 2, CategoryID: uintPtr(3)}, 3, 8, 3},
+		{"category percent rule", rules, Product{ID: 3, CategoryID: uintPtr(1), ReorderPoint: intPtr(10)}, 2, 5, 2},
+		{"category percent rule without reorder point falls through to global", rules, Product{ID: 4, CategoryID: uintPtr(1)}, 1, 6, 1},
+		{"category percent rule with a zero reorder point falls through to global", rules, Product{ID: 5, CategoryID: uintPtr(5), ReorderPoint: intPtr(0)}, 1, 6, 1},
+		{"ancestor percent rule", rules, Product{ID: 6, CategoryID: uintPtr(5), ReorderPoint: intPtr(50)}, 4, 20, 5},
+		{"category without rules uses global", rules, Product{ID: 7, CategoryID: uintPtr(6)}, 1, 6, 1},
+		{"product without category uses global", rules, Product{ID: 8}, 1, 6, 1},
+		{"no applicable rule uses the defaults", withoutGlobal, Product{ID: 9, CategoryID: uintPtr(1)}, 0, LowStockThreshold, CriticalStockThreshold},
+		{"nil rules use the defaults", nil, Product{ID: 10, CategoryID: uintPtr(2)}, 0, LowStockThreshold, CriticalStockThreshold},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := tt.rules.Rule(&tt.product)
+			switch {
+			case tt.wantRule == 0 && rule != nil:
+				t.Fatalf("got rule %d, want the default bands", rule.ID)
+			case tt.wantRule != 0 && (rule == nil || rule.ID != tt.wantRule):
+				t.Fatalf("got rule %v, want rule %d", rule, tt.wantRule)
+			}
+
+			bands := tt.rules.Bands(&tt.product)
+			if bands.Low != tt.wantLow || bands.Critical != tt.wantCrit {
+				t.Fatalf("got bands low=%v critical=%v, want low=%v critical=%v", bands.Low, bands.Critical, tt.wantLow, tt.wantCrit)
+			}
+			if rule != nil && (bands.LowSeverity != rule.LowSeverity || bands.CriticalSeverity != rule.CriticalSeverity ||
+				bands.OutOfStockSeverity != rule.OutOfStockSeverity) {
+				t.Fatalf("got severities %+v, want those of rule %d", bands, rule.ID)
+			}
+		})
+	}
+}
+
+func TestStockRulesCategoryCycle(t *testing.T) {
+	// Un ciclo en el árbol no debe colgar la resolución
+	rules := NewStockRules(nil, map[uint]*uint{1: uintPtr(2), 2: uintPtr(1)})
+	if rule := rules.Rule(&Product{ID: 1, CategoryID: uintPtr(1)}); rule != nil {
+		t.Fatalf("got rule %d, want none", rule.ID)
+	}
+}
+
+func TestStockBandsStatus(t *testing.T) {
+	bands := StockBands{Low: 5, Critical: 2}
+	tests := []struct {
+		quantity int
+		want     string
+	}{
+		{0, StockStatusOutOfStock},
+		{1, StockStatusCritical},
+		{2, StockStatusCritical}, // quantity == critical
+		{3, StockStatusLow},
+		{5, StockStatusLow}, // quantity == low
+		{6, StockStatusNormal},
+	}
+	for _, tt := range tests {
+		if got := bands.Status(tt.quantity); got != tt.want {
+			t.Errorf("Status(%d) = %s, want %s", tt.quantity, got, tt.want)
+		}
+	}
+
+	// Con un umbral crítico de 0 el stock bajo empieza en 1
+	if got := (StockBands{Low: 3, Critical: 0}).Status(1); got != StockStatusLow {
+		t.Errorf("Status(1) with critical 0 = %s, want %s", got, StockStatusLow)
+	}
+}
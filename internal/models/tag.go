@@ -0,0 +1,41 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// MaxProductTags es el número máximo de etiquetas por producto
+const MaxProductTags = 20
+
+// Tag es una etiqueta libre de la organización que se asigna a varios productos
+type Tag struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	OrganizationID uint      `gorm:"not null;uniqueIndex:idx_tags_org_slug" json:"-"`
+	Name           string    `gorm:"not null;size:50" json:"name"`
+	Slug           string    `gorm:"not null;size:64;uniqueIndex:idx_tags_org_slug" json:"slug"` // Único en la organización
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TagResponse representa una etiqueta con el número de productos que la usan
+type TagResponse struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	ProductCount int64  `json:"product_count"`
+}
+
+// TagNames retorna los nombres de las etiquetas del producto ordenados alfabéticamente
+func (p *Product) TagNames() []string {
+	names := make([]string, 0, len(p.Tags))
+	for _, tag := range p.Tags {
+		names = append(names, tag.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TableName especifica el nombre de la tabla
+func (Tag) TableName() string {
+	return "tags"
+}
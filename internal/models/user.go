@@ -3,57 +3,95 @@ package models
 import (
 	"time"
 
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 // User representa un usuario del sistema
 type User struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Email     string    `gorm:"uniqueIndex;not null" json:"email" validate:"required,email"`
-	Password  string    `gorm:"not null" json:"-"` // No incluir en JSON responses
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	Email           string     `gorm:"uniqueIndex;not null" json:"email" validate:"required,email"`
+	Password        string     `gorm:"not null" json:"-"` // No incluir en JSON responses
+	Role            string     `gorm:"not null;default:user" json:"role"`
+	OIDCIssuer      *string    `gorm:"column:oidc_issuer;uniqueIndex:idx_users_oidc_identity" json:"-"`  // Proveedor de identidad externo
+	OIDCSubject     *string    `gorm:"column:oidc_subject;uniqueIndex:idx_users_oidc_identity" json:"-"` // "sub" del usuario en el proveedor
+	EmailVerified   bool       `gorm:"not null;default:false" json:"email_verified"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	MFAEnabled      bool       `gorm:"not null;default:false" json:"mfa_enabled"` // Segundo factor (TOTP)
+	MFASecret       string     `json:"-"`                                         // Secreto base32, pendiente hasta que MFAEnabled sea true
+	MFALastStep     int64      `gorm:"not null;default:0" json:"-"`               // Último paso TOTP usado (anti-replay)
+	FailedLogins    int        `gorm:"not null;default:0" json:"-"`               // Intentos fallidos consecutivos
+	LastFailedLogin *time.Time `json:"-"`
+	LockedUntil     *time.Time `json:"locked_until,omitempty"`
+	Language        string     `gorm:"size:8" json:"language"` // Idioma preferido (es, en); vacío usa Accept-Language
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 // UserRequest representa la estructura para registro/login
 type UserRequest struct {
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
+	Password string `json:"password" validate:"required"`
 }
 
 // UserResponse representa la respuesta sin datos sensibles
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            uint      `json:"id"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"email_verified"`
+	MFAEnabled    bool      `json:"mfa_enabled"`
+	Language      string    `json:"language"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// LanguageRequest representa el cambio de idioma preferido; vacío vuelve a usar Accept-Language
+type LanguageRequest struct {
+	Language string `json:"language" validate:"omitempty,oneof=es en"`
 }
 
 // BeforeCreate es un hook de GORM que se ejecuta antes de crear un usuario
 func (u *User) BeforeCreate(tx *gorm.DB) error {
+	// Rol por defecto
+	if u.Role == "" {
+		u.Role = RoleUser
+	}
+
 	// Hash de la contraseña antes de guardar
 	if u.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+		hashedPassword, err := HashPassword(u.Password)
 		if err != nil {
 			return err
 		}
-		u.Password = string(hashedPassword)
+		u.Password = hashedPassword
 	}
 	return nil
 }
 
+// Permissions retorna los permisos concedidos por el rol del usuario
+func (u *User) Permissions() []string {
+	return PermissionsForRole(u.Role)
+}
+
 // CheckPassword verifica si la contraseña proporcionada coincide con el hash
 func (u *User) CheckPassword(password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
-	return err == nil
+	return verifyPassword(u.Password, password)
+}
+
+// PasswordNeedsRehash indica si el hash guardado debe regenerarse (bcrypt o parámetros antiguos)
+func (u *User) PasswordNeedsRehash() bool {
+	return passwordNeedsRehash(u.Password)
 }
 
 // ToResponse convierte User a UserResponse (sin datos sensibles)
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
-		ID:        u.ID,
-		Email:     u.Email,
-		CreatedAt: u.CreatedAt,
+		ID:            u.ID,
+		Email:         u.Email,
+		Role:          u.Role,
+		EmailVerified: u.EmailVerified,
+		MFAEnabled:    u.MFAEnabled,
+		Language:      u.Language,
+		CreatedAt:     u.CreatedAt,
 	}
 }
 
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+// Propósitos de los tokens de un solo uso enviados por correo
+const (
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposePasswordReset     = "password_reset"
+)
+
+// UserToken registra un token firmado enviado al usuario para poder consumirlo una sola vez
+type UserToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	Purpose   string     `gorm:"not null;index" json:"purpose"`
+	JTI       string     `gorm:"not null;uniqueIndex;size:64" json:"-"` // ID del JWT
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// EmailRequest representa una petición que solo necesita el email
+type EmailRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// TokenRequest representa la confirmación de un token enviado por correo
+type TokenRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// PasswordResetRequest representa el cambio de contraseña con un token de recuperación
+type PasswordResetRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// TableName especifica el nombre de la tabla
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
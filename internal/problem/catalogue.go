@@ -0,0 +1,177 @@
+package problem
+
+import (
+	"net/http"
+	"strings"
+
+	"inventory-api/internal/i18n"
+)
+
+// Type es un tipo de problema del catálogo: el código estable, el estado HTTP y el título
+// Los códigos forman parte del contrato de la API y no deben cambiar
+type Type struct {
+	Code   string `json:"code"`
+	Status int    `json:"status"`
+	Title  string `json:"title"`
+}
+
+// URI retorna la URI del tipo, que se envía en el miembro type
+func (t Type) URI() string {
+	return TypeBase + t.Code
+}
+
+// New crea un problema de este tipo
+func (t Type) New() *Problem {
+	return &Problem{Type: t.URI(), Title: t.Title, Status: t.Status, Code: t.Code}
+}
+
+// Detail crea un problema de este tipo con una explicación de la aparición concreta
+func (t Type) Detail(detail string) *Problem {
+	p := t.New()
+	p.Detail = detail
+	return p
+}
+
+// Message crea un problema de este tipo cuyo detalle es un mensaje del catálogo de i18n
+// El detalle queda en inglés hasta que el manejador de errores lo traduce con Localize
+func (t Type) Message(key string, args ...interface{}) *Problem {
+	p := t.Detail(i18n.T(i18n.English, key, args...))
+	p.detailKey = key
+	p.detailArgs = args
+	return p
+}
+
+// catalogue son los tipos definidos, en el orden en que se documentan
+var catalogue []Type
+
+// define registra un tipo en el catálogo
+func define(code string, status int, title string) Type {
+	t := Type{Code: code, Status: status, Title: title}
+	catalogue = append(catalogue, t)
+	return t
+}
+
+// Errores generales de las peticiones
+var (
+	BadRequest              = define("bad_request", http.StatusBadRequest, "Bad request")
+	InvalidRequest          = define("invalid_request", http.StatusBadRequest, "Invalid request format")
+	ValidationFailed        = define("validation_failed", http.StatusBadRequest, "Validation failed")
+	InvalidParameter        = define("invalid_parameter", http.StatusBadRequest, "Invalid parameter")
+	Unauthorized            = define("unauthorized", http.StatusUnauthorized, "Authentication required")
+	InvalidToken            = define("invalid_token", http.StatusUnauthorized, "Invalid or expired token")
+	InvalidAPIKey           = define("invalid_api_key", http.StatusUnauthorized, "Invalid or revoked API key")
+	Forbidden               = define("forbidden", http.StatusForbidden, "Forbidden")
+	InsufficientPermissions = define("insufficient_permissions", http.StatusForbidden, "Insufficient permissions")
+	MFARequired             = define("mfa_required", http.StatusForbidden, "Two-factor authentication required for this action")
+	SessionRequired         = define("session_required", http.StatusForbidden, "This endpoint requires a user session token")
+	OrganizationRequired    = define("organization_required", http.StatusForbidden, "No active organization")
+	NotMember               = define("not_a_member", http.StatusForbidden, "You are not a member of this organization")
+	NotFound                = define("not_found", http.StatusNotFound, "Resource not found")
+	MethodNotAllowed        = define("method_not_allowed", http.StatusMethodNotAllowed, "Method not allowed")
+	PayloadTooLarge         = define("payload_too_large", http.StatusRequestEntityTooLarge, "Request body is too large")
+	UnsupportedMediaType    = define("unsupported_media_type", http.StatusUnsupportedMediaType, "Unsupported media type")
+	TooManyRequests         = define("too_many_requests", http.StatusTooManyRequests, "Too many requests")
+	Internal                = define("internal_error", http.StatusInternalServerError, "Internal server error")
+)
+
+// Cuentas, sesiones y credenciales
+var (
+	UserExists                  = define("user_exists", http.StatusConflict, "User already exists")
+	UserNotFound                = define("user_not_found", http.StatusNotFound, "User not found")
+	InvalidCredentials          = define("invalid_credentials", http.StatusUnauthorized, "Invalid credentials")
+	LoginThrottled              = define("login_throttled", http.StatusTooManyRequests, "Too many failed login attempts")
+	AccountLocked               = define("account_locked", http.StatusLocked, "Account temporarily locked")
+	PasswordPolicy              = define("password_policy", http.StatusBadRequest, "Password does not meet the password policy")
+	InvalidLinkToken            = define("invalid_link_token", http.StatusBadRequest, "Invalid or expired verification or reset token")
+	EmailAlreadyVerified        = define("email_already_verified", http.StatusConflict, "Email already verified")
+	InvalidMFAToken             = define("invalid_mfa_token", http.StatusUnauthorized, "Invalid or expired MFA token")
+	InvalidMFACode              = define("invalid_mfa_code", http.StatusUnauthorized, "Invalid MFA code")
+	MFAAlreadyEnabled           = define("mfa_already_enabled", http.StatusConflict, "MFA already enabled")
+	MFANotEnabled               = define("mfa_not_enabled", http.StatusBadRequest, "MFA not enabled")
+	MFASetupNotStarted          = define("mfa_setup_not_started", http.StatusBadRequest, "MFA setup not started")
+	APIKeyNotFound              = define("api_key_not_found", http.StatusNotFound, "API key not found")
+	InvalidPermission           = define("invalid_permission", http.StatusBadRequest, "Invalid API key permission")
+	OIDCNotConfigured           = define("oidc_not_configured", http.StatusNotFound, "OIDC login is not configured")
+	OIDCLoginFailed             = define("oidc_login_failed", http.StatusUnauthorized, "OIDC login failed")
+	IdentityProviderUnavailable = define("identity_provider_unavailable", http.StatusBadGateway, "Identity provider unavailable")
+)
+
+// Organizaciones y miembros
+var (
+	OrganizationNotFound = define("organization_not_found", http.StatusNotFound, "Organization not found")
+	MemberNotFound       = define("member_not_found", http.StatusNotFound, "Member not found")
+	AlreadyMember        = define("already_member", http.StatusConflict, "User is already a member")
+	InvalidRole          = define("invalid_role", http.StatusBadRequest, "Invalid role")
+	OwnerRequired        = define("owner_required", http.StatusForbidden, "Only owners can add, change or remove owners")
+	LastOwner            = define("last_owner", http.StatusBadRequest, "An organization must keep at least one owner")
+	InvalidSlug          = define("invalid_slug", http.StatusBadRequest, "Invalid slug")
+	SlugExists           = define("slug_exists", http.StatusConflict, "Slug already exists")
+)
+
+// Categorías y atributos
+var (
+	CategoryNotFound           = define("category_not_found", http.StatusNotFound, "Category not found")
+	ParentCategoryNotFound     = define("parent_category_not_found", http.StatusBadRequest, "Parent category not found")
+	CategoryCycle              = define("category_cycle", http.StatusBadRequest, "Invalid parent category")
+	CategoryNotEmpty           = define("category_not_empty", http.StatusConflict, "Category is not empty")
+	AttributeNotFound          = define("attribute_not_found", http.StatusNotFound, "Attribute not found")
+	AttributeExists            = define("attribute_exists", http.StatusConflict, "Attribute already defined")
+	AttributeTypeImmutable     = define("attribute_type_immutable", http.StatusBadRequest, "Attribute type cannot be changed")
+	InvalidAttributeDefinition = define("invalid_attribute_definition", http.StatusBadRequest, "Invalid attribute definition")
+)
+
+// Productos
+var (
+	ProductNotFound  = define("product_not_found", http.StatusNotFound, "Product not found")
+	SKUExists        = define("sku_exists", http.StatusConflict, "A product with this SKU already exists")
+	InvalidCategory  = define("invalid_category", http.StatusBadRequest, "Category not found or invalid")
+	InvalidTags      = define("invalid_tags", http.StatusBadRequest, "Invalid tags")
+	InvalidAttribute = define("invalid_attribute", http.StatusBadRequest, "Invalid product attribute")
+	InvalidProduct   = define("invalid_product", http.StatusUnprocessableEntity, "Invalid product")
+	InvalidFilter    = define("invalid_filter", http.StatusBadRequest, "Invalid filter")
+	InvalidSort      = define("invalid_sort", http.StatusBadRequest, "Invalid sort field")
+	InvalidCursor    = define("invalid_cursor", http.StatusBadRequest, "Invalid cursor")
+	InvalidPatch     = define("invalid_patch", http.StatusBadRequest, "Invalid patch document")
+	PatchTestFailed  = define("patch_test_failed", http.StatusConflict, "A test operation of the patch failed")
+	InvalidImport    = define("invalid_import", http.StatusBadRequest, "Invalid import file")
+	InvalidBulk      = define("invalid_bulk_request", http.StatusBadRequest, "Invalid bulk request")
+	FiltersRequired  = define("filters_required", http.StatusBadRequest, "At least one filter is required")
+)
+
+// Reglas de stock
+var (
+	StockRuleNotFound        = define("stock_rule_not_found", http.StatusNotFound, "Stock rule not found")
+	StockRuleExists          = define("stock_rule_exists", http.StatusConflict, "Stock rule already defined")
+	StockRuleTargetImmutable = define("stock_rule_target_immutable", http.StatusBadRequest, "Stock rule scope cannot be changed")
+	InvalidStockRule         = define("invalid_stock_rule", http.StatusBadRequest, "Invalid stock rule")
+)
+
+// Catalogue retorna todos los tipos de problema de la API
+func Catalogue() []Type {
+	return append([]Type(nil), catalogue...)
+}
+
+// Lookup busca un tipo del catálogo por su código
+func Lookup(code string) (Type, bool) {
+	for _, t := range catalogue {
+		if t.Code == code {
+			return t, true
+		}
+	}
+	return Type{}, false
+}
+
+// ForStatus retorna el tipo genérico de un estado HTTP, para los errores que produce Echo
+// (rutas inexistentes, límite de peticiones, etc.). Los estados sin tipo propio usan el texto del estado
+func ForStatus(status int) Type {
+	for _, t := range []Type{BadRequest, Unauthorized, Forbidden, NotFound, MethodNotAllowed, PayloadTooLarge, UnsupportedMediaType, TooManyRequests, Internal} {
+		if t.Status == status {
+			return t
+		}
+	}
+	title := http.StatusText(status)
+	if title == "" {
+		return Internal
+	}
+	return Type{Code: strings.ReplaceAll(strings.ToLower(title), " ", "_"), Status: status, Title: title}
+}
@@ -0,0 +1,90 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"inventory-api/internal/i18n"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ContentType es el tipo de contenido de las respuestas de error (RFC 7807)
+const ContentType = "application/problem+json"
+
+// TypeBase es el prefijo de la URI de los tipos de problema; GET /problems/:code describe cada uno
+const TypeBase = "/problems/"
+
+// Problem es una respuesta de error con el formato problem details (RFC 7807)
+// Implementa error: los handlers y middleware la retornan y el manejador de errores de Echo la escribe
+type Problem struct {
+	Type       string                 // URI del tipo de problema
+	Title      string                 // Resumen del tipo, igual en todas sus apariciones
+	Status     int                    // Código de estado HTTP
+	Detail     string                 // Explicación de esta aparición concreta
+	Instance   string                 // Ruta de la petición que lo produjo
+	Code       string                 // Código estable para que los clientes reconozcan el error
+	Extensions map[string]interface{} // Miembros adicionales, p. ej. los errores por campo
+
+	detailKey  string        // Código del detalle en el catálogo de mensajes, si se puede traducir
+	detailArgs []interface{} // Argumentos del mensaje del detalle
+}
+
+// Error implementa la interfaz error
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Code + ": " + p.Detail
+	}
+	return p.Code + ": " + p.Title
+}
+
+// With añade un miembro de extensión al problema y lo retorna para encadenar llamadas
+func (p *Problem) With(key string, value interface{}) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]interface{})
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+// Localize traduce el título y, si tiene código de mensaje, el detalle al idioma indicado
+// Lo que no tiene traducción se mantiene en inglés
+func (p *Problem) Localize(lang i18n.Language) {
+	if title, ok := i18n.Lookup(lang, "problem."+p.Code); ok {
+		p.Title = title
+	}
+	if p.detailKey != "" {
+		p.Detail = i18n.T(lang, p.detailKey, p.detailArgs...)
+	}
+}
+
+// MarshalJSON escribe los miembros estándar junto a las extensiones, al mismo nivel
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+6)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.Status
+	members["code"] = p.Code
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	return json.Marshal(members)
+}
+
+// Write responde con el problema como application/problem+json
+func Write(c echo.Context, p *Problem) error {
+	if p.Instance == "" {
+		p.Instance = c.Request().URL.Path
+	}
+	c.Response().Header().Set(echo.HeaderContentType, ContentType)
+	if c.Request().Method == http.MethodHead {
+		return c.NoContent(p.Status)
+	}
+	return c.JSON(p.Status, p)
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"inventory-api/internal/controllers"
 	"inventory-api/internal/middleware"
+	"inventory-api/internal/models"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
@@ -12,36 +13,137 @@ import (
 func SetupRoutes(e *echo.Echo, db *gorm.DB) {
 	// Inicializar controladores
 	authController := controllers.NewAuthController(db)
+	apiKeyController := controllers.NewAPIKeyController(db)
+	mfaController := controllers.NewMFAController(db)
+	adminController := controllers.NewAdminController(db)
+	auditController := controllers.NewAuditController(db)
+	oidcController := controllers.NewOIDCController(db)
+	orgController := controllers.NewOrganizationController(db)
 	productController := controllers.NewProductController(db)
+	categoryController := controllers.NewCategoryController(db)
+	stockRuleController := controllers.NewStockRuleController(db)
+
+	// Permisos requeridos por las rutas protegidas
+	canRead := middleware.RequirePermission(models.PermissionProductsRead)
+	canWrite := middleware.RequirePermission(models.PermissionProductsWrite)
+	canDelete := middleware.RequirePermission(models.PermissionProductsDelete)
+	canManageUsers := middleware.RequirePermission(models.PermissionUsersManage)
+	canManageOrg := middleware.RequirePermission(models.PermissionOrgManage)
+	requireOrg := middleware.RequireOrganization()
+	requireMFA := middleware.RequireMFA()
+
+	// Claves públicas para verificar los JWT emitidos por esta API
+	e.GET("/.well-known/jwks.json", authController.JWKS)
+
+	// Catálogo de tipos de error: la URI del miembro type de las respuestas problem+json
+	e.GET("/problems", controllers.ListProblemTypes)     // GET /problems
+	e.GET("/problems/:code", controllers.GetProblemType) // GET /problems/:code
 
 	// Grupo de rutas de autenticación (públicas)
 	authGroup := e.Group("/auth")
 	{
 		authGroup.POST("/register", authController.Register)
 		authGroup.POST("/login", authController.Login)
+		authGroup.POST("/login/mfa", authController.LoginMFA)
+		authGroup.POST("/verify-email/confirm", authController.ConfirmEmailVerification)
+		authGroup.POST("/password/forgot", authController.ForgotPassword)
+		authGroup.POST("/password/reset", authController.ResetPassword)
+		authGroup.GET("/password/policy", authController.PasswordPolicy)
+		authGroup.GET("/oidc/login", oidcController.Login)
+		authGroup.GET("/oidc/callback", oidcController.Callback)
 
 		// Rutas protegidas de auth
 		authProtected := authGroup.Group("", middleware.RequireAuth(db))
 		authProtected.GET("/profile", authController.Profile)
 		authProtected.POST("/refresh", authController.RefreshToken)
+		authProtected.POST("/verify-email/request", authController.RequestEmailVerification)
+		authProtected.POST("/switch-organization", authController.SwitchOrganization, middleware.RequireUserSession())
+		authProtected.PUT("/profile/language", authController.UpdateLanguage, middleware.RequireUserSession())
+
+		// Gestión de API keys (solo con sesión de usuario, no con otra API key)
+		apiKeys := authProtected.Group("/api-keys", middleware.RequireUserSession())
+		apiKeys.GET("", apiKeyController.ListAPIKeys)
+		apiKeys.POST("", apiKeyController.CreateAPIKey, requireMFA)
+		apiKeys.DELETE("/:id", apiKeyController.RevokeAPIKey)
+
+		// Segundo factor (TOTP)
+		mfa := authProtected.Group("/mfa", middleware.RequireUserSession())
+		mfa.POST("/setup", mfaController.Setup)
+		mfa.POST("/enable", mfaController.Enable)
+		mfa.POST("/disable", mfaController.Disable)
+		mfa.POST("/recovery-codes", mfaController.RegenerateRecoveryCodes)
 	}
 
-	// Grupo de rutas de productos
-	productsGroup := e.Group("/products")
+	// Administración de usuarios
+	adminGroup := e.Group("/admin", middleware.RequireAuth(db), canManageUsers, requireMFA)
+	adminGroup.POST("/users/:id/unlock", adminController.UnlockUser)
+
+	// Log de auditoría (solo administradores)
+	e.GET("/audit", auditController.ListAuditLogs, middleware.RequireAuth(db), canManageUsers, requireMFA)
+
+	// Organizaciones (tenants) y sus miembros
+	orgsGroup := e.Group("/orgs", middleware.RequireAuth(db), middleware.RequireUserSession())
 	{
-		// Rutas públicas de productos
-		productsGroup.GET("", productController.GetAllProducts)                // GET /products
-		productsGroup.GET("/:id", productController.GetProductByID)            // GET /products/:id
-		productsGroup.GET("/low-stock", productController.GetLowStockProducts) // GET /products/low-stock
-		productsGroup.GET("/stats", productController.GetInventoryStats)       // GET /products/stats
+		orgsGroup.GET("", orgController.ListOrganizations)   // GET /orgs
+		orgsGroup.POST("", orgController.CreateOrganization) // POST /orgs
+
+		members := orgsGroup.Group("/current/members", requireOrg)
+		members.GET("", orgController.ListMembers)                            // GET /orgs/current/members
+		members.POST("", orgController.AddMember, canManageOrg)               // POST /orgs/current/members
+		members.PUT("/:user_id", orgController.UpdateMember, canManageOrg)    // PUT /orgs/current/members/:user_id
+		members.DELETE("/:user_id", orgController.RemoveMember, canManageOrg) // DELETE /orgs/current/members/:user_id
+	}
+
+	// Árbol de categorías de productos de la organización activa
+	categoriesGroup := e.Group("/categories", middleware.RequireAuth(db))
+	{
+		categoriesGroup.GET("", categoryController.ListCategories, canRead)                           // GET /categories
+		categoriesGroup.GET("/:id", categoryController.GetCategory, canRead)                          // GET /categories/:id
+		categoriesGroup.POST("", categoryController.CreateCategory, canWrite)                         // POST /categories
+		categoriesGroup.PUT("/:id", categoryController.UpdateCategory, canWrite)                      // PUT /categories/:id
+		categoriesGroup.DELETE("/:id", categoryController.DeleteCategory, canDelete)                  // DELETE /categories/:id
+		categoriesGroup.GET("/:id/attributes", categoryController.ListAttributes, canRead)            // GET /categories/:id/attributes
+		categoriesGroup.POST("/:id/attributes", categoryController.CreateAttribute, canWrite)         // POST /categories/:id/attributes
+		categoriesGroup.PUT("/:id/attributes/:key", categoryController.UpdateAttribute, canWrite)     // PUT /categories/:id/attributes/:key
+		categoriesGroup.DELETE("/:id/attributes/:key", categoryController.DeleteAttribute, canDelete) // DELETE /categories/:id/attributes/:key
+	}
+
+	// Reglas del estado de stock y de la severidad de las alertas de la organización activa
+	stockRulesGroup := e.Group("/stock-rules", middleware.RequireAuth(db))
+	{
+		stockRulesGroup.GET("", stockRuleController.ListStockRules, canRead)              // GET /stock-rules
+		stockRulesGroup.POST("", stockRuleController.CreateStockRule, canManageOrg)       // POST /stock-rules
+		stockRulesGroup.PUT("/:id", stockRuleController.UpdateStockRule, canManageOrg)    // PUT /stock-rules/:id
+		stockRulesGroup.DELETE("/:id", stockRuleController.DeleteStockRule, canManageOrg) // DELETE /stock-rules/:id
+	}
+
+	// Etiquetas de productos de la organización activa
+	e.GET("/tags", productController.ListTags, middleware.RequireAuth(db), canRead) // GET /tags
 
+	// Grupo de rutas de productos (siempre limitadas a la organización activa)
+	productsGroup := e.Group("/products")
+	{
 		// Rutas protegidas de productos (requieren autenticación)
 		protectedProducts := productsGroup.Group("", middleware.RequireAuth(db))
-		protectedProducts.POST("", productController.CreateProduct)        // POST /products
-		protectedProducts.PUT("/:id", productController.UpdateProduct)     // PUT /products/:id
-		protectedProducts.DELETE("/:id", productController.DeleteProduct)  // DELETE /products/:id
-		protectedProducts.PUT("/:id/stock", productController.UpdateStock) // PUT /products/:id/stock
-		protectedProducts.GET("/alerts", productController.GenerateAlerts) // GET /products/alerts
+		protectedProducts.GET("", productController.GetAllProducts, canRead)                     // GET /products
+		protectedProducts.GET("/:id", productController.GetProductByID, canRead)                 // GET /products/:id
+		protectedProducts.GET("/:id/history", productController.GetProductHistory, canRead)      // GET /products/:id/history
+		protectedProducts.GET("/low-stock", productController.GetLowStockProducts, canRead)      // GET /products/low-stock
+		protectedProducts.GET("/stats", productController.GetInventoryStats, canRead)            // GET /products/stats
+		protectedProducts.GET("/trash", productController.ListTrash, canRead)                    // GET /products/trash
+		protectedProducts.GET("/search", productController.SearchProducts, canRead)              // GET /products/search
+		protectedProducts.GET("/suggest", productController.SuggestProducts, canRead)            // GET /products/suggest
+		protectedProducts.GET("/export", productController.ExportProducts, canRead)              // GET /products/export
+		protectedProducts.POST("/:id/restore", productController.RestoreProduct, canDelete)      // POST /products/:id/restore
+		protectedProducts.POST("", productController.CreateProduct, canWrite)                    // POST /products
+		protectedProducts.POST("/import", productController.ImportProducts, canWrite)            // POST /products/import
+		protectedProducts.POST("/bulk", productController.BulkProducts, canWrite)                // POST /products/bulk
+		protectedProducts.POST("/bulk/update", productController.MassUpdateProducts, canWrite)   // POST /products/bulk/update
+		protectedProducts.PUT("/:id", productController.UpdateProduct, canWrite)                 // PUT /products/:id
+		protectedProducts.PATCH("/:id", productController.PatchProduct, canWrite)                // PATCH /products/:id
+		protectedProducts.DELETE("/:id", productController.DeleteProduct, canDelete, requireMFA) // DELETE /products/:id
+		protectedProducts.PUT("/:id/stock", productController.UpdateStock, canWrite)             // PUT /products/:id/stock
+		protectedProducts.GET("/alerts", productController.GenerateAlerts, canRead)              // GET /products/alerts
 	}
 
 	// Rutas adicionales de API
@@ -52,28 +154,99 @@ func SetupRoutes(e *echo.Echo, db *gorm.DB) {
 		{
 			apiAuthGroup.POST("/register", authController.Register)
 			apiAuthGroup.POST("/login", authController.Login)
+			apiAuthGroup.POST("/login/mfa", authController.LoginMFA)
+			apiAuthGroup.POST("/verify-email/confirm", authController.ConfirmEmailVerification)
+			apiAuthGroup.POST("/password/forgot", authController.ForgotPassword)
+			apiAuthGroup.POST("/password/reset", authController.ResetPassword)
+			apiAuthGroup.GET("/password/policy", authController.PasswordPolicy)
+			apiAuthGroup.GET("/oidc/login", oidcController.Login)
+			apiAuthGroup.GET("/oidc/callback", oidcController.Callback)
 
 			apiAuthProtected := apiAuthGroup.Group("", middleware.RequireAuth(db))
 			apiAuthProtected.GET("/profile", authController.Profile)
 			apiAuthProtected.POST("/refresh", authController.RefreshToken)
+			apiAuthProtected.POST("/verify-email/request", authController.RequestEmailVerification)
+			apiAuthProtected.POST("/switch-organization", authController.SwitchOrganization, middleware.RequireUserSession())
+			apiAuthProtected.PUT("/profile/language", authController.UpdateLanguage, middleware.RequireUserSession())
+
+			apiKeys := apiAuthProtected.Group("/api-keys", middleware.RequireUserSession())
+			apiKeys.GET("", apiKeyController.ListAPIKeys)
+			apiKeys.POST("", apiKeyController.CreateAPIKey, requireMFA)
+			apiKeys.DELETE("/:id", apiKeyController.RevokeAPIKey)
+
+			mfa := apiAuthProtected.Group("/mfa", middleware.RequireUserSession())
+			mfa.POST("/setup", mfaController.Setup)
+			mfa.POST("/enable", mfaController.Enable)
+			mfa.POST("/disable", mfaController.Disable)
+			mfa.POST("/recovery-codes", mfaController.RegenerateRecoveryCodes)
+		}
+
+		// Administración con versionado
+		apiAdminGroup := apiGroup.Group("/admin", middleware.RequireAuth(db), canManageUsers, requireMFA)
+		apiAdminGroup.POST("/users/:id/unlock", adminController.UnlockUser)
+		apiGroup.GET("/audit", auditController.ListAuditLogs, middleware.RequireAuth(db), canManageUsers, requireMFA)
+
+		// Organizaciones con versionado
+		apiOrgsGroup := apiGroup.Group("/orgs", middleware.RequireAuth(db), middleware.RequireUserSession())
+		{
+			apiOrgsGroup.GET("", orgController.ListOrganizations)
+			apiOrgsGroup.POST("", orgController.CreateOrganization)
+
+			members := apiOrgsGroup.Group("/current/members", requireOrg)
+			members.GET("", orgController.ListMembers)
+			members.POST("", orgController.AddMember, canManageOrg)
+			members.PUT("/:user_id", orgController.UpdateMember, canManageOrg)
+			members.DELETE("/:user_id", orgController.RemoveMember, canManageOrg)
 		}
 
+		// Categorías con versionado
+		apiCategoriesGroup := apiGroup.Group("/categories", middleware.RequireAuth(db))
+		{
+			apiCategoriesGroup.GET("", categoryController.ListCategories, canRead)
+			apiCategoriesGroup.GET("/:id", categoryController.GetCategory, canRead)
+			apiCategoriesGroup.POST("", categoryController.CreateCategory, canWrite)
+			apiCategoriesGroup.PUT("/:id", categoryController.UpdateCategory, canWrite)
+			apiCategoriesGroup.DELETE("/:id", categoryController.DeleteCategory, canDelete)
+			apiCategoriesGroup.GET("/:id/attributes", categoryController.ListAttributes, canRead)
+			apiCategoriesGroup.POST("/:id/attributes", categoryController.CreateAttribute, canWrite)
+			apiCategoriesGroup.PUT("/:id/attributes/:key", categoryController.UpdateAttribute, canWrite)
+			apiCategoriesGroup.DELETE("/:id/attributes/:key", categoryController.DeleteAttribute, canDelete)
+		}
+
+		// Reglas de stock con versionado
+		apiStockRulesGroup := apiGroup.Group("/stock-rules", middleware.RequireAuth(db))
+		{
+			apiStockRulesGroup.GET("", stockRuleController.ListStockRules, canRead)
+			apiStockRulesGroup.POST("", stockRuleController.CreateStockRule, canManageOrg)
+			apiStockRulesGroup.PUT("/:id", stockRuleController.UpdateStockRule, canManageOrg)
+			apiStockRulesGroup.DELETE("/:id", stockRuleController.DeleteStockRule, canManageOrg)
+		}
+
+		apiGroup.GET("/tags", productController.ListTags, middleware.RequireAuth(db), canRead)
+
 		// Rutas de productos con versionado
 		apiProductsGroup := apiGroup.Group("/products")
 		{
-			// Públicas
-			apiProductsGroup.GET("", productController.GetAllProducts)
-			apiProductsGroup.GET("/:id", productController.GetProductByID)
-			apiProductsGroup.GET("/low-stock", productController.GetLowStockProducts)
-			apiProductsGroup.GET("/stats", productController.GetInventoryStats)
-
-			// Protegidas
 			apiProtectedProducts := apiProductsGroup.Group("", middleware.RequireAuth(db))
-			apiProtectedProducts.POST("", productController.CreateProduct)
-			apiProtectedProducts.PUT("/:id", productController.UpdateProduct)
-			apiProtectedProducts.DELETE("/:id", productController.DeleteProduct)
-			apiProtectedProducts.PUT("/:id/stock", productController.UpdateStock)
-			apiProtectedProducts.GET("/alerts", productController.GenerateAlerts)
+			apiProtectedProducts.GET("", productController.GetAllProducts, canRead)
+			apiProtectedProducts.GET("/:id", productController.GetProductByID, canRead)
+			apiProtectedProducts.GET("/:id/history", productController.GetProductHistory, canRead)
+			apiProtectedProducts.GET("/low-stock", productController.GetLowStockProducts, canRead)
+			apiProtectedProducts.GET("/stats", productController.GetInventoryStats, canRead)
+			apiProtectedProducts.GET("/trash", productController.ListTrash, canRead)
+			apiProtectedProducts.GET("/search", productController.SearchProducts, canRead)
+			apiProtectedProducts.GET("/suggest", productController.SuggestProducts, canRead)
+			apiProtectedProducts.GET("/export", productController.ExportProducts, canRead)
+			apiProtectedProducts.POST("/:id/restore", productController.RestoreProduct, canDelete)
+			apiProtectedProducts.POST("", productController.CreateProduct, canWrite)
+			apiProtectedProducts.POST("/import", productController.ImportProducts, canWrite)
+			apiProtectedProducts.POST("/bulk", productController.BulkProducts, canWrite)
+			apiProtectedProducts.POST("/bulk/update", productController.MassUpdateProducts, canWrite)
+			apiProtectedProducts.PUT("/:id", productController.UpdateProduct, canWrite)
+			apiProtectedProducts.PATCH("/:id", productController.PatchProduct, canWrite)
+			apiProtectedProducts.DELETE("/:id", productController.DeleteProduct, canDelete, requireMFA)
+			apiProtectedProducts.PUT("/:id/stock", productController.UpdateStock, canWrite)
+			apiProtectedProducts.GET("/alerts", productController.GenerateAlerts, canRead)
 		}
 	}
 }
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"inventory-api/internal/mailer"
+	"inventory-api/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// Validez de los tokens enviados por correo
+const (
+	emailVerificationTTL = 48 * time.Hour
+	passwordResetTTL     = time.Hour
+)
+
+// AccountService maneja la verificación de email y la recuperación de contraseña
+type AccountService struct {
+	db      *gorm.DB
+	mailer  mailer.Mailer
+	baseURL string
+}
+
+// NewAccountService crea una nueva instancia del servicio de cuentas
+func NewAccountService(db *gorm.DB) *AccountService {
+	baseURL := strings.TrimRight(os.Getenv("APP_BASE_URL"), "/")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+
+	return &AccountService{
+		db:      db,
+		mailer:  mailer.Default(),
+		baseURL: baseURL,
+	}
+}
+
+// SendVerificationEmail envía el enlace de verificación al usuario
+func (acs *AccountService) SendVerificationEmail(user *models.User) error {
+	token, err := acs.issueToken(user, models.TokenPurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := acs.baseURL + "/verify-email?token=" + url.QueryEscape(token)
+	return acs.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: "Welcome to Inventory API!\n\n" +
+			"Confirm your email address by opening the following link:\n\n" +
+			link + "\n\n" +
+			"The link expires in 48 hours. If you did not create an account, ignore this email.\n",
+	})
+}
+
+// RequestEmailVerification reenvía el correo de verificación
+func (acs *AccountService) RequestEmailVerification(userID uint) error {
+	var user models.User
+	if err := acs.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	if err := acs.SendVerificationEmail(&user); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+	return nil
+}
+
+// ConfirmEmailVerification marca el email como verificado si el token es válido
+func (acs *AccountService) ConfirmEmailVerification(token string, client models.ClientInfo) (*models.UserResponse, error) {
+	var user models.User
+	err := acs.db.Transaction(func(tx *gorm.DB) error {
+		u, err := acs.consumeToken(tx, token, models.TokenPurposeEmailVerification)
+		if err != nil {
+			return err
+		}
+
+		before := u.AuditFields()
+		now := time.Now()
+		if err := tx.Model(u).Updates(map[string]interface{}{
+			"email_verified":    true,
+			"email_verified_at": now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to verify email: %w", err)
+		}
+
+		user = *u
+		user.EmailVerified = true
+		user.EmailVerifiedAt = &now
+		return recordAudit(tx, client.Audit().WithActor(&user), models.AuditActionUpdate,
+			models.AuditEntityUser, user.ID, 0, before, user.AuditFields())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := user.ToResponse()
+	return &response, nil
+}
+
+// RequestPasswordReset encola una solicitud de recuperación de contraseña
+// No revela si la cuenta existe para evitar la enumeración de usuarios: la búsqueda de la cuenta,
+// el token y el correo se hacen en el worker de recuperación, fuera de la petición
+func (acs *AccountService) RequestPasswordReset(email string) error {
+	return passwordResets.enqueue(email)
+}
+
+// processPasswordReset envía el enlace de recuperación si el email pertenece a una cuenta
+func (acs *AccountService) processPasswordReset(email string) error {
+	var user models.User
+	if err := acs.db.Where("email = ?", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+	if err := acs.sendPasswordResetEmail(&user); err != nil {
+		return fmt.Errorf("user %d: %w", user.ID, err)
+	}
+	return nil
+}
+
+// sendPasswordResetEmail emite un token de recuperación y envía el enlace por correo
+func (acs *AccountService) sendPasswordResetEmail(user *models.User) error {
+	token, err := acs.issueToken(user, models.TokenPurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := acs.baseURL + "/reset-password?token=" + url.QueryEscape(token)
+	if err := acs.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: "We received a request to reset the password of your Inventory API account.\n\n" +
+			"Choose a new password by opening the following link:\n\n" +
+			link + "\n\n" +
+			"The link expires in 1 hour and can only be used once. If you did not request it, ignore this email.\n",
+	}); err != nil {
+		return fmt.Errorf("failed to send password reset email: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword cambia la contraseña usando un token de recuperación
+// Si la contraseña no cumple la política el token no se consume
+func (acs *AccountService) ResetPassword(token, newPassword string, client models.ClientInfo) error {
+	return acs.db.Transaction(func(tx *gorm.DB) error {
+		user, err := acs.consumeToken(tx, token, models.TokenPurposePasswordReset)
+		if err != nil {
+			return err
+		}
+
+		if err := LoadPasswordPolicy().Validate(newPassword, user.Email); err != nil {
+			return err
+		}
+
+		hashedPassword, err := models.HashPassword(newPassword)
+		if err != nil {
+			return fmt.Errorf("failed to hash password: %w", err)
+		}
+
+		// Recibir el correo demuestra también la propiedad de la dirección
+		updates := map[string]interface{}{"password": hashedPassword}
+		if !user.EmailVerified {
+			updates["email_verified"] = true
+			updates["email_verified_at"] = time.Now()
+		}
+
+		before := user.AuditFields()
+		if err := tx.Model(user).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+
+		after := *user
+		after.Password = hashedPassword
+		after.EmailVerified = true
+		return recordAudit(tx, client.Audit().WithActor(user), models.AuditActionUpdate,
+			models.AuditEntityUser, user.ID, 0, before, after.AuditFields())
+	})
+}
+
+// issueToken firma un token de un solo uso y lo registra en la base de datos
+// Los tokens anteriores del mismo propósito quedan invalidados
+func (acs *AccountService) issueToken(user *models.User, purpose string, ttl time.Duration) (string, error) {
+	keys, err := LoadJWTKeys()
+	if err != nil {
+		return "", err
+	}
+
+	jtiBytes := make([]byte, 16)
+	if _, err := rand.Read(jtiBytes); err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+	jti := hex.EncodeToString(jtiBytes)
+
+	now := time.Now()
+	record := models.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		JTI:       jti,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	err = acs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", user.ID, purpose).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&record).Error
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store token: %w", err)
+	}
+
+	claims := JWTClaims{
+		UserID:  user.ID,
+		Email:   user.Email,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(record.ExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "inventory-api",
+			Subject:   fmt.Sprintf("%d", user.ID),
+		},
+	}
+
+	token, err := keys.Sign(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return token, nil
+}
+
+// consumeToken valida la firma, el propósito y la vigencia del token y lo marca como usado
+func (acs *AccountService) consumeToken(tx *gorm.DB, token, purpose string) (*models.User, error) {
+	claims, err := NewAuthService(acs.db).validateToken(token, purpose)
+	if err != nil || claims.ID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	// La condición used_at IS NULL garantiza un único uso aun con peticiones concurrentes
+	now := time.Now()
+	result := tx.Model(&models.UserToken{}).
+		Where("jti = ? AND user_id = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", claims.ID, claims.UserID, purpose, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected != 1 {
+		return nil, ErrInvalidToken
+	}
+
+	var user models.User
+	if err := tx.First(&user, claims.UserID).Error; err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return &user, nil
+}
+
+// sendVerificationEmailAsync envía el correo de verificación sin bloquear la petición
+func (acs *AccountService) sendVerificationEmailAsync(user models.User) {
+	go func() {
+		if err := acs.SendVerificationEmail(&user); err != nil {
+			log.Printf("Warning: failed to send verification email to %s: %v", user.Email, err)
+		}
+	}()
+}
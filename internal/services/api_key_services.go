@@ -0,0 +1,219 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"inventory-api/internal/i18n"
+	"inventory-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// apiKeyScheme es el prefijo fijo de todas las API keys ("inv_<id>_<secreto>")
+const apiKeyScheme = "inv_"
+
+// apiKeyTouchInterval limita la frecuencia con la que se actualiza last_used_at
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyService maneja la lógica de las API keys
+type APIKeyService struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyService crea una nueva instancia del servicio de API keys
+func NewAPIKeyService(db *gorm.DB) *APIKeyService {
+	return &APIKeyService{db: db}
+}
+
+// CreateAPIKey genera una nueva API key para el usuario, limitada a su organización activa
+func (aks *APIKeyService) CreateAPIKey(userID, orgID uint, req models.APIKeyRequest) (*models.APIKeyCreatedResponse, error) {
+	var user models.User
+	if err := aks.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	// Una API key nunca puede tener más permisos que su propietario en la organización
+	userPermissions, err := aks.OwnerPermissions(&user, orgID)
+	if err != nil {
+		return nil, err
+	}
+	permissions := make([]string, 0, len(req.Permissions))
+	for _, p := range req.Permissions {
+		if !models.IsValidPermission(p) {
+			return nil, &APIKeyPermissionError{Permission: p, Reason: i18n.Msg("error.invalid_permission", p)}
+		}
+		if !models.HasPermission(userPermissions, p) {
+			return nil, &APIKeyPermissionError{Permission: p, Reason: i18n.Msg("error.permission_not_allowed", p)}
+		}
+		if !models.HasPermission(permissions, p) {
+			permissions = append(permissions, p)
+		}
+	}
+
+	key, prefix, err := generateAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	apiKey := models.APIKey{
+		UserID:         userID,
+		OrganizationID: orgID,
+		Name:           req.Name,
+		Prefix:         prefix,
+		KeyHash:        hashAPIKey(key),
+		Permissions:    permissions,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	if err := aks.db.Create(&apiKey).Error; err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return &models.APIKeyCreatedResponse{
+		APIKeyResponse: apiKey.ToResponse(),
+		Key:            key,
+	}, nil
+}
+
+// OwnerPermissions retorna los permisos efectivos del usuario en la organización
+// Sin membresía solo conserva los permisos de plataforma de su rol global
+func (aks *APIKeyService) OwnerPermissions(user *models.User, orgID uint) ([]string, error) {
+	if orgID == 0 {
+		return user.Permissions(), nil
+	}
+
+	membership, err := NewOrganizationService(aks.db).GetMembership(orgID, user.ID)
+	if err != nil {
+		if errors.Is(err, ErrMembershipNotFound) {
+			return user.Permissions(), nil
+		}
+		return nil, err
+	}
+
+	return models.EffectivePermissions(user.Role, membership.Role), nil
+}
+
+// ListAPIKeys obtiene las API keys del usuario
+func (aks *APIKeyService) ListAPIKeys(userID uint) ([]models.APIKeyResponse, error) {
+	var keys []models.APIKey
+	if err := aks.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch api keys: %w", err)
+	}
+
+	responses := make([]models.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, key.ToResponse())
+	}
+
+	return responses, nil
+}
+
+// RevokeAPIKey revoca una API key del usuario
+func (aks *APIKeyService) RevokeAPIKey(userID, keyID uint) error {
+	var key models.APIKey
+	if err := aks.db.Where("id = ? AND user_id = ?", keyID, userID).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return fmt.Errorf("failed to fetch api key: %w", err)
+	}
+
+	if key.RevokedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	if err := aks.db.Model(&key).Update("revoked_at", now).Error; err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	return nil
+}
+
+// AuthenticateAPIKey valida una API key y retorna la clave y su propietario
+func (aks *APIKeyService) AuthenticateAPIKey(rawKey string) (*models.APIKey, *models.User, error) {
+	prefix, ok := apiKeyPrefix(rawKey)
+	if !ok {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	var key models.APIKey
+	if err := aks.db.Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidAPIKey
+		}
+		return nil, nil, fmt.Errorf("database error: %w", err)
+	}
+
+	// Comparación en tiempo constante del hash
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashAPIKey(rawKey))) != 1 {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if !key.IsActive(now) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	var user models.User
+	if err := aks.db.First(&user, key.UserID).Error; err != nil {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	// Registrar el último uso sin escribir en cada petición
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		if err := aks.db.Model(&key).UpdateColumn("last_used_at", now).Error; err == nil {
+			key.LastUsedAt = &now
+		}
+	}
+
+	return &key, &user, nil
+}
+
+// generateAPIKey genera una clave aleatoria y su prefijo visible
+func generateAPIKey() (key, prefix string, err error) {
+	id := make([]byte, 6)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	prefix = apiKeyScheme + hex.EncodeToString(id)
+	key = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, prefix, nil
+}
+
+// apiKeyPrefix extrae el prefijo visible de una clave completa
+func apiKeyPrefix(rawKey string) (string, bool) {
+	if !strings.HasPrefix(rawKey, apiKeyScheme) {
+		return "", false
+	}
+	i := strings.Index(rawKey[len(apiKeyScheme):], "_")
+	if i <= 0 {
+		return "", false
+	}
+	return rawKey[:len(apiKeyScheme)+i], true
+}
+
+// hashAPIKey calcula el hash almacenado de una clave
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
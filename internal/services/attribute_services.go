@@ -0,0 +1,292 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"inventory-api/internal/i18n"
+	"inventory-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Longitud máxima de los atributos de tipo string
+const maxAttributeStringLength = 500
+
+// attributeKeyPattern define las claves de atributo válidas (snake_case)
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// categoryAncestorsSQL selecciona la categoría indicada y todas sus antecesoras
+const categoryAncestorsSQL = `WITH RECURSIVE ancestors AS (
+	SELECT id, parent_id FROM categories WHERE id = ?
+	UNION
+	SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+) SELECT id FROM ancestors`
+
+// ProductAttributeError indica un atributo de producto que no cumple su definición
+type ProductAttributeError struct {
+	Attribute string
+	Reason    i18n.Message
+}
+
+// Error implementa la interfaz error
+func (e *ProductAttributeError) Error() string {
+	return fmt.Sprintf("invalid attribute %s: %s", e.Attribute, e.Reason)
+}
+
+// ListAttributes obtiene las definiciones de atributos que aplican a los productos de la
+// categoría: las suyas y las heredadas de sus antecesoras
+func (cs *CategoryService) ListAttributes(categoryID uint) ([]models.AttributeDefinition, error) {
+	category, err := findCategory(cs.db, cs.orgID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	return categoryAttributeDefinitions(cs.db, cs.orgID, category.ID)
+}
+
+// CreateAttribute define un atributo nuevo para la categoría y sus subcategorías
+func (cs *CategoryService) CreateAttribute(categoryID uint, req models.AttributeDefinitionRequest) (*models.AttributeDefinition, error) {
+	category, err := findCategory(cs.db, cs.orgID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	definition := models.AttributeDefinition{
+		OrganizationID: cs.orgID,
+		CategoryID:     category.ID,
+		Key:            strings.TrimSpace(req.Key),
+		Label:          strings.TrimSpace(req.Label),
+		Type:           req.Type,
+		Options:        req.Options,
+		Required:       req.Required,
+	}
+	if err := normalizeAttributeDefinition(&definition); err != nil {
+		return nil, err
+	}
+
+	// La clave no puede repetirse en la rama: ni en antecesoras ni en subcategorías
+	var count int64
+	err = cs.db.Model(&models.AttributeDefinition{}).
+		Where("organization_id = ? AND key = ?", cs.orgID, definition.Key).
+		Where("(category_id IN ("+categoryAncestorsSQL+") OR category_id IN ("+fmt.Sprintf(categorySubtreeSQL, "id = ?")+"))",
+			category.ID, category.ID).
+		Count(&count).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if count > 0 {
+		return nil, ErrAttributeExists
+	}
+
+	err = cs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&definition).Error; err != nil {
+			return fmt.Errorf("failed to create attribute: %w", err)
+		}
+		return recordAudit(tx, cs.audit, models.AuditActionCreate, models.AuditEntityAttribute,
+			definition.ID, definition.OrganizationID, nil, definition.AuditFields())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+// UpdateAttribute cambia la etiqueta, las opciones o la obligatoriedad de un atributo
+// El tipo no se puede cambiar; los valores existentes se revalidan al editar cada producto
+func (cs *CategoryService) UpdateAttribute(categoryID uint, key string, req models.AttributeDefinitionRequest) (*models.AttributeDefinition, error) {
+	definition, err := cs.findAttribute(categoryID, key)
+	if err != nil {
+		return nil, err
+	}
+	if req.Type != "" && req.Type != definition.Type {
+		return nil, ErrAttributeTypeChange
+	}
+	before := definition.AuditFields()
+
+	definition.Label = strings.TrimSpace(req.Label)
+	definition.Options = req.Options
+	definition.Required = req.Required
+	if err := normalizeAttributeDefinition(definition); err != nil {
+		return nil, err
+	}
+
+	err = cs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(definition).Error; err != nil {
+			return fmt.Errorf("failed to update attribute: %w", err)
+		}
+		return recordAudit(tx, cs.audit, models.AuditActionUpdate, models.AuditEntityAttribute,
+			definition.ID, definition.OrganizationID, before, definition.AuditFields())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return definition, nil
+}
+
+// DeleteAttribute elimina la definición y quita su valor de los productos de la categoría
+// y de sus subcategorías (también de los de la papelera)
+func (cs *CategoryService) DeleteAttribute(categoryID uint, key string) error {
+	definition, err := cs.findAttribute(categoryID, key)
+	if err != nil {
+		return err
+	}
+
+	return cs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(definition).Error; err != nil {
+			return fmt.Errorf("failed to delete attribute: %w", err)
+		}
+		err := tx.Exec("UPDATE products SET attributes = attributes - ? WHERE organization_id = ? AND category_id IN ("+
+			fmt.Sprintf(categorySubtreeSQL, "id = ?")+")", definition.Key, cs.orgID, definition.CategoryID).Error
+		if err != nil {
+			return fmt.Errorf("failed to remove attribute from products: %w", err)
+		}
+		return recordAudit(tx, cs.audit, models.AuditActionDelete, models.AuditEntityAttribute,
+			definition.ID, definition.OrganizationID, definition.AuditFields(), nil)
+	})
+}
+
+// findAttribute obtiene una definición de atributo propia de la categoría (no heredada)
+func (cs *CategoryService) findAttribute(categoryID uint, key string) (*models.AttributeDefinition, error) {
+	if _, err := findCategory(cs.db, cs.orgID, categoryID); err != nil {
+		return nil, err
+	}
+
+	var definition models.AttributeDefinition
+	err := cs.db.Where("organization_id = ? AND category_id = ? AND key = ?", cs.orgID, categoryID, key).
+		First(&definition).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttributeNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch attribute: %w", err)
+	}
+	return &definition, nil
+}
+
+// normalizeAttributeDefinition valida la clave, el tipo y las opciones de una definición
+func normalizeAttributeDefinition(definition *models.AttributeDefinition) error {
+	if !attributeKeyPattern.MatchString(definition.Key) {
+		return ErrInvalidAttributeKey
+	}
+	if !models.IsValidAttributeType(definition.Type) {
+		return ErrInvalidAttributeType
+	}
+	if len(definition.Label) > 100 {
+		return ErrInvalidAttributeLabel
+	}
+
+	if definition.Type != models.AttributeTypeEnum {
+		definition.Options = nil
+		return nil
+	}
+
+	options := []string{}
+	for _, option := range definition.Options {
+		option = strings.TrimSpace(option)
+		if option != "" && !containsString(options, option) {
+			options = append(options, option)
+		}
+	}
+	if len(options) == 0 {
+		return ErrEnumOptionsRequired
+	}
+	definition.Options = options
+	return nil
+}
+
+// categoryAttributeDefinitions obtiene las definiciones de la categoría y de sus antecesoras
+func categoryAttributeDefinitions(db *gorm.DB, orgID, categoryID uint) ([]models.AttributeDefinition, error) {
+	definitions := []models.AttributeDefinition{}
+	err := db.Where("organization_id = ? AND category_id IN ("+categoryAncestorsSQL+")", orgID, categoryID).
+		Order("key").
+		Find(&definitions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attribute definitions: %w", err)
+	}
+	return definitions, nil
+}
+
+// validateProductAttributes comprueba los valores de un producto contra las definiciones de
+// su categoría y retorna los valores a guardar. Con dropUnknown se descartan en silencio las
+// claves sin definición (atributos conservados de otra categoría) en lugar de rechazarlas
+func validateProductAttributes(definitions []models.AttributeDefinition, values map[string]interface{}, dropUnknown bool) (models.ProductAttributes, error) {
+	byKey := make(map[string]models.AttributeDefinition, len(definitions))
+	for _, definition := range definitions {
+		byKey[definition.Key] = definition
+	}
+
+	attributes := models.ProductAttributes{}
+	for key, value := range values {
+		definition, ok := byKey[key]
+		if !ok {
+			if dropUnknown {
+				continue
+			}
+			return nil, &ProductAttributeError{Attribute: key, Reason: i18n.Msg("attribute_value.not_defined")}
+		}
+		if value == nil {
+			continue
+		}
+
+		switch definition.Type {
+		case models.AttributeTypeString:
+			text, ok := value.(string)
+			if !ok {
+				return nil, &ProductAttributeError{Attribute: key, Reason: i18n.Msg("attribute_value.string")}
+			}
+			if len(text) > maxAttributeStringLength {
+				return nil, &ProductAttributeError{Attribute: key, Reason: i18n.Msg("attribute_value.too_long", maxAttributeStringLength)}
+			}
+		case models.AttributeTypeNumber:
+			if _, ok := value.(float64); !ok {
+				return nil, &ProductAttributeError{Attribute: key, Reason: i18n.Msg("attribute_value.number")}
+			}
+		case models.AttributeTypeBool:
+			if _, ok := value.(bool); !ok {
+				return nil, &ProductAttributeError{Attribute: key, Reason: i18n.Msg("attribute_value.bool")}
+			}
+		case models.AttributeTypeEnum:
+			option, ok := value.(string)
+			if !ok || !containsString(definition.Options, option) {
+				return nil, &ProductAttributeError{Attribute: key, Reason: i18n.Msg("attribute_value.oneof", strings.Join(definition.Options, ", "))}
+			}
+		}
+		attributes[key] = value
+	}
+
+	for _, definition := range definitions {
+		if _, ok := attributes[definition.Key]; definition.Required && !ok {
+			return nil, &ProductAttributeError{Attribute: definition.Key, Reason: i18n.Msg("attribute_value.required")}
+		}
+	}
+	return attributes, nil
+}
+
+// parseAttributeValue convierte el texto de un atributo (p. ej. una celda de CSV) al tipo de su
+// definición. Las claves sin definición se dejan como texto para que la validación las rechace
+func parseAttributeValue(definitions []models.AttributeDefinition, key, raw string) (interface{}, error) {
+	for _, definition := range definitions {
+		if definition.Key != key {
+			continue
+		}
+		switch definition.Type {
+		case models.AttributeTypeNumber:
+			number, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, &ProductAttributeError{Attribute: key, Reason: i18n.Msg("attribute_value.number")}
+			}
+			return number, nil
+		case models.AttributeTypeBool:
+			boolean, err := strconv.ParseBool(raw)
+			if err != nil {
+				return nil, &ProductAttributeError{Attribute: key, Reason: i18n.Msg("attribute_value.bool")}
+			}
+			return boolean, nil
+		}
+		break
+	}
+	return raw, nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+
+	"inventory-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Límites de paginación del log de auditoría
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// AuditService consulta el log de auditoría
+type AuditService struct {
+	db *gorm.DB
+}
+
+// NewAuditService crea una nueva instancia del servicio de auditoría
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// ListAuditLogs obtiene las entradas que cumplen el filtro, de la más reciente a la más antigua
+// Retorna también el total de entradas sin paginar
+func (aus *AuditService) ListAuditLogs(filter models.AuditFilter) ([]models.AuditLog, int64, error) {
+	query := aus.db.Model(&models.AuditLog{})
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	if limit > maxAuditLimit {
+		limit = maxAuditLimit
+	}
+
+	logs := []models.AuditLog{}
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(filter.Offset).Find(&logs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch audit logs: %w", err)
+	}
+
+	return logs, total, nil
+}
+
+// recordAudit guarda una entrada de auditoría dentro de la transacción del cambio,
+// de modo que no puede haber cambios sin registrar ni registros de cambios revertidos
+// Las actualizaciones que no modifican ningún campo no se registran
+func recordAudit(tx *gorm.DB, audit models.AuditContext, action, entityType string, entityID, orgID uint, before, after map[string]interface{}) error {
+	changes := models.DiffAuditFields(before, after)
+	if action == models.AuditActionUpdate && len(changes) == 0 {
+		return nil
+	}
+
+	entry := models.AuditLog{
+		ActorID:        audit.ActorID,
+		ActorEmail:     audit.ActorEmail,
+		Action:         action,
+		EntityType:     entityType,
+		EntityID:       entityID,
+		OrganizationID: orgID,
+		Changes:        changes,
+		RequestID:      truncate(audit.RequestID, 64),
+		IP:             audit.IP,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return fmt.Errorf("failed to record audit log: %w", err)
+	}
+	return nil
+}
@@ -3,7 +3,8 @@ package services
 import (
 	"errors"
 	"fmt"
-	"os"
+	"log"
+	"sync"
 	"time"
 
 	"inventory-api/internal/models"
@@ -14,27 +15,60 @@ import (
 
 // AuthService maneja la lógica de autenticación
 type AuthService struct {
-	db *gorm.DB
+	db            *gorm.DB
+	protection    *LoginProtectionService
+	organizations *OrganizationService
 }
 
 // NewAuthService crea una nueva instancia del servicio de autenticación
 func NewAuthService(db *gorm.DB) *AuthService {
-	return &AuthService{db: db}
+	return &AuthService{
+		db:            db,
+		protection:    NewLoginProtectionService(db),
+		organizations: NewOrganizationService(db),
+	}
 }
 
+// Propósitos de los tokens que no son de acceso
+const (
+	TokenPurposeMFAChallenge = "mfa_challenge"
+)
+
+// mfaChallengeTTL es la validez del token intermedio del login con MFA
+const mfaChallengeTTL = 5 * time.Minute
+
 // JWTClaims define las claims personalizadas del JWT
 type JWTClaims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
+	UserID  uint   `json:"user_id"`
+	Email   string `json:"email"`
+	Role    string `json:"role"`
+	OrgID   uint   `json:"org_id,omitempty"`  // Organización (tenant) activa de la sesión
+	MFA     bool   `json:"mfa,omitempty"`     // El usuario verificó el segundo factor
+	Lang    string `json:"lang,omitempty"`    // Idioma preferido del usuario para las respuestas
+	Purpose string `json:"purpose,omitempty"` // Vacío en los tokens de acceso
 	jwt.RegisteredClaims
 }
 
+// LoginResult representa el resultado del primer paso del login
+// Si MFARequired es true, Token está vacío y MFAToken debe canjearse con CompleteMFALogin
+type LoginResult struct {
+	Token       string
+	MFARequired bool
+	MFAToken    string
+	User        *models.UserResponse
+}
+
 // RegisterUser registra un nuevo usuario
-func (as *AuthService) RegisterUser(req models.UserRequest) (*models.UserResponse, error) {
+func (as *AuthService) RegisterUser(req models.UserRequest, client models.ClientInfo) (*models.UserResponse, error) {
 	// Verificar si el usuario ya existe
 	var existingUser models.User
 	if err := as.db.Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
-		return nil, errors.New("user already exists")
+		return nil, ErrUserExists
+	}
+
+	// Validar la contraseña contra la política configurada
+	if err := LoadPasswordPolicy().Validate(req.Password, req.Email); err != nil {
+		return nil, err
 	}
 
 	// Crear nuevo usuario
@@ -43,66 +77,188 @@ func (as *AuthService) RegisterUser(req models.UserRequest) (*models.UserRespons
 		Password: req.Password, // Se hasheará automáticamente en el hook BeforeCreate
 	}
 
-	if err := as.db.Create(&user).Error; err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
+	// Cada usuario nuevo recibe su propia organización como propietario
+	err := as.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		if err := recordAudit(tx, client.Audit().WithActor(&user), models.AuditActionCreate,
+			models.AuditEntityUser, user.ID, 0, nil, user.AuditFields()); err != nil {
+			return err
+		}
+		_, err := CreatePersonalOrganization(tx, &user)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	// Enviar el enlace de verificación de email
+	NewAccountService(as.db).sendVerificationEmailAsync(user)
+
 	response := user.ToResponse()
 	return &response, nil
 }
 
 // LoginUser autentica un usuario y genera un JWT
-func (as *AuthService) LoginUser(req models.UserRequest) (string, *models.UserResponse, error) {
+// Si la cuenta tiene MFA activo retorna un token de desafío en lugar del JWT final
+func (as *AuthService) LoginUser(req models.UserRequest, client models.ClientInfo) (*LoginResult, error) {
+	// Rechazar IPs con demasiados intentos fallidos
+	if err := as.protection.CheckIP(client.IP); err != nil {
+		as.protection.LogEvent(nil, req.Email, client, models.AuthEventLoginBlocked, err.Error())
+		return nil, err
+	}
+
 	// Buscar usuario por email
 	var user models.User
 	if err := as.db.Where("email = ?", req.Email).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", nil, errors.New("invalid credentials")
+			// Mismo coste que una contraseña incorrecta para no revelar si el email existe
+			compareDummyPassword(req.Password)
+			as.protection.RecordFailure(nil, req.Email, client, models.AuthEventLoginFailed, "unknown email")
+			return nil, ErrInvalidCredentials
 		}
-		return "", nil, fmt.Errorf("database error: %w", err)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	// Rechazar cuentas bloqueadas o en espera
+	if err := as.protection.CheckUser(&user); err != nil {
+		as.protection.LogEvent(&user, user.Email, client, models.AuthEventLoginBlocked, err.Error())
+		return nil, err
 	}
 
 	// Verificar contraseña
 	if !user.CheckPassword(req.Password) {
-		return "", nil, errors.New("invalid credentials")
+		as.protection.RecordFailure(&user, user.Email, client, models.AuthEventLoginFailed, "invalid password")
+		return nil, ErrInvalidCredentials
+	}
+
+	// Migrar hashes bcrypt o con parámetros antiguos al algoritmo actual
+	if user.PasswordNeedsRehash() {
+		as.rehashPassword(&user, req.Password)
+	}
+
+	response := user.ToResponse()
+
+	// Segundo paso requerido (el contador se reinicia al completar MFA)
+	if user.MFAEnabled {
+		challenge, err := as.generateToken(&user, 0, TokenPurposeMFAChallenge, mfaChallengeTTL, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate mfa challenge: %w", err)
+		}
+		return &LoginResult{MFARequired: true, MFAToken: challenge, User: &response}, nil
 	}
 
 	// Generar JWT token
-	token, err := as.GenerateJWT(&user)
+	token, err := as.GenerateJWT(&user, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	as.protection.RecordSuccess(&user, client)
+	return &LoginResult{Token: token, User: &response}, nil
+}
+
+// CompleteMFALogin canjea el token de desafío y un código TOTP/recuperación por el JWT final
+func (as *AuthService) CompleteMFALogin(req models.MFALoginRequest, client models.ClientInfo) (string, *models.UserResponse, error) {
+	if err := as.protection.CheckIP(client.IP); err != nil {
+		return "", nil, err
+	}
+
+	claims, err := as.validateToken(req.MFAToken, TokenPurposeMFAChallenge)
+	if err != nil {
+		return "", nil, ErrInvalidMFAToken
+	}
+
+	user, err := as.GetUserByID(claims.UserID)
+	if err != nil {
+		return "", nil, ErrInvalidMFAToken
+	}
+
+	// Los códigos fallidos cuentan como intentos fallidos de la cuenta
+	if err := as.protection.CheckUser(user); err != nil {
+		as.protection.LogEvent(user, user.Email, client, models.AuthEventLoginBlocked, err.Error())
+		return "", nil, err
+	}
+
+	ok, err := NewMFAService(as.db).VerifyCode(user, req.Code)
+	if err != nil {
+		return "", nil, err
+	}
+	if !ok {
+		as.protection.RecordFailure(user, user.Email, client, models.AuthEventMFAFailed, "invalid mfa code")
+		return "", nil, ErrInvalidMFACode
+	}
+
+	token, err := as.GenerateJWT(user, true)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
+	as.protection.RecordSuccess(user, client)
 	response := user.ToResponse()
 	return token, &response, nil
 }
 
-// GenerateJWT genera un token JWT para el usuario
-func (as *AuthService) GenerateJWT(user *models.User) (string, error) {
-	// Obtener la clave secreta
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return "", errors.New("JWT_SECRET not configured")
+// GetRecentAuthEvents obtiene los últimos eventos de autenticación del usuario
+func (as *AuthService) GetRecentAuthEvents(userID uint, limit int) ([]models.AuthEvent, error) {
+	return as.protection.RecentEvents(userID, limit)
+}
+
+// UnlockUser desbloquea manualmente una cuenta bloqueada por intentos fallidos
+func (as *AuthService) UnlockUser(userID uint, client models.ClientInfo, audit models.AuditContext) error {
+	return as.protection.UnlockUser(userID, client, audit)
+}
+
+// GenerateJWT genera un token JWT de acceso para el usuario en su organización por defecto
+// mfaVerified indica si la sesión se autenticó con segundo factor
+func (as *AuthService) GenerateJWT(user *models.User, mfaVerified bool) (string, error) {
+	orgID, err := as.organizations.DefaultOrganizationID(user.ID)
+	if err != nil {
+		return "", err
+	}
+	return as.GenerateJWTForOrganization(user, orgID, mfaVerified)
+}
+
+// GenerateJWTForOrganization genera un token JWT de acceso con la organización indicada activa
+func (as *AuthService) GenerateJWTForOrganization(user *models.User, orgID uint, mfaVerified bool) (string, error) {
+	return as.generateToken(user, orgID, "", 24*time.Hour, mfaVerified) // 24 horas
+}
+
+// ValidateJWT valida un token JWT de acceso y retorna las claims
+func (as *AuthService) ValidateJWT(tokenString string) (*JWTClaims, error) {
+	return as.validateToken(tokenString, "")
+}
+
+// generateToken firma un token con el propósito y la validez indicados
+func (as *AuthService) generateToken(user *models.User, orgID uint, purpose string, ttl time.Duration, mfaVerified bool) (string, error) {
+	// Obtener las claves de firma
+	keys, err := LoadJWTKeys()
+	if err != nil {
+		return "", err
 	}
 
 	// Crear claims
+	now := time.Now()
 	claims := JWTClaims{
-		UserID: user.ID,
-		Email:  user.Email,
+		UserID:  user.ID,
+		Email:   user.Email,
+		Role:    user.Role,
+		OrgID:   orgID,
+		MFA:     mfaVerified,
+		Lang:    user.Language,
+		Purpose: purpose,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // 24 horas
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "inventory-api",
 			Subject:   fmt.Sprintf("%d", user.ID),
 		},
 	}
 
-	// Crear token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Firmar token
-	tokenString, err := token.SignedString([]byte(secret))
+	// Firmar token con la clave activa (incluye el header kid)
+	tokenString, err := keys.Sign(claims)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -110,23 +266,16 @@ func (as *AuthService) GenerateJWT(user *models.User) (string, error) {
 	return tokenString, nil
 }
 
-// ValidateJWT valida un token JWT y retorna las claims
-func (as *AuthService) ValidateJWT(tokenString string) (*JWTClaims, error) {
-	// Obtener la clave secreta
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return nil, errors.New("JWT_SECRET not configured")
+// validateToken valida la firma y que el token tenga el propósito esperado
+func (as *AuthService) validateToken(tokenString, purpose string) (*JWTClaims, error) {
+	// Obtener las claves de verificación
+	keys, err := LoadJWTKeys()
+	if err != nil {
+		return nil, err
 	}
 
 	// Parsear token
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validar método de firma
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(secret), nil
-	})
-
+	token, err := keys.Parse(tokenString, &JWTClaims{})
 	if err != nil {
 		return nil, fmt.Errorf("invalid token: %w", err)
 	}
@@ -137,15 +286,67 @@ func (as *AuthService) ValidateJWT(tokenString string) (*JWTClaims, error) {
 		return nil, errors.New("invalid token claims")
 	}
 
+	// Un token de desafío nunca sirve como token de acceso (y viceversa)
+	if claims.Purpose != purpose {
+		return nil, errors.New("invalid token purpose")
+	}
+
 	return claims, nil
 }
 
+// GetJWKS retorna las claves públicas para que otros servicios verifiquen tokens
+func (as *AuthService) GetJWKS() (JWKSet, error) {
+	keys, err := LoadJWTKeys()
+	if err != nil {
+		return JWKSet{}, err
+	}
+	return keys.JWKS(), nil
+}
+
+// rehashPassword vuelve a hashear la contraseña tras un login correcto
+// Un fallo aquí no impide el login; se reintentará en el siguiente
+func (as *AuthService) rehashPassword(user *models.User, password string) {
+	hashedPassword, err := models.HashPassword(password)
+	if err != nil {
+		log.Printf("Warning: failed to rehash password for user %d: %v", user.ID, err)
+		return
+	}
+
+	// Solo si el hash no cambió mientras tanto (p. ej. un reset de contraseña concurrente)
+	result := as.db.Model(&models.User{}).
+		Where("id = ? AND password = ?", user.ID, user.Password).
+		Update("password", hashedPassword)
+	if result.Error != nil {
+		log.Printf("Warning: failed to rehash password for user %d: %v", user.ID, result.Error)
+		return
+	}
+	if result.RowsAffected == 1 {
+		user.Password = hashedPassword
+	}
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     models.User
+)
+
+// compareDummyPassword ejecuta una verificación de contraseña descartable
+func compareDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		hash, err := models.HashPassword("inventory-api-dummy-password")
+		if err == nil {
+			dummyHash.Password = hash
+		}
+	})
+	dummyHash.CheckPassword(password)
+}
+
 // GetUserByID obtiene un usuario por su ID
 func (as *AuthService) GetUserByID(userID uint) (*models.User, error) {
 	var user models.User
 	if err := as.db.First(&user, userID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("database error: %w", err)
 	}
@@ -153,11 +354,55 @@ func (as *AuthService) GetUserByID(userID uint) (*models.User, error) {
 }
 
 // RefreshToken genera un nuevo token para un usuario autenticado
-func (as *AuthService) RefreshToken(userID uint) (string, error) {
+// Se conservan la organización activa y el estado MFA de la sesión original
+func (as *AuthService) RefreshToken(userID, orgID uint, mfaVerified bool) (string, error) {
+	user, err := as.GetUserByID(userID)
+	if err != nil {
+		return "", err
+	}
+
+	// Si el usuario desactivó MFA la sesión deja de considerarse verificada
+	mfaVerified = mfaVerified && user.MFAEnabled
+
+	if orgID != 0 {
+		if _, err := as.organizations.GetMembership(orgID, userID); err == nil {
+			return as.GenerateJWTForOrganization(user, orgID, mfaVerified)
+		}
+	}
+	return as.GenerateJWT(user, mfaVerified)
+}
+
+// UpdateLanguage cambia el idioma preferido del usuario (vacío lo elimina) y emite un token
+// con la nueva preferencia, conservando la organización activa y el estado MFA de la sesión
+func (as *AuthService) UpdateLanguage(userID, orgID uint, mfaVerified bool, language string) (string, *models.UserResponse, error) {
 	user, err := as.GetUserByID(userID)
 	if err != nil {
+		return "", nil, err
+	}
+
+	if err := as.db.Model(user).Update("language", language).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to update language: %w", err)
+	}
+
+	token, err := as.RefreshToken(userID, orgID, mfaVerified)
+	if err != nil {
+		return "", nil, err
+	}
+
+	response := user.ToResponse()
+	return token, &response, nil
+}
+
+// SwitchOrganization genera un token con otra organización del usuario como activa
+func (as *AuthService) SwitchOrganization(userID, orgID uint, mfaVerified bool) (string, error) {
+	user, err := as.GetUserByID(userID)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := as.organizations.GetMembership(orgID, userID); err != nil {
 		return "", err
 	}
 
-	return as.GenerateJWT(user)
+	return as.GenerateJWTForOrganization(user, orgID, mfaVerified && user.MFAEnabled)
 }
@@ -0,0 +1,388 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"inventory-api/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// categorySubtreeSQL selecciona las categorías que cumplen la condición y todas sus descendientes
+const categorySubtreeSQL = `WITH RECURSIVE subtree AS (
+	SELECT id FROM categories WHERE %s
+	UNION
+	SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+) SELECT id FROM subtree`
+
+// CategoryService maneja el árbol de categorías de productos de una organización
+// Las consultas se limitan a la organización indicada con WithTenant
+// y los cambios se atribuyen en la auditoría al autor indicado con WithActor
+type CategoryService struct {
+	db    *gorm.DB
+	orgID uint
+	audit models.AuditContext
+}
+
+// NewCategoryService crea una nueva instancia del servicio de categorías
+func NewCategoryService(db *gorm.DB) *CategoryService {
+	return &CategoryService{db: db}
+}
+
+// WithTenant retorna una copia del servicio limitada a la organización indicada
+func (cs *CategoryService) WithTenant(orgID uint) *CategoryService {
+	scoped := *cs
+	scoped.orgID = orgID
+	return &scoped
+}
+
+// WithActor retorna una copia del servicio que atribuye los cambios al autor indicado
+func (cs *CategoryService) WithActor(audit models.AuditContext) *CategoryService {
+	scoped := *cs
+	scoped.audit = audit
+	return &scoped
+}
+
+// tenant retorna una consulta de categorías filtrada por la organización actual
+func (cs *CategoryService) tenant() *gorm.DB {
+	return cs.db.Model(&models.Category{}).Where("organization_id = ?", cs.orgID)
+}
+
+// ListCategories obtiene las categorías de la organización como árbol,
+// o como lista ordenada por ruta si flat es true
+func (cs *CategoryService) ListCategories(flat bool) ([]models.CategoryResponse, error) {
+	var categories []models.Category
+	if err := cs.tenant().Order("name").Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch categories: %w", err)
+	}
+
+	counts, err := cs.productCounts()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*models.Category, len(categories))
+	children := make(map[uint][]*models.Category)
+	var roots []*models.Category
+	for i := range categories {
+		category := &categories[i]
+		byID[category.ID] = category
+	}
+	for i := range categories {
+		category := &categories[i]
+		if category.ParentID == nil || byID[*category.ParentID] == nil {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	// build recorre el árbol en profundidad; en modo plano añade cada nodo a la lista
+	list := []models.CategoryResponse{}
+	var build func(category *models.Category, parentPath string) models.CategoryResponse
+	build = func(category *models.Category, parentPath string) models.CategoryResponse {
+		path := category.Name
+		if parentPath != "" {
+			path = parentPath + " / " + category.Name
+		}
+
+		response := category.ToResponse(path, counts[category.ID])
+		if flat {
+			list = append(list, response)
+		}
+		for _, child := range children[category.ID] {
+			childResponse := build(child, path)
+			if !flat {
+				response.Children = append(response.Children, childResponse)
+			}
+		}
+		return response
+	}
+
+	for _, root := range roots {
+		response := build(root, "")
+		if !flat {
+			list = append(list, response)
+		}
+	}
+	return list, nil
+}
+
+// GetCategory obtiene una categoría con su ruta y sus subcategorías directas
+func (cs *CategoryService) GetCategory(id uint) (*models.CategoryResponse, error) {
+	category, err := findCategory(cs.db, cs.orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := cs.categoryPath(category)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := cs.productCounts()
+	if err != nil {
+		return nil, err
+	}
+
+	var children []models.Category
+	if err := cs.tenant().Where("parent_id = ?", category.ID).Order("name").Find(&children).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch subcategories: %w", err)
+	}
+
+	response := category.ToResponse(path, counts[category.ID])
+	for _, child := range children {
+		response.Children = append(response.Children, child.ToResponse(path+" / "+child.Name, counts[child.ID]))
+	}
+	return &response, nil
+}
+
+// CreateCategory crea una categoría, en la raíz o bajo la categoría padre indicada
+func (cs *CategoryService) CreateCategory(req models.CategoryRequest) (*models.CategoryResponse, error) {
+	if cs.orgID == 0 {
+		return nil, ErrOrganizationRequired
+	}
+
+	category := models.Category{
+		OrganizationID: cs.orgID,
+		ParentID:       req.ParentID,
+		Name:           strings.TrimSpace(req.Name),
+	}
+	if err := cs.applySlug(&category, req.Slug); err != nil {
+		return nil, err
+	}
+	if err := cs.checkParent(&category); err != nil {
+		return nil, err
+	}
+
+	err := cs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&category).Error; err != nil {
+			return fmt.Errorf("failed to create category: %w", err)
+		}
+		return recordAudit(tx, cs.audit, models.AuditActionCreate, models.AuditEntityCategory,
+			category.ID, category.OrganizationID, nil, category.AuditFields())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := cs.categoryPath(&category)
+	if err != nil {
+		return nil, err
+	}
+	response := category.ToResponse(path, 0)
+	return &response, nil
+}
+
+// UpdateCategory renombra o mueve una categoría
+// El nombre se copia a sus productos (también a los de la papelera)
+func (cs *CategoryService) UpdateCategory(id uint, req models.CategoryRequest) (*models.CategoryResponse, error) {
+	category, err := findCategory(cs.db, cs.orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	before := category.AuditFields()
+	oldName := category.Name
+
+	category.Name = strings.TrimSpace(req.Name)
+	category.ParentID = req.ParentID
+	if req.Slug != "" {
+		if err := cs.applySlug(category, req.Slug); err != nil {
+			return nil, err
+		}
+	}
+	if err := cs.checkParent(category); err != nil {
+		return nil, err
+	}
+
+	err = cs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(category).Error; err != nil {
+			return fmt.Errorf("failed to update category: %w", err)
+		}
+		if category.Name != oldName {
+			if err := tx.Unscoped().Model(&models.Product{}).Where("category_id = ?", category.ID).
+				UpdateColumn("category", category.Name).Error; err != nil {
+				return fmt.Errorf("failed to rename category in products: %w", err)
+			}
+		}
+		return recordAudit(tx, cs.audit, models.AuditActionUpdate, models.AuditEntityCategory,
+			category.ID, category.OrganizationID, before, category.AuditFields())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cs.GetCategory(category.ID)
+}
+
+// DeleteCategory elimina una categoría vacía: sin subcategorías ni productos (incluida la papelera)
+func (cs *CategoryService) DeleteCategory(id uint) error {
+	category, err := findCategory(cs.db, cs.orgID, id)
+	if err != nil {
+		return err
+	}
+
+	var subcategories int64
+	if err := cs.tenant().Where("parent_id = ?", category.ID).Count(&subcategories).Error; err != nil {
+		return fmt.Errorf("failed to count subcategories: %w", err)
+	}
+	if subcategories > 0 {
+		return ErrCategoryHasChildren
+	}
+
+	var products int64
+	if err := cs.db.Unscoped().Model(&models.Product{}).Where("category_id = ?", category.ID).Count(&products).Error; err != nil {
+		return fmt.Errorf("failed to count products: %w", err)
+	}
+	if products > 0 {
+		return ErrCategoryHasProducts
+	}
+
+	return cs.db.Transaction(func(tx *gorm.DB) error {
+		// Las definiciones de atributos y la regla de stock de la categoría ya no aplican a ningún producto
+		if err := tx.Where("category_id = ?", category.ID).Delete(&models.AttributeDefinition{}).Error; err != nil {
+			return fmt.Errorf("failed to delete category attributes: %w", err)
+		}
+		if err := tx.Where("category_id = ?", category.ID).Delete(&models.StockRule{}).Error; err != nil {
+			return fmt.Errorf("failed to delete category stock rule: %w", err)
+		}
+		if err := tx.Delete(category).Error; err != nil {
+			return fmt.Errorf("failed to delete category: %w", err)
+		}
+		return recordAudit(tx, cs.audit, models.AuditActionDelete, models.AuditEntityCategory,
+			category.ID, category.OrganizationID, category.AuditFields(), nil)
+	})
+}
+
+// applySlug asigna el slug indicado, o el derivado del nombre, y verifica que esté libre
+func (cs *CategoryService) applySlug(category *models.Category, slug string) error {
+	if slug == "" {
+		slug = slugify(category.Name)
+	}
+	if slug == "" || slug != slugify(slug) {
+		return ErrInvalidCategorySlug
+	}
+
+	var count int64
+	if err := cs.tenant().Where("slug = ? AND id <> ?", slug, category.ID).Count(&count).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if count > 0 {
+		return ErrCategorySlugExists
+	}
+
+	category.Slug = slug
+	return nil
+}
+
+// checkParent verifica que la categoría padre exista y que no se cree un ciclo
+func (cs *CategoryService) checkParent(category *models.Category) error {
+	parentID := category.ParentID
+	for parentID != nil {
+		if *parentID == category.ID {
+			return ErrCategoryCycle
+		}
+
+		parent, err := findCategory(cs.db, cs.orgID, *parentID)
+		if err != nil {
+			if errors.Is(err, ErrCategoryNotFound) {
+				return ErrParentCategoryNotFound
+			}
+			return err
+		}
+		// Una categoría nueva no puede ser antecesora de nadie
+		if category.ID == 0 {
+			return nil
+		}
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
+// categoryPath construye la ruta de nombres desde la raíz hasta la categoría
+func (cs *CategoryService) categoryPath(category *models.Category) (string, error) {
+	names := []string{category.Name}
+	parentID := category.ParentID
+	for parentID != nil {
+		parent, err := findCategory(cs.db, cs.orgID, *parentID)
+		if err != nil {
+			return "", err
+		}
+		names = append([]string{parent.Name}, names...)
+		parentID = parent.ParentID
+	}
+	return strings.Join(names, " / "), nil
+}
+
+// productCounts cuenta los productos activos de cada categoría de la organización
+func (cs *CategoryService) productCounts() (map[uint]int64, error) {
+	var rows []struct {
+		CategoryID uint
+		Total      int64
+	}
+	err := cs.db.Model(&models.Product{}).
+		Select("category_id, COUNT(*) AS total").
+		Where("organization_id = ? AND category_id IS NOT NULL", cs.orgID).
+		Group("category_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count products by category: %w", err)
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Total
+	}
+	return counts, nil
+}
+
+// findCategory obtiene una categoría de la organización
+func findCategory(db *gorm.DB, orgID, id uint) (*models.Category, error) {
+	var category models.Category
+	if err := db.Where("organization_id = ?", orgID).First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch category: %w", err)
+	}
+	return &category, nil
+}
+
+// resolveProductCategory obtiene la categoría de un producto por ID o, si no se indica,
+// por el nombre o slug recibido. Las categorías desconocidas se crean en la raíz para
+// que los clientes que envían texto libre sigan funcionando
+func resolveProductCategory(db *gorm.DB, orgID uint, categoryID *uint, name string) (*models.Category, error) {
+	if categoryID != nil {
+		return findCategory(db, orgID, *categoryID)
+	}
+
+	name = strings.TrimSpace(name)
+	slug := slugify(name)
+	if slug == "" {
+		return nil, ErrInvalidCategory
+	}
+
+	var category models.Category
+	err := db.Where("organization_id = ? AND slug = ?", orgID, slug).First(&category).Error
+	if err == nil {
+		return &category, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to fetch category: %w", err)
+	}
+
+	category = models.Category{OrganizationID: orgID, Name: name, Slug: slug}
+	// Si otra petición la creó a la vez, se usa la existente
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&category).Error; err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+	if category.ID == 0 {
+		if err := db.Where("organization_id = ? AND slug = ?", orgID, slug).First(&category).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch category: %w", err)
+		}
+	}
+	return &category, nil
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// envInt lee un entero de las variables de entorno con valor por defecto
+func envInt(name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: invalid %s=%q, using %d", name, value, def)
+		return def
+	}
+	return n
+}
+
+// envDuration lee una duración (p. ej. "15m") de las variables de entorno con valor por defecto
+func envDuration(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Warning: invalid %s=%q, using %s", name, value, def)
+		return def
+	}
+	return d
+}
+
+// envBool lee un booleano ("true", "1", "false"...) de las variables de entorno con valor por defecto
+func envBool(name string, def bool) bool {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: invalid %s=%q, using %t", name, value, def)
+		return def
+	}
+	return b
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+
+	"inventory-api/internal/i18n"
+)
+
+// Errores de los servicios. Los controladores los reconocen con errors.Is y el manejador de
+// errores de la API los traduce a respuestas problem+json; cualquier otro error (p. ej. de la
+// base de datos) se trata como interno
+
+// Organizaciones y miembros
+var (
+	ErrOrganizationRequired    = errors.New("organization required")
+	ErrOrganizationNotFound    = errors.New("organization not found")
+	ErrInvalidOrganizationSlug = errors.New("invalid organization slug")
+	ErrOrganizationSlugExists  = errors.New("organization slug already exists")
+	ErrMembershipNotFound      = errors.New("membership not found")
+	ErrAlreadyMember           = errors.New("user is already a member")
+	ErrInvalidRole             = errors.New("invalid role")
+	ErrOwnerRequired           = errors.New("only owners can manage owners")
+	ErrLastOwner               = errors.New("cannot remove the last owner")
+)
+
+// Usuarios, sesiones y credenciales
+var (
+	ErrUserNotFound         = errors.New("user not found")
+	ErrUserExists           = errors.New("user already exists")
+	ErrInvalidCredentials   = errors.New("invalid credentials")
+	ErrInvalidToken         = errors.New("invalid or expired token")
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+	ErrInvalidMFAToken      = errors.New("invalid or expired mfa token")
+	ErrInvalidMFACode       = errors.New("invalid mfa code")
+	ErrMFANotEnabled        = errors.New("mfa not enabled")
+	ErrMFAAlreadyEnabled    = errors.New("mfa already enabled")
+	ErrMFASetupNotStarted   = errors.New("mfa setup not started")
+	ErrInvalidAPIKey        = errors.New("invalid api key")
+	ErrAPIKeyNotFound       = errors.New("api key not found")
+)
+
+// Inicio de sesión con OIDC
+var (
+	ErrOIDCNotConfigured    = errors.New("oidc not configured")
+	ErrInvalidOIDCState     = errors.New("invalid or expired oidc state")
+	ErrOIDCNoEmail          = errors.New("id token has no email claim")
+	ErrOIDCEmailNotVerified = errors.New("email not verified by identity provider")
+	ErrOIDCAccountLinked    = errors.New("account already linked to another identity")
+)
+
+// Productos
+var (
+	ErrProductNotFound      = errors.New("product not found")
+	ErrProductNotInTrash    = errors.New("product not in trash")
+	ErrSKUExists            = errors.New("sku already exists")
+	ErrInsufficientStock    = errors.New("insufficient stock")
+	ErrInvalidTag           = errors.New("invalid tag")
+	ErrTooManyTags          = errors.New("too many tags")
+	ErrInvalidSortField     = errors.New("invalid sort field")
+	ErrInvalidCursor        = errors.New("invalid cursor")
+	ErrUnsupportedPatchType = errors.New("unsupported patch type")
+	ErrPatchTestFailed      = errors.New("patch test failed")
+	ErrInvalidBulkMode      = errors.New("invalid bulk mode")
+	ErrNoOperations         = errors.New("no operations")
+	ErrTooManyOperations    = errors.New("too many operations")
+	ErrNoChanges            = errors.New("no changes")
+	ErrInvalidMassUpdate    = errors.New("invalid mass update change")
+	ErrFiltersRequired      = errors.New("filters required")
+)
+
+// Categorías y atributos
+var (
+	ErrCategoryNotFound       = errors.New("category not found")
+	ErrInvalidCategory        = errors.New("invalid category")
+	ErrInvalidCategorySlug    = errors.New("invalid category slug")
+	ErrCategorySlugExists     = errors.New("category slug already exists")
+	ErrParentCategoryNotFound = errors.New("parent category not found")
+	ErrCategoryCycle          = errors.New("category cycle")
+	ErrCategoryHasChildren    = errors.New("category has subcategories")
+	ErrCategoryHasProducts    = errors.New("category has products")
+	ErrAttributeNotFound      = errors.New("attribute not found")
+	ErrAttributeExists        = errors.New("attribute already defined")
+	ErrAttributeTypeChange    = errors.New("attribute type cannot change")
+	ErrInvalidAttributeKey    = errors.New("invalid attribute key")
+	ErrInvalidAttributeType   = errors.New("invalid attribute type")
+	ErrInvalidAttributeLabel  = errors.New("invalid attribute label")
+	ErrEnumOptionsRequired    = errors.New("enum attribute requires options")
+)
+
+// Reglas de stock
+var (
+	ErrStockRuleNotFound      = errors.New("stock rule not found")
+	ErrStockRuleExists        = errors.New("stock rule already exists")
+	ErrStockRuleTargetChange  = errors.New("stock rule scope cannot change")
+	ErrInvalidStockRuleTarget = errors.New("invalid stock rule target")
+	ErrInvalidStockThresholds = errors.New("invalid stock thresholds")
+	ErrInvalidStockPercent    = errors.New("stock percent threshold above 100")
+)
+
+// APIKeyPermissionError indica un permiso que no existe o que el propietario de la API key no tiene
+type APIKeyPermissionError struct {
+	Permission string
+	Reason     i18n.Message // Recibe el permiso como argumento
+}
+
+// Error implementa la interfaz error
+func (e *APIKeyPermissionError) Error() string {
+	return e.Reason.String()
+}
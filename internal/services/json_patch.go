@@ -0,0 +1,298 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"inventory-api/internal/i18n"
+)
+
+// JSONPatchError indica un parche mal formado o que no puede aplicarse al documento
+type JSONPatchError struct {
+	Reason i18n.Message
+}
+
+// Error implementa la interfaz error
+func (e *JSONPatchError) Error() string {
+	return "invalid patch: " + e.Reason.String()
+}
+
+// jsonPatchOperation representa una operación de un JSON Patch (RFC 6902)
+// Value es nil si la operación no lo incluye, y "null" si incluye un null explícito
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// applyMergePatch aplica un JSON Merge Patch (RFC 7386): los objetos se combinan campo a campo,
+// un null elimina el campo y cualquier otro valor lo reemplaza. Modifica target
+func applyMergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = applyMergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
+
+// applyJSONPatch aplica en orden las operaciones de un JSON Patch (RFC 6902)
+// Si una falla, el parche entero se descarta
+func applyJSONPatch(doc interface{}, data []byte) (interface{}, error) {
+	var operations []jsonPatchOperation
+	if err := json.Unmarshal(data, &operations); err != nil {
+		return nil, &JSONPatchError{Reason: i18n.Msg("patch.not_array")}
+	}
+
+	for i, op := range operations {
+		var err error
+		if doc, err = applyJSONPatchOperation(doc, op); err != nil {
+			var patchErr *JSONPatchError
+			if errors.As(err, &patchErr) {
+				patchErr.Reason = i18n.Msg("patch.operation", i, patchErr.Reason)
+			}
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// applyJSONPatchOperation aplica una operación y retorna el documento resultante
+func applyJSONPatchOperation(doc interface{}, op jsonPatchOperation) (interface{}, error) {
+	if op.Path == nil {
+		return nil, &JSONPatchError{Reason: i18n.Msg("patch.path_required")}
+	}
+	path, err := parseJSONPointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, &JSONPatchError{Reason: i18n.Msg("patch.value_required", op.Op)}
+		}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, &JSONPatchError{Reason: i18n.Msg("patch.invalid_value")}
+		}
+	case "move", "copy":
+		if op.From == nil {
+			return nil, &JSONPatchError{Reason: i18n.Msg("patch.from_required", op.Op)}
+		}
+		from, err := parseJSONPointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = jsonPointerGet(doc, from); err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			value = copyJSONValue(value)
+			break
+		}
+		if strings.HasPrefix(*op.Path, *op.From+"/") {
+			return nil, &JSONPatchError{Reason: i18n.Msg("patch.move_into_itself")}
+		}
+		if doc, err = jsonPointerRemove(doc, from); err != nil {
+			return nil, err
+		}
+	case "remove":
+		return jsonPointerRemove(doc, path)
+	default:
+		return nil, &JSONPatchError{Reason: i18n.Msg("patch.invalid_op")}
+	}
+
+	switch op.Op {
+	case "replace":
+		if _, err := jsonPointerGet(doc, path); err != nil {
+			return nil, err
+		}
+		if len(path) > 0 {
+			if doc, err = jsonPointerRemove(doc, path); err != nil {
+				return nil, err
+			}
+		}
+	case "test":
+		current, err := jsonPointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(current, value) {
+			return nil, ErrPatchTestFailed
+		}
+		return doc, nil
+	}
+	return jsonPointerAdd(doc, path, value)
+}
+
+// parseJSONPointer separa un JSON Pointer (RFC 6901) en sus tokens; "" es el documento entero
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, &JSONPatchError{Reason: i18n.Msg("patch.invalid_path", pointer)}
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// jsonPointerGet retorna el valor al que apunta path
+func jsonPointerGet(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		child, err := jsonChild(doc, token)
+		if err != nil {
+			return nil, err
+		}
+		doc = child
+	}
+	return doc, nil
+}
+
+// jsonPointerAdd añade value en path: en un objeto crea o reemplaza el campo y en un array
+// lo inserta en la posición indicada ("-" para el final)
+func jsonPointerAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return jsonPointerUpdate(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch node := container.(type) {
+		case map[string]interface{}:
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			index := len(node)
+			if token != "-" {
+				var err error
+				if index, err = jsonArrayIndex(node, token, true); err != nil {
+					return nil, err
+				}
+			}
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		}
+		return nil, &JSONPatchError{Reason: i18n.Msg("patch.add_to_scalar", token)}
+	})
+}
+
+// jsonPointerRemove elimina el valor al que apunta path, que debe existir
+func jsonPointerRemove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, &JSONPatchError{Reason: i18n.Msg("patch.remove_document")}
+	}
+	return jsonPointerUpdate(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch node := container.(type) {
+		case map[string]interface{}:
+			if _, ok := node[token]; !ok {
+				return nil, &JSONPatchError{Reason: i18n.Msg("patch.path_not_found", token)}
+			}
+			delete(node, token)
+			return node, nil
+		case []interface{}:
+			index, err := jsonArrayIndex(node, token, false)
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:index], node[index+1:]...), nil
+		}
+		return nil, &JSONPatchError{Reason: i18n.Msg("patch.remove_from_scalar", token)}
+	})
+}
+
+// jsonPointerUpdate recorre path hasta el contenedor del último token, le aplica fn y
+// retorna el documento con el contenedor actualizado (los arrays pueden cambiar de longitud)
+func jsonPointerUpdate(doc interface{}, path []string, fn func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	child, err := jsonChild(doc, path[0])
+	if err != nil {
+		return nil, err
+	}
+	updated, err := jsonPointerUpdate(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		node[path[0]] = updated
+	case []interface{}:
+		index, _ := jsonArrayIndex(node, path[0], false)
+		node[index] = updated
+	}
+	return doc, nil
+}
+
+// jsonChild retorna el campo o elemento token de un objeto o array
+func jsonChild(doc interface{}, token string) (interface{}, error) {
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok {
+			return nil, &JSONPatchError{Reason: i18n.Msg("patch.path_not_found", token)}
+		}
+		return child, nil
+	case []interface{}:
+		index, err := jsonArrayIndex(node, token, false)
+		if err != nil {
+			return nil, err
+		}
+		return node[index], nil
+	}
+	return nil, &JSONPatchError{Reason: i18n.Msg("patch.path_not_found", token)}
+}
+
+// jsonArrayIndex convierte token en un índice de array; con end se admite la posición tras el último
+// Como exige el RFC 6901, solo se aceptan dígitos y sin ceros a la izquierda ("+1" o "01" no valen)
+func jsonArrayIndex(array []interface{}, token string, end bool) (int, error) {
+	limit := len(array)
+	if end {
+		limit++
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || strings.TrimLeft(token, "0123456789") != "" || index >= limit || (len(token) > 1 && token[0] == '0') {
+		return 0, &JSONPatchError{Reason: i18n.Msg("patch.invalid_index", token)}
+	}
+	return index, nil
+}
+
+// copyJSONValue copia en profundidad un valor JSON decodificado
+func copyJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			copied[key] = copyJSONValue(child)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, child := range v {
+			copied[i] = copyJSONValue(child)
+		}
+		return copied
+	}
+	return value
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// decodeJSON decodifica un documento JSON de prueba
+func decodeJSON(t *testing.T, data string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		t.Fatalf("invalid test JSON %s: %v", data, err)
+	}
+	return value
+}
+
+// TestApplyJSONPatch cubre los ejemplos del apéndice A del RFC 6902 y los casos límite de
+// los JSON Pointer (RFC 6901) y de los índices de array
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string // Vacío si el parche debe fallar
+		testErr bool   // El fallo esperado es el de una operación test
+	}{
+		// RFC 6902, apéndice A
+		{
+			name:  "A.1 adding an object member",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/baz","value":"qux"}]`,
+			want:  `{"baz":"qux","foo":"bar"}`,
+		},
+		{
+			name:  "A.2 adding an array element",
+			doc:   `{"foo":["bar","baz"]}`,
+			patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+			want:  `{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			name:  "A.3 removing an object member",
+			doc:   `{"baz":"qux","foo":"bar"}`,
+			patch: `[{"op":"remove","path":"/baz"}]`,
+			want:  `{"foo":"bar"}`,
+		},
+		{
+			name:  "A.4 removing an array element",
+			doc:   `{"foo":["bar","qux","baz"]}`,
+			patch: `[{"op":"remove","path":"/foo/1"}]`,
+			want:  `{"foo":["bar","baz"]}`,
+		},
+		{
+			name:  "A.5 replacing a value",
+			doc:   `{"baz":"qux","foo":"bar"}`,
+			patch: `[{"op":"replace","path":"/baz","value":"boo"}]`,
+			want:  `{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			name:  "A.6 moving a value",
+			doc:   `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			want:  `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			name:  "A.7 moving an array element",
+			doc:   `{"foo":["all","grass","cows","eat"]}`,
+			patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			want:  `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			name: "A.8 testing a value: success",
+			doc:  `{"baz":"qux","foo":["a",2,"c"]}`,
+			patch: `[{"op":"test","path":"/baz","value":"qux"},
+				{"op":"test","path":"/foo/1","value":2}]`,
+			want: `{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{
+			name:    "A.9 testing a value: error",
+			doc:     `{"baz":"qux"}`,
+			patch:   `[{"op":"test","path":"/baz","value":"bar"}]`,
+			testErr: true,
+		},
+		{
+			name:  "A.10 adding a nested member object",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`,
+			want:  `{"foo":"bar","child":{"grandchild":{}}}`,
+		},
+		{
+			name:  "A.11 ignoring unrecognized elements",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`,
+			want:  `{"foo":"bar","baz":"qux"}`,
+		},
+		{
+			name:  "A.12 adding to a nonexistent target",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+		},
+		{
+			name:  "A.14 ~ escape ordering",
+			doc:   `{"/":9,"~1":10}`,
+			patch: `[{"op":"test","path":"/~01","value":10}]`,
+			want:  `{"/":9,"~1":10}`,
+		},
+		{
+			name:    "A.15 comparing strings and numbers",
+			doc:     `{"/":9,"~1":10}`,
+			patch:   `[{"op":"test","path":"/~01","value":"10"}]`,
+			testErr: true,
+		},
+		{
+			name:  "A.16 adding an array value",
+			doc:   `{"foo":["bar"]}`,
+			patch: `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+			want:  `{"foo":["bar",["abc","def"]]}`,
+		},
+
+		// JSON Pointer y operaciones
+		{
+			name:  "escaped slash in a member name",
+			doc:   `{"a/b":1}`,
+			patch: `[{"op":"replace","path":"/a~1b","value":2}]`,
+			want:  `{"a/b":2}`,
+		},
+		{
+			name:  "empty member name",
+			doc:   `{"":1}`,
+			patch: `[{"op":"remove","path":"/"}]`,
+			want:  `{}`,
+		},
+		{
+			name:  "replace the whole document",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"replace","path":"","value":{"baz":1}}]`,
+			want:  `{"baz":1}`,
+		},
+		{
+			name:  "remove the whole document",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"remove","path":""}]`,
+		},
+		{
+			name:  "path without a leading slash",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"remove","path":"foo"}]`,
+		},
+		{
+			name:  "add replaces an existing member",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/foo","value":null}]`,
+			want:  `{"foo":null}`,
+		},
+		{
+			name:  "add at the end of an array by index",
+			doc:   `{"foo":["bar"]}`,
+			patch: `[{"op":"add","path":"/foo/1","value":"baz"}]`,
+			want:  `{"foo":["bar","baz"]}`,
+		},
+		{
+			name:  "add past the end of an array",
+			doc:   `{"foo":["bar"]}`,
+			patch: `[{"op":"add","path":"/foo/2","value":"baz"}]`,
+		},
+		{
+			name:  "array index with a leading zero",
+			doc:   `{"foo":["bar","baz"]}`,
+			patch: `[{"op":"remove","path":"/foo/01"}]`,
+		},
+		{
+			name:  "array index with a sign",
+			doc:   `{"foo":["bar","baz"]}`,
+			patch: `[{"op":"remove","path":"/foo/+1"}]`,
+		},
+		{
+			name:  "negative array index",
+			doc:   `{"foo":["bar","baz"]}`,
+			patch: `[{"op":"remove","path":"/foo/-1"}]`,
+		},
+		{
+			name:  "remove the end of an array",
+			doc:   `{"foo":["bar"]}`,
+			patch: `[{"op":"remove","path":"/foo/-"}]`,
+		},
+		{
+			name:  "remove a missing member",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"remove","path":"/baz"}]`,
+		},
+		{
+			name:  "replace a missing member",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"replace","path":"/baz","value":1}]`,
+		},
+		{
+			name:  "replace an array element",
+			doc:   `{"foo":["bar","baz","qux"]}`,
+			patch: `[{"op":"replace","path":"/foo/1","value":"boo"}]`,
+			want:  `{"foo":["bar","boo","qux"]}`,
+		},
+		{
+			name:  "move a value into its own child",
+			doc:   `{"foo":{"bar":1}}`,
+			patch: `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`,
+		},
+		{
+			name:  "move a value onto itself",
+			doc:   `{"foo":{"bar":1}}`,
+			patch: `[{"op":"move","from":"/foo","path":"/foo"}]`,
+			want:  `{"foo":{"bar":1}}`,
+		},
+		{
+			name:  "move to a sibling sharing a prefix",
+			doc:   `{"foo":1}`,
+			patch: `[{"op":"move","from":"/foo","path":"/foobar"}]`,
+			want:  `{"foobar":1}`,
+		},
+		{
+			name: "copy is a deep copy",
+			doc:  `{"foo":{"bar":1}}`,
+			patch: `[{"op":"copy","from":"/foo","path":"/baz"},
+				{"op":"replace","path":"/baz/bar","value":2}]`,
+			want: `{"foo":{"bar":1},"baz":{"bar":2}}`,
+		},
+		{
+			name:  "copy from a missing member",
+			doc:   `{"foo":1}`,
+			patch: `[{"op":"copy","from":"/bar","path":"/baz"}]`,
+		},
+		{
+			name:  "test objects ignoring member order",
+			doc:   `{"foo":{"a":1,"b":[1,2]}}`,
+			patch: `[{"op":"test","path":"/foo","value":{"b":[1,2],"a":1}}]`,
+			want:  `{"foo":{"a":1,"b":[1,2]}}`,
+		},
+		{
+			name:    "test arrays in order",
+			doc:     `{"foo":[1,2]}`,
+			patch:   `[{"op":"test","path":"/foo","value":[2,1]}]`,
+			testErr: true,
+		},
+		{
+			name:  "test a null value",
+			doc:   `{"foo":null}`,
+			patch: `[{"op":"test","path":"/foo","value":null}]`,
+			want:  `{"foo":null}`,
+		},
+		{
+			name:  "test a missing member",
+			doc:   `{"foo":1}`,
+			patch: `[{"op":"test","path":"/bar","value":null}]`,
+		},
+
+		// Parches mal formados
+		{
+			name:  "patch is not an array",
+			doc:   `{"foo":1}`,
+			patch: `{"op":"remove","path":"/foo"}`,
+		},
+		{
+			name:  "unknown op",
+			doc:   `{"foo":1}`,
+			patch: `[{"op":"delete","path":"/foo"}]`,
+		},
+		{
+			name:  "missing path",
+			doc:   `{"foo":1}`,
+			patch: `[{"op":"remove"}]`,
+		},
+		{
+			name:  "missing value",
+			doc:   `{"foo":1}`,
+			patch: `[{"op":"add","path":"/bar"}]`,
+		},
+		{
+			name:  "missing from",
+			doc:   `{"foo":1}`,
+			patch: `[{"op":"move","path":"/bar"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyJSONPatch(decodeJSON(t, tt.doc), []byte(tt.patch))
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				var patchErr *JSONPatchError
+				switch {
+				case tt.testErr && !errors.Is(err, ErrPatchTestFailed):
+					t.Fatalf("expected ErrPatchTestFailed, got %v", err)
+				case !tt.testErr && !errors.As(err, &patchErr):
+					t.Fatalf("expected a JSONPatchError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := decodeJSON(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Fatalf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// TestApplyMergePatch cubre los ejemplos del apéndice A del RFC 7386
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target+" + "+tt.patch, func(t *testing.T) {
+			got := applyMergePatch(decodeJSON(t, tt.target), decodeJSON(t, tt.patch))
+			if want := decodeJSON(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Fatalf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTKey representa una clave usada para firmar y/o verificar tokens
+type JWTKey struct {
+	KID     string
+	Method  jwt.SigningMethod
+	Public  crypto.PublicKey
+	private crypto.Signer // nil si la clave solo sirve para verificar
+}
+
+// JWK representa una clave pública en formato JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet representa el documento publicado en /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWTKeySet agrupa la clave activa de firma y las claves de verificación
+type JWTKeySet struct {
+	signing    *JWTKey
+	keys       map[string]*JWTKey
+	order      []string
+	hmacSecret []byte // Solo se usa si no hay claves asimétricas configuradas
+}
+
+var (
+	jwtKeySetOnce sync.Once
+	jwtKeySet     *JWTKeySet
+	jwtKeySetErr  error
+)
+
+// LoadJWTKeys carga las claves JWT una sola vez desde las variables de entorno
+//
+// Variables soportadas:
+//   - JWT_SIGNING_KEY_FILE: clave privada PEM (RSA o Ed25519) usada para firmar
+//   - JWT_SIGNING_KEY_ID: kid de la clave de firma (por defecto su thumbprint RFC 7638)
+//   - JWT_VERIFICATION_KEY_FILES: lista separada por comas de claves PEM adicionales
+//     aceptadas al verificar (rotación), con formato "ruta" o "kid=ruta"
+//   - JWT_SECRET: secreto HS256 heredado, usado solo si no hay claves asimétricas
+func LoadJWTKeys() (*JWTKeySet, error) {
+	jwtKeySetOnce.Do(func() {
+		jwtKeySet, jwtKeySetErr = newJWTKeySetFromEnv()
+	})
+	return jwtKeySet, jwtKeySetErr
+}
+
+// newJWTKeySetFromEnv construye el conjunto de claves a partir del entorno
+func newJWTKeySetFromEnv() (*JWTKeySet, error) {
+	ks := &JWTKeySet{keys: make(map[string]*JWTKey)}
+
+	// Clave de firma activa
+	if path := os.Getenv("JWT_SIGNING_KEY_FILE"); path != "" {
+		key, err := loadPEMKey(path, os.Getenv("JWT_SIGNING_KEY_ID"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load signing key: %w", err)
+		}
+		if key.private == nil {
+			return nil, errors.New("JWT_SIGNING_KEY_FILE must contain a private key")
+		}
+		ks.signing = key
+		ks.add(key)
+	}
+
+	// Claves adicionales de verificación (claves anteriores durante la rotación)
+	for _, entry := range strings.Split(os.Getenv("JWT_VERIFICATION_KEY_FILES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kid, path := "", entry
+		if i := strings.Index(entry, "="); i > 0 {
+			kid, path = entry[:i], entry[i+1:]
+		}
+
+		key, err := loadPEMKey(path, kid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load verification key %s: %w", path, err)
+		}
+		key.private = nil // Las claves de verificación nunca firman
+		ks.add(key)
+	}
+
+	if len(ks.keys) > 0 {
+		if ks.signing == nil {
+			return nil, errors.New("JWT_SIGNING_KEY_FILE is required when verification keys are configured")
+		}
+		return ks, nil
+	}
+
+	// Compatibilidad con la configuración HS256 anterior
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return nil, errors.New("JWT signing key not configured (set JWT_SIGNING_KEY_FILE or JWT_SECRET)")
+	}
+	ks.hmacSecret = []byte(secret)
+	return ks, nil
+}
+
+// add registra una clave respetando el orden de carga
+func (ks *JWTKeySet) add(key *JWTKey) {
+	if _, exists := ks.keys[key.KID]; !exists {
+		ks.order = append(ks.order, key.KID)
+	}
+	ks.keys[key.KID] = key
+}
+
+// Sign firma las claims con la clave activa e incluye el header kid
+func (ks *JWTKeySet) Sign(claims jwt.Claims) (string, error) {
+	if ks.signing == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ks.hmacSecret)
+	}
+
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	token.Header["kid"] = ks.signing.KID
+	return token.SignedString(ks.signing.private)
+}
+
+// Parse valida la firma del token eligiendo la clave según su kid
+func (ks *JWTKeySet) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	if ks.signing == nil {
+		return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			return ks.hmacSecret, nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	}
+
+	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ks.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id: %q", kid)
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.Public, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}))
+}
+
+// JWKS retorna las claves públicas de verificación en formato JWK
+func (ks *JWTKeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, kid := range ks.order {
+		if jwk, err := toJWK(ks.keys[kid]); err == nil {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+// loadPEMKey lee una clave PEM privada o pública desde disco
+func loadPEMKey(path, kid string) (*JWTKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	key := &JWTKey{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key.private = priv
+	case "PRIVATE KEY":
+		priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := priv.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported private key type")
+		}
+		key.private = signer
+	case "RSA PUBLIC KEY":
+		pub, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key.Public = pub
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key.Public = pub
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+
+	if key.private != nil {
+		key.Public = key.private.Public()
+	}
+
+	switch pub := key.Public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		key.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, errors.New("only RSA and Ed25519 keys are supported")
+	}
+
+	if kid == "" {
+		if kid, err = thumbprint(key); err != nil {
+			return nil, err
+		}
+	}
+	key.KID = kid
+
+	return key, nil
+}
+
+// toJWK convierte la parte pública de una clave a JWK
+func toJWK(key *JWTKey) (JWK, error) {
+	jwk := JWK{Kid: key.KID, Use: "sig", Alg: key.Method.Alg()}
+
+	switch pub := key.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	default:
+		return JWK{}, errors.New("unsupported key type")
+	}
+
+	return jwk, nil
+}
+
+// thumbprint calcula el thumbprint RFC 7638 de la clave pública
+func thumbprint(key *JWTKey) (string, error) {
+	jwk, err := toJWK(key)
+	if err != nil {
+		return "", err
+	}
+
+	// Solo los miembros requeridos, en orden lexicográfico
+	var members interface{}
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"inventory-api/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginBlockedError indica que el login está bloqueado temporalmente
+type LoginBlockedError struct {
+	Locked     bool          // true si la cuenta alcanzó el máximo de intentos
+	RetryAfter time.Duration // Tiempo hasta el próximo intento permitido
+}
+
+// Error implementa la interfaz error
+func (e *LoginBlockedError) Error() string {
+	if e.Locked {
+		return "account temporarily locked"
+	}
+	return "too many failed login attempts"
+}
+
+// loginProtectionConfig agrupa los límites configurables por entorno
+type loginProtectionConfig struct {
+	freeAttempts    int           // Intentos fallidos antes de aplicar espera
+	maxAttempts     int           // Intentos fallidos antes de bloquear la cuenta
+	lockoutDuration time.Duration // Duración del bloqueo de cuenta
+	backoffBase     time.Duration // Espera tras el primer intento penalizado
+	backoffMax      time.Duration // Espera máxima entre intentos
+	failureWindow   time.Duration // Tras este tiempo sin fallos el contador se reinicia
+	ipFreeAttempts  int
+	ipMaxAttempts   int
+	ipBlockDuration time.Duration
+}
+
+// LoginProtectionService controla los intentos fallidos por cuenta e IP
+// y registra los eventos de autenticación
+type LoginProtectionService struct {
+	db  *gorm.DB
+	cfg loginProtectionConfig
+}
+
+// NewLoginProtectionService crea una nueva instancia del servicio de protección de login
+func NewLoginProtectionService(db *gorm.DB) *LoginProtectionService {
+	return &LoginProtectionService{
+		db: db,
+		cfg: loginProtectionConfig{
+			freeAttempts:    envInt("LOGIN_FREE_ATTEMPTS", 3),
+			maxAttempts:     envInt("LOGIN_MAX_FAILED_ATTEMPTS", 10),
+			lockoutDuration: envDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			backoffBase:     envDuration("LOGIN_BACKOFF_BASE", time.Second),
+			backoffMax:      envDuration("LOGIN_BACKOFF_MAX", 5*time.Minute),
+			failureWindow:   envDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+			ipFreeAttempts:  envInt("LOGIN_IP_FREE_ATTEMPTS", 10),
+			ipMaxAttempts:   envInt("LOGIN_IP_MAX_FAILED_ATTEMPTS", 50),
+			ipBlockDuration: envDuration("LOGIN_IP_BLOCK_DURATION", 15*time.Minute),
+		},
+	}
+}
+
+// CheckIP verifica si la IP puede intentar un login
+func (lps *LoginProtectionService) CheckIP(ip string) error {
+	var throttle models.LoginThrottle
+	if err := lps.db.Where("ip = ?", ip).First(&throttle).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	now := time.Now()
+	if throttle.BlockedUntil != nil && now.Before(*throttle.BlockedUntil) {
+		return &LoginBlockedError{RetryAfter: throttle.BlockedUntil.Sub(now)}
+	}
+	return nil
+}
+
+// CheckUser verifica si la cuenta puede intentar un login
+func (lps *LoginProtectionService) CheckUser(user *models.User) error {
+	now := time.Now()
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		return &LoginBlockedError{
+			Locked:     user.FailedLogins >= lps.cfg.maxAttempts,
+			RetryAfter: user.LockedUntil.Sub(now),
+		}
+	}
+	return nil
+}
+
+// RecordFailure registra un intento fallido para la IP y, si existe, para la cuenta
+func (lps *LoginProtectionService) RecordFailure(user *models.User, email string, client models.ClientInfo, event, reason string) {
+	now := time.Now()
+
+	if err := lps.recordIPFailure(client.IP, now); err != nil {
+		log.Printf("Warning: failed to record login failure for ip %s: %v", client.IP, err)
+	}
+
+	if user != nil {
+		locked, err := lps.recordUserFailure(user, now)
+		if err != nil {
+			log.Printf("Warning: failed to record login failure for user %d: %v", user.ID, err)
+		}
+		if locked {
+			lps.LogEvent(user, email, client, models.AuthEventAccountLocked, "too many failed attempts")
+		}
+	}
+
+	lps.LogEvent(user, email, client, event, reason)
+}
+
+// RecordSuccess reinicia el contador de la cuenta y registra el login correcto
+func (lps *LoginProtectionService) RecordSuccess(user *models.User, client models.ClientInfo) {
+	if user.FailedLogins > 0 || user.LockedUntil != nil {
+		if err := resetUser(lps.db, user.ID); err != nil {
+			log.Printf("Warning: failed to reset login failures for user %d: %v", user.ID, err)
+		}
+	}
+
+	lps.LogEvent(user, user.Email, client, models.AuthEventLoginSuccess, "")
+}
+
+// UnlockUser desbloquea una cuenta manualmente (acción de administrador)
+func (lps *LoginProtectionService) UnlockUser(userID uint, client models.ClientInfo, audit models.AuditContext) error {
+	var user models.User
+	if err := lps.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	before := user.AuditFields()
+	unlocked := user
+	unlocked.LockedUntil = nil
+
+	err := lps.db.Transaction(func(tx *gorm.DB) error {
+		if err := resetUser(tx, user.ID); err != nil {
+			return err
+		}
+		return recordAudit(tx, audit, models.AuditActionUpdate, models.AuditEntityUser,
+			user.ID, 0, before, unlocked.AuditFields())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unlock user: %w", err)
+	}
+
+	lps.LogEvent(&user, user.Email, client, models.AuthEventAccountUnlocked, "unlocked by administrator")
+	return nil
+}
+
+// RecentEvents obtiene los últimos eventos de autenticación del usuario
+func (lps *LoginProtectionService) RecentEvents(userID uint, limit int) ([]models.AuthEvent, error) {
+	var events []models.AuthEvent
+	if err := lps.db.Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch auth events: %w", err)
+	}
+	return events, nil
+}
+
+// LogEvent guarda un evento de autenticación; los errores solo se registran en el log
+func (lps *LoginProtectionService) LogEvent(user *models.User, email string, client models.ClientInfo, event, reason string) {
+	record := models.AuthEvent{
+		Email:     email,
+		Event:     event,
+		Reason:    reason,
+		IP:        client.IP,
+		UserAgent: truncate(client.UserAgent, 512),
+	}
+	if user != nil {
+		record.UserID = &user.ID
+	}
+
+	if err := lps.db.Create(&record).Error; err != nil {
+		log.Printf("Warning: failed to store auth event %s: %v", event, err)
+	}
+}
+
+// recordUserFailure incrementa el contador de la cuenta y calcula la espera o el bloqueo
+// La fila se bloquea mientras se calcula: una ráfaga de intentos en paralelo cuenta cada fallo
+// Retorna true si la cuenta acaba de quedar bloqueada
+func (lps *LoginProtectionService) recordUserFailure(user *models.User, now time.Time) (bool, error) {
+	locked := false
+	err := lps.db.Transaction(func(tx *gorm.DB) error {
+		var current models.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "failed_logins", "last_failed_login").First(&current, user.ID).Error
+		if err != nil {
+			return err
+		}
+
+		failures := current.FailedLogins + 1
+		if current.LastFailedLogin != nil && now.Sub(*current.LastFailedLogin) > lps.cfg.failureWindow {
+			failures = 1
+		}
+
+		delay := backoffDelay(failures, lps.cfg.freeAttempts, lps.cfg.backoffBase, lps.cfg.backoffMax)
+		if failures >= lps.cfg.maxAttempts {
+			delay = lps.cfg.lockoutDuration
+			locked = current.FailedLogins < lps.cfg.maxAttempts
+		}
+
+		updates := map[string]interface{}{
+			"failed_logins":     failures,
+			"last_failed_login": now,
+			"locked_until":      nil,
+		}
+		if delay > 0 {
+			updates["locked_until"] = now.Add(delay)
+		}
+
+		return tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(updates).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return locked, nil
+}
+
+// recordIPFailure incrementa el contador de la IP y calcula la espera o el bloqueo
+// Como en las cuentas, la fila de la IP se bloquea mientras se calcula el nuevo contador
+func (lps *LoginProtectionService) recordIPFailure(ip string, now time.Time) error {
+	if ip == "" {
+		return nil
+	}
+
+	return lps.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.LoginThrottle{IP: ip}).Error; err != nil {
+			return err
+		}
+
+		var throttle models.LoginThrottle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("ip = ?", ip).First(&throttle).Error; err != nil {
+			return err
+		}
+
+		failures := throttle.Failures + 1
+		if !throttle.LastFailureAt.IsZero() && now.Sub(throttle.LastFailureAt) > lps.cfg.failureWindow {
+			failures = 1
+		}
+
+		delay := backoffDelay(failures, lps.cfg.ipFreeAttempts, lps.cfg.backoffBase, lps.cfg.backoffMax)
+		if failures >= lps.cfg.ipMaxAttempts {
+			delay = lps.cfg.ipBlockDuration
+		}
+
+		updates := map[string]interface{}{
+			"failures":        failures,
+			"last_failure_at": now,
+			"blocked_until":   nil,
+		}
+		if delay > 0 {
+			updates["blocked_until"] = now.Add(delay)
+		}
+
+		return tx.Model(&throttle).Updates(updates).Error
+	})
+}
+
+// resetUser borra los intentos fallidos y el bloqueo de la cuenta
+func resetUser(db *gorm.DB, userID uint) error {
+	return db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"failed_logins":     0,
+		"last_failed_login": nil,
+		"locked_until":      nil,
+	}).Error
+}
+
+// backoffDelay calcula la espera exponencial tras un número de fallos
+func backoffDelay(failures, freeAttempts int, base, max time.Duration) time.Duration {
+	if failures <= freeAttempts {
+		return 0
+	}
+
+	delay := base
+	for i := freeAttempts + 1; i < failures; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+// truncate recorta un texto a la longitud máxima indicada
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"inventory-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// recoveryCodeCount es la cantidad de códigos de recuperación generados
+const recoveryCodeCount = 10
+
+// recoveryCodeAlphabet evita caracteres ambiguos (0/O, 1/I/L)
+const recoveryCodeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+var (
+	mfaRolesOnce sync.Once
+	mfaRoles     []string
+)
+
+// MFAService maneja la lógica del segundo factor de autenticación
+type MFAService struct {
+	db *gorm.DB
+}
+
+// NewMFAService crea una nueva instancia del servicio de MFA
+func NewMFAService(db *gorm.DB) *MFAService {
+	return &MFAService{db: db}
+}
+
+// MFARequiredForRole indica si el rol debe usar segundo factor en acciones sensibles
+// Se configura con MFA_REQUIRED_ROLES (por defecto "admin")
+func MFARequiredForRole(role string) bool {
+	mfaRolesOnce.Do(func() {
+		value, ok := os.LookupEnv("MFA_REQUIRED_ROLES")
+		if !ok {
+			value = models.RoleAdmin
+		}
+		for _, r := range strings.Split(value, ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				mfaRoles = append(mfaRoles, r)
+			}
+		}
+	})
+	return models.HasPermission(mfaRoles, role)
+}
+
+// SetupMFA genera un secreto TOTP pendiente de confirmación
+func (ms *MFAService) SetupMFA(userID uint) (*models.MFASetupResponse, error) {
+	user, err := ms.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	if err := ms.db.Model(user).Updates(map[string]interface{}{
+		"mfa_secret":    secret,
+		"mfa_last_step": 0,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to store mfa secret: %w", err)
+	}
+
+	return &models.MFASetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totpProvisioningURI(secret, user.Email),
+	}, nil
+}
+
+// EnableMFA confirma el secreto pendiente con un código y genera códigos de recuperación
+func (ms *MFAService) EnableMFA(userID uint, code string, audit models.AuditContext) ([]string, error) {
+	user, err := ms.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.MFASecret == "" {
+		return nil, ErrMFASetupNotStarted
+	}
+
+	step, ok := validateTOTP(user.MFASecret, code, time.Now(), user.MFALastStep)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	before := user.AuditFields()
+	after := *user
+	after.MFAEnabled = true
+
+	var codes []string
+	err = ms.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"mfa_enabled":   true,
+			"mfa_last_step": step,
+		}).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(tx, audit, models.AuditActionUpdate, models.AuditEntityUser,
+			user.ID, 0, before, after.AuditFields()); err != nil {
+			return err
+		}
+
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable mfa: %w", err)
+	}
+
+	return codes, nil
+}
+
+// DisableMFA desactiva el segundo factor tras verificar un código
+func (ms *MFAService) DisableMFA(userID uint, code string, audit models.AuditContext) error {
+	user, err := ms.getUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.MFAEnabled {
+		return ErrMFANotEnabled
+	}
+
+	ok, err := ms.VerifyCode(user, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	before := user.AuditFields()
+	after := *user
+	after.MFAEnabled = false
+
+	return ms.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"mfa_enabled":   false,
+			"mfa_secret":    "",
+			"mfa_last_step": 0,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to disable mfa: %w", err)
+		}
+		if err := recordAudit(tx, audit, models.AuditActionUpdate, models.AuditEntityUser,
+			user.ID, 0, before, after.AuditFields()); err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+		return nil
+	})
+}
+
+// RegenerateRecoveryCodes invalida los códigos anteriores y genera nuevos
+func (ms *MFAService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	user, err := ms.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.MFAEnabled {
+		return nil, ErrMFANotEnabled
+	}
+
+	ok, err := ms.VerifyCode(user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	var codes []string
+	err = ms.db.Transaction(func(tx *gorm.DB) error {
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to regenerate recovery codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+// VerifyCode valida un código TOTP o consume un código de recuperación
+func (ms *MFAService) VerifyCode(user *models.User, code string) (bool, error) {
+	if !user.MFAEnabled {
+		return false, nil
+	}
+
+	// Código TOTP
+	if step, ok := validateTOTP(user.MFASecret, code, time.Now(), user.MFALastStep); ok {
+		// La condición evita que dos peticiones concurrentes acepten el mismo código
+		result := ms.db.Model(&models.User{}).
+			Where("id = ? AND mfa_last_step < ?", user.ID, step).
+			UpdateColumn("mfa_last_step", step)
+		if result.Error != nil {
+			return false, fmt.Errorf("database error: %w", result.Error)
+		}
+		user.MFALastStep = step
+		return result.RowsAffected == 1, nil
+	}
+
+	// Código de recuperación (de un solo uso)
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return false, nil
+	}
+
+	result := ms.db.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashRecoveryCode(normalized)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("database error: %w", result.Error)
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// getUser obtiene el usuario por ID
+func (ms *MFAService) getUser(userID uint) (*models.User, error) {
+	var user models.User
+	if err := ms.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &user, nil
+}
+
+// replaceRecoveryCodes borra los códigos del usuario y guarda unos nuevos
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.MFARecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, models.MFARecoveryCode{
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(normalizeRecoveryCode(code)),
+		})
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// generateRecoveryCode genera un código con formato XXXXX-XXXXX
+func generateRecoveryCode() (string, error) {
+	// Se descartan los bytes que introducirían sesgo al aplicar el módulo
+	limit := byte(256 - 256%len(recoveryCodeAlphabet))
+
+	var sb strings.Builder
+	buf := make([]byte, 1)
+	for n := 0; n < 10; {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		if buf[0] >= limit {
+			continue
+		}
+		if n == 5 {
+			sb.WriteByte('-')
+		}
+		sb.WriteByte(recoveryCodeAlphabet[int(buf[0])%len(recoveryCodeAlphabet)])
+		n++
+	}
+	return sb.String(), nil
+}
+
+// normalizeRecoveryCode elimina separadores y pasa a mayúsculas
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// hashRecoveryCode calcula el hash almacenado de un código de recuperación
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,592 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"inventory-api/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// Tiempos del flujo OIDC
+const (
+	OIDCStateTTL        = 10 * time.Minute // También la vida de la cookie que liga el state al navegador
+	oidcHTTPTimeout     = 10 * time.Second
+	oidcJWKSMinRefresh  = time.Minute // Evita recargar el JWKS en cada kid desconocido
+	oidcDiscoveryMaxAge = time.Hour
+)
+
+// OIDCConfig define la configuración del proveedor de identidad
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string // Opcional para clientes públicos (solo PKCE)
+	RedirectURL  string
+	Scopes       []string
+	DefaultRole  string
+}
+
+// oidcDiscovery contiene los campos usados del documento de descubrimiento
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCClaims representa las claims usadas del ID token
+type OIDCClaims struct {
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+	Nonce         string   `json:"nonce"`
+	AZP           string   `json:"azp"`
+	AMR           []string `json:"amr"`
+	jwt.RegisteredClaims
+}
+
+// oidcProvider cachea el descubrimiento y las claves del proveedor
+type oidcProvider struct {
+	cfg    OIDCConfig
+	client *http.Client
+
+	mu          sync.Mutex
+	discovery   *oidcDiscovery
+	discoveryAt time.Time
+	keys        map[string]interface{}
+	keysAt      time.Time
+}
+
+var (
+	oidcProviderOnce sync.Once
+	oidcProviderInst *oidcProvider
+)
+
+// OIDCService maneja el login con un proveedor OpenID Connect externo
+type OIDCService struct {
+	db       *gorm.DB
+	provider *oidcProvider
+}
+
+// NewOIDCService crea una nueva instancia del servicio OIDC
+// El proveedor se comparte entre instancias para reutilizar la caché de claves
+func NewOIDCService(db *gorm.DB) *OIDCService {
+	oidcProviderOnce.Do(func() {
+		cfg, ok := oidcConfigFromEnv()
+		if ok {
+			oidcProviderInst = newOIDCProvider(cfg)
+		}
+	})
+	return &OIDCService{db: db, provider: oidcProviderInst}
+}
+
+// newOIDCProvider crea un proveedor con la configuración indicada
+func newOIDCProvider(cfg OIDCConfig) *oidcProvider {
+	return &oidcProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: oidcHTTPTimeout},
+	}
+}
+
+// oidcConfigFromEnv lee la configuración OIDC; retorna false si no está habilitado
+func oidcConfigFromEnv() (OIDCConfig, bool) {
+	cfg := OIDCConfig{
+		IssuerURL:    strings.TrimRight(os.Getenv("OIDC_ISSUER_URL"), "/"),
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:       strings.Fields(os.Getenv("OIDC_SCOPES")),
+		DefaultRole:  os.Getenv("OIDC_DEFAULT_ROLE"),
+	}
+	if cfg.IssuerURL == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return cfg, false
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	if !models.IsValidRole(cfg.DefaultRole) {
+		cfg.DefaultRole = models.RoleUser
+	}
+	return cfg, true
+}
+
+// Enabled indica si el login OIDC está configurado
+func (oidcs *OIDCService) Enabled() bool {
+	return oidcs.provider != nil
+}
+
+// AuthorizationURL inicia el flujo: guarda state, nonce y code_verifier y retorna la URL del
+// proveedor y el state, que el controlador liga al navegador que inicia el login
+func (oidcs *OIDCService) AuthorizationURL() (string, string, error) {
+	if !oidcs.Enabled() {
+		return "", "", ErrOIDCNotConfigured
+	}
+
+	discovery, err := oidcs.provider.getDiscovery()
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := randomURLToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomURLToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := randomURLToken(48) // 64 caracteres (RFC 7636: entre 43 y 128)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Limpiar estados caducados de logins abandonados
+	oidcs.db.Where("expires_at < ?", time.Now()).Delete(&models.OIDCLoginState{})
+
+	record := models.OIDCLoginState{
+		StateHash:    sha256Hex(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(OIDCStateTTL),
+	}
+	if err := oidcs.db.Create(&record).Error; err != nil {
+		return "", "", fmt.Errorf("failed to store oidc state: %w", err)
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", oidcs.provider.cfg.ClientID)
+	params.Set("redirect_uri", oidcs.provider.cfg.RedirectURL)
+	params.Set("scope", strings.Join(oidcs.provider.cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + params.Encode(), state, nil
+}
+
+// HandleCallback canjea el código, valida el ID token y retorna el JWT propio de la API
+func (oidcs *OIDCService) HandleCallback(code, state string, client models.ClientInfo) (string, *models.UserResponse, error) {
+	if !oidcs.Enabled() {
+		return "", nil, ErrOIDCNotConfigured
+	}
+
+	// Consumir el state (un solo uso)
+	var loginState models.OIDCLoginState
+	err := oidcs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("state_hash = ? AND expires_at > ?", sha256Hex(state), time.Now()).First(&loginState).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&loginState).Error
+	})
+	if err != nil {
+		return "", nil, ErrInvalidOIDCState
+	}
+
+	rawIDToken, err := oidcs.provider.exchangeCode(code, loginState.CodeVerifier)
+	if err != nil {
+		return "", nil, err
+	}
+
+	claims, err := oidcs.provider.verifyIDToken(rawIDToken, loginState.Nonce)
+	if err != nil {
+		return "", nil, err
+	}
+
+	user, err := oidcs.findOrProvisionUser(claims, client)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// La autenticación fuerte del proveedor cuenta como segundo factor
+	mfaVerified := false
+	for _, method := range claims.AMR {
+		if method == "mfa" || method == "otp" || method == "hwk" || method == "swk" {
+			mfaVerified = true
+		}
+	}
+
+	authService := NewAuthService(oidcs.db)
+	token, err := authService.GenerateJWT(user, mfaVerified)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	authService.protection.RecordSuccess(user, client)
+	response := user.ToResponse()
+	return token, &response, nil
+}
+
+// findOrProvisionUser asocia la identidad externa a un usuario, creándolo si no existe
+func (oidcs *OIDCService) findOrProvisionUser(claims *OIDCClaims, client models.ClientInfo) (*models.User, error) {
+	issuer := claims.Issuer
+	subject := claims.Subject
+
+	// 1. Usuario ya vinculado a esta identidad
+	var user models.User
+	err := oidcs.db.Where("oidc_issuer = ? AND oidc_subject = ?", issuer, subject).First(&user).Error
+	if err == nil {
+		return &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if claims.Email == "" {
+		return nil, ErrOIDCNoEmail
+	}
+
+	// 2. Usuario local con el mismo email: solo se vincula si el proveedor lo verificó
+	err = oidcs.db.Where("email = ?", claims.Email).First(&user).Error
+	if err == nil {
+		if !claims.EmailVerified {
+			return nil, ErrOIDCEmailNotVerified
+		}
+		if user.OIDCSubject != nil {
+			return nil, ErrOIDCAccountLinked
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"oidc_issuer":  issuer,
+			"oidc_subject": subject,
+		}
+		if !user.EmailVerified {
+			updates["email_verified"] = true
+			updates["email_verified_at"] = now
+		}
+
+		before := user.AuditFields()
+		err := oidcs.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&user).Updates(updates).Error; err != nil {
+				return err
+			}
+			user.OIDCIssuer = &issuer
+			user.OIDCSubject = &subject
+			user.EmailVerified = true
+			return recordAudit(tx, client.Audit().WithActor(&user), models.AuditActionUpdate,
+				models.AuditEntityUser, user.ID, 0, before, user.AuditFields())
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to link identity: %w", err)
+		}
+		return &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	// 3. Alta automática con el rol por defecto, sin contraseña local y con organización propia
+	user = models.User{
+		Email:         claims.Email,
+		Role:          oidcs.provider.cfg.DefaultRole,
+		OIDCIssuer:    &issuer,
+		OIDCSubject:   &subject,
+		EmailVerified: claims.EmailVerified,
+	}
+	if claims.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	err = oidcs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		if err := recordAudit(tx, client.Audit().WithActor(&user), models.AuditActionCreate,
+			models.AuditEntityUser, user.ID, 0, nil, user.AuditFields()); err != nil {
+			return err
+		}
+		_, err := CreatePersonalOrganization(tx, &user)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// getDiscovery obtiene (y cachea) el documento .well-known/openid-configuration
+func (p *oidcProvider) getDiscovery() (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil && time.Since(p.discoveryAt) < oidcDiscoveryMaxAge {
+		return p.discovery, nil
+	}
+
+	var doc oidcDiscovery
+	if err := p.getJSON(p.cfg.IssuerURL+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, fmt.Errorf("oidc discovery failed: %w", err)
+	}
+
+	// El issuer anunciado debe coincidir con el configurado (OIDC Discovery §4.3)
+	if strings.TrimRight(doc.Issuer, "/") != p.cfg.IssuerURL {
+		return nil, fmt.Errorf("oidc discovery failed: issuer mismatch %q", doc.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("oidc discovery failed: incomplete provider metadata")
+	}
+
+	p.discovery = &doc
+	p.discoveryAt = time.Now()
+	return p.discovery, nil
+}
+
+// exchangeCode canjea el código de autorización por tokens y retorna el ID token
+func (p *oidcProvider) exchangeCode(code, verifier string) (string, error) {
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", p.cfg.ClientID)
+
+	req, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("oidc token exchange failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("oidc token exchange failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || body.Error != "" {
+		return "", fmt.Errorf("oidc token exchange failed: %s %s", body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return "", errors.New("oidc token exchange failed: no id_token in response")
+	}
+
+	return body.IDToken, nil
+}
+
+// verifyIDToken valida firma, issuer, audiencia, expiración y nonce del ID token
+func (p *oidcProvider) verifyIDToken(rawIDToken, nonce string) (*OIDCClaims, error) {
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &OIDCClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.getKey(discovery.JWKSURI, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384", "EdDSA"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+
+	if claims.Nonce != nonce {
+		return nil, errors.New("invalid id token: nonce mismatch")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("invalid id token: missing subject")
+	}
+	// Con varias audiencias, azp debe identificar a este cliente (OIDC Core §3.1.3.7)
+	if len(claims.Audience) > 1 && claims.AZP != p.cfg.ClientID {
+		return nil, errors.New("invalid id token: authorized party mismatch")
+	}
+
+	return claims, nil
+}
+
+// getKey obtiene la clave pública por kid, recargando el JWKS si es necesario
+func (p *oidcProvider) getKey(jwksURI, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	if p.keys != nil && time.Since(p.keysAt) < oidcJWKSMinRefresh {
+		return nil, fmt.Errorf("unknown key id: %q", kid)
+	}
+
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := p.getJSON(jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch provider keys: %w", err)
+	}
+
+	keys := make(map[string]interface{})
+	for _, raw := range set.Keys {
+		kid, key, err := parseProviderJWK(raw)
+		if err == nil {
+			keys[kid] = key
+		}
+	}
+	p.keys = keys
+	p.keysAt = time.Now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key id: %q", kid)
+}
+
+// lookupKey busca una clave en la caché; sin kid solo es válido si hay una única clave
+func (p *oidcProvider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// getJSON descarga y decodifica un documento JSON
+func (p *oidcProvider) getJSON(rawURL string, target interface{}) error {
+	resp, err := p.client.Get(rawURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, rawURL)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(target)
+}
+
+// parseProviderJWK convierte una JWK de firma (RSA, EC u OKP) en clave pública
+func parseProviderJWK(raw json.RawMessage) (string, interface{}, error) {
+	var jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		Crv string `json:"crv"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+	if err := json.Unmarshal(raw, &jwk); err != nil {
+		return "", nil, err
+	}
+	if jwk.Use != "" && jwk.Use != "sig" {
+		return "", nil, errors.New("not a signing key")
+	}
+
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return "", nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return "", nil, err
+		}
+		return jwk.Kid, &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return "", nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return "", nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return "", nil, err
+		}
+		return jwk.Kid, &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return "", nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return "", nil, errors.New("invalid Ed25519 key")
+		}
+		return jwk.Kid, ed25519.PublicKey(x), nil
+	}
+
+	return "", nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+}
+
+// randomURLToken genera un valor aleatorio codificado en base64url
+func randomURLToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// sha256Hex calcula el hash SHA-256 en hexadecimal
+func sha256Hex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
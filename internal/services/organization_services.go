@@ -0,0 +1,316 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"inventory-api/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// slugInvalidChars coincide con los caracteres no permitidos en un slug
+var slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// accentFolder sustituye las letras con tilde por su versión sin tilde (en minúsculas)
+var accentFolder = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u", "ç", "c",
+)
+
+// OrganizationService maneja las organizaciones (tenants) y sus miembros
+type OrganizationService struct {
+	db *gorm.DB
+}
+
+// NewOrganizationService crea una nueva instancia del servicio de organizaciones
+func NewOrganizationService(db *gorm.DB) *OrganizationService {
+	return &OrganizationService{db: db}
+}
+
+// CreateOrganization crea una organización con el usuario como propietario
+func (ogs *OrganizationService) CreateOrganization(userID uint, req models.OrganizationRequest) (*models.OrganizationResponse, error) {
+	slug := req.Slug
+	if slug == "" {
+		slug = slugify(req.Name)
+	}
+	if slug == "" || slug != slugify(slug) {
+		return nil, ErrInvalidOrganizationSlug
+	}
+
+	var org *models.Organization
+	err := ogs.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Organization{}).Where("slug = ?", slug).Count(&count).Error; err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		if count > 0 {
+			return ErrOrganizationSlugExists
+		}
+
+		var err error
+		org, err = createOrganization(tx, req.Name, slug, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := org.ToResponse(models.OrgRoleOwner)
+	return &response, nil
+}
+
+// CreatePersonalOrganization crea la organización inicial de un usuario recién registrado
+func CreatePersonalOrganization(tx *gorm.DB, user *models.User) (*models.Organization, error) {
+	local, _, _ := strings.Cut(user.Email, "@")
+
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+
+	base := slugify(local)
+	if base == "" {
+		base = "org"
+	}
+	if len(base) > 50 {
+		base = base[:50]
+	}
+
+	return createOrganization(tx, local+"'s organization", base+"-"+hex.EncodeToString(suffix), user.ID)
+}
+
+// createOrganization inserta la organización y la membresía del propietario
+func createOrganization(tx *gorm.DB, name, slug string, ownerID uint) (*models.Organization, error) {
+	org := models.Organization{Name: name, Slug: slug}
+	if err := tx.Create(&org).Error; err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	membership := models.Membership{
+		OrganizationID: org.ID,
+		UserID:         ownerID,
+		Role:           models.OrgRoleOwner,
+	}
+	if err := tx.Create(&membership).Error; err != nil {
+		return nil, fmt.Errorf("failed to create membership: %w", err)
+	}
+
+	return &org, nil
+}
+
+// ListOrganizations obtiene las organizaciones a las que pertenece el usuario
+func (ogs *OrganizationService) ListOrganizations(userID uint) ([]models.OrganizationResponse, error) {
+	var rows []struct {
+		models.Organization
+		Role string
+	}
+	err := ogs.db.Table("organizations").
+		Select("organizations.*, memberships.role").
+		Joins("JOIN memberships ON memberships.organization_id = organizations.id").
+		Where("memberships.user_id = ?", userID).
+		Order("memberships.created_at ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch organizations: %w", err)
+	}
+
+	responses := make([]models.OrganizationResponse, 0, len(rows))
+	for _, row := range rows {
+		responses = append(responses, row.Organization.ToResponse(row.Role))
+	}
+
+	return responses, nil
+}
+
+// GetMembership obtiene la membresía del usuario en la organización
+func (ogs *OrganizationService) GetMembership(orgID, userID uint) (*models.Membership, error) {
+	var membership models.Membership
+	if err := ogs.db.Where("organization_id = ? AND user_id = ?", orgID, userID).First(&membership).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMembershipNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &membership, nil
+}
+
+// DefaultOrganizationID retorna la organización más antigua del usuario (0 si no tiene ninguna)
+func (ogs *OrganizationService) DefaultOrganizationID(userID uint) (uint, error) {
+	var membership models.Membership
+	err := ogs.db.Where("user_id = ?", userID).Order("created_at ASC, id ASC").First(&membership).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	return membership.OrganizationID, nil
+}
+
+// ListMembers obtiene los miembros de la organización
+func (ogs *OrganizationService) ListMembers(orgID uint) ([]models.MemberResponse, error) {
+	var members []models.MemberResponse
+	err := ogs.db.Table("memberships").
+		Select("memberships.user_id, users.email, memberships.role, memberships.created_at").
+		Joins("JOIN users ON users.id = memberships.user_id").
+		Where("memberships.organization_id = ?", orgID).
+		Order("memberships.created_at ASC").
+		Scan(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch members: %w", err)
+	}
+	return members, nil
+}
+
+// AddMember añade un usuario existente a la organización
+// Solo un propietario puede añadir otros propietarios
+func (ogs *OrganizationService) AddMember(orgID uint, actorRole string, req models.MemberRequest) (*models.MemberResponse, error) {
+	if !models.IsValidOrgRole(req.Role) {
+		return nil, ErrInvalidRole
+	}
+	if req.Role == models.OrgRoleOwner && actorRole != models.OrgRoleOwner {
+		return nil, ErrOwnerRequired
+	}
+
+	var user models.User
+	if err := ogs.db.Where("email = ?", req.Email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if _, err := ogs.GetMembership(orgID, user.ID); err == nil {
+		return nil, ErrAlreadyMember
+	}
+
+	membership := models.Membership{OrganizationID: orgID, UserID: user.ID, Role: req.Role}
+	if err := ogs.db.Create(&membership).Error; err != nil {
+		return nil, fmt.Errorf("failed to add member: %w", err)
+	}
+
+	return &models.MemberResponse{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      membership.Role,
+		CreatedAt: membership.CreatedAt,
+	}, nil
+}
+
+// UpdateMemberRole cambia el rol de un miembro de la organización
+func (ogs *OrganizationService) UpdateMemberRole(orgID uint, actorRole string, userID uint, role string) error {
+	if !models.IsValidOrgRole(role) {
+		return ErrInvalidRole
+	}
+
+	return ogs.db.Transaction(func(tx *gorm.DB) error {
+		membership, err := ogs.lockMembership(tx, orgID, userID)
+		if err != nil {
+			return err
+		}
+
+		if (membership.Role == models.OrgRoleOwner || role == models.OrgRoleOwner) && actorRole != models.OrgRoleOwner {
+			return ErrOwnerRequired
+		}
+		if membership.Role == models.OrgRoleOwner && role != models.OrgRoleOwner {
+			if err := ensureAnotherOwner(tx, orgID, userID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(membership).Update("role", role).Error; err != nil {
+			return fmt.Errorf("failed to update member: %w", err)
+		}
+		return nil
+	})
+}
+
+// RemoveMember quita a un usuario de la organización
+func (ogs *OrganizationService) RemoveMember(orgID uint, actorRole string, userID uint) error {
+	return ogs.db.Transaction(func(tx *gorm.DB) error {
+		membership, err := ogs.lockMembership(tx, orgID, userID)
+		if err != nil {
+			return err
+		}
+
+		if membership.Role == models.OrgRoleOwner {
+			if actorRole != models.OrgRoleOwner {
+				return ErrOwnerRequired
+			}
+			if err := ensureAnotherOwner(tx, orgID, userID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Delete(membership).Error; err != nil {
+			return fmt.Errorf("failed to remove member: %w", err)
+		}
+
+		// Las API keys del usuario en esta organización dejan de ser válidas
+		if err := tx.Model(&models.APIKey{}).
+			Where("organization_id = ? AND user_id = ? AND revoked_at IS NULL", orgID, userID).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return fmt.Errorf("failed to revoke api keys: %w", err)
+		}
+		return nil
+	})
+}
+
+// lockMembership obtiene la membresía bloqueando la organización hasta el final de la transacción
+// Así dos cambios concurrentes no pueden dejar la organización sin propietarios
+func (ogs *OrganizationService) lockMembership(tx *gorm.DB, orgID, userID uint) (*models.Membership, error) {
+	var org models.Organization
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&org, orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	var membership models.Membership
+	err := tx.Where("organization_id = ? AND user_id = ?", orgID, userID).First(&membership).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMembershipNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &membership, nil
+}
+
+// ensureAnotherOwner verifica que la organización no se quede sin propietarios
+func ensureAnotherOwner(tx *gorm.DB, orgID, userID uint) error {
+	var owners int64
+	err := tx.Model(&models.Membership{}).
+		Where("organization_id = ? AND role = ? AND user_id <> ?", orgID, models.OrgRoleOwner, userID).
+		Count(&owners).Error
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if owners == 0 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// slugify convierte un nombre en un identificador apto para URLs
+func slugify(name string) string {
+	slug := slugInvalidChars.ReplaceAllString(foldAccents(strings.ToLower(name)), "-")
+	slug = strings.Trim(slug, "-")
+	if len(slug) > 64 {
+		slug = strings.TrimRight(slug[:64], "-")
+	}
+	return slug
+}
+
+// foldAccents quita las tildes de un texto en minúsculas ("electrónica" → "electronica")
+func foldAccents(text string) string {
+	return accentFolder.Replace(text)
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"bufio"
+	_ "embed"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"inventory-api/internal/i18n"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+// PasswordPolicy define los requisitos de las contraseñas nuevas
+type PasswordPolicy struct {
+	MinLength     int  `json:"min_length"`
+	MaxLength     int  `json:"max_length"`
+	RequireUpper  bool `json:"require_upper"`
+	RequireLower  bool `json:"require_lower"`
+	RequireDigit  bool `json:"require_digit"`
+	RequireSymbol bool `json:"require_symbol"`
+	RejectCommon  bool `json:"reject_common"`
+}
+
+// PasswordPolicyError indica que una contraseña no cumple la política
+// Cada infracción es un mensaje del catálogo de i18n que sigue a "password"
+type PasswordPolicyError struct {
+	Violations []i18n.Message
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "password does not meet the password policy: " + strings.Join(e.Messages(i18n.English), "; ")
+}
+
+// Messages retorna las infracciones en el idioma indicado
+func (e *PasswordPolicyError) Messages(lang i18n.Language) []string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.In(lang)
+	}
+	return messages
+}
+
+var (
+	passwordPolicyOnce sync.Once
+	passwordPolicy     PasswordPolicy
+
+	commonPasswordsOnce sync.Once
+	commonPasswords     map[string]struct{}
+)
+
+// LoadPasswordPolicy lee la política de contraseñas una sola vez desde las variables de entorno
+//
+// Variables soportadas:
+//   - PASSWORD_MIN_LENGTH / PASSWORD_MAX_LENGTH: longitud en caracteres (por defecto 8 y 128)
+//   - PASSWORD_REQUIRE_UPPER, PASSWORD_REQUIRE_LOWER, PASSWORD_REQUIRE_DIGIT,
+//     PASSWORD_REQUIRE_SYMBOL: clases de caracteres obligatorias (por defecto false)
+//   - PASSWORD_REJECT_COMMON: rechazar contraseñas de la lista incluida (por defecto true)
+//   - PASSWORD_BLOCKLIST_FILE: fichero opcional con contraseñas adicionales a rechazar
+func LoadPasswordPolicy() PasswordPolicy {
+	passwordPolicyOnce.Do(func() {
+		passwordPolicy = PasswordPolicy{
+			MinLength:     envInt("PASSWORD_MIN_LENGTH", 8),
+			MaxLength:     envInt("PASSWORD_MAX_LENGTH", 128),
+			RequireUpper:  envBool("PASSWORD_REQUIRE_UPPER", false),
+			RequireLower:  envBool("PASSWORD_REQUIRE_LOWER", false),
+			RequireDigit:  envBool("PASSWORD_REQUIRE_DIGIT", false),
+			RequireSymbol: envBool("PASSWORD_REQUIRE_SYMBOL", false),
+			RejectCommon:  envBool("PASSWORD_REJECT_COMMON", true),
+		}
+		if passwordPolicy.MinLength < 1 {
+			passwordPolicy.MinLength = 1
+		}
+		if passwordPolicy.MaxLength < passwordPolicy.MinLength {
+			passwordPolicy.MaxLength = passwordPolicy.MinLength
+		}
+	})
+	return passwordPolicy
+}
+
+// Validate comprueba la contraseña contra la política y retorna todas las infracciones
+func (p PasswordPolicy) Validate(password, email string) error {
+	var violations []i18n.Message
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		violations = append(violations, i18n.Msg("password.min_length", p.MinLength))
+	}
+	if length > p.MaxLength {
+		violations = append(violations, i18n.Msg("password.max_length", p.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, i18n.Msg("password.upper"))
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, i18n.Msg("password.lower"))
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, i18n.Msg("password.digit"))
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, i18n.Msg("password.symbol"))
+	}
+
+	// La contraseña no puede ser el email ni su parte local
+	normalized := strings.ToLower(strings.TrimSpace(password))
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email != "" {
+		local, _, _ := strings.Cut(email, "@")
+		if normalized == email || normalized == local {
+			violations = append(violations, i18n.Msg("password.email"))
+		}
+	}
+
+	if p.RejectCommon && isCommonPassword(normalized) {
+		violations = append(violations, i18n.Msg("password.common"))
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// isCommonPassword indica si la contraseña está en la lista de contraseñas comunes
+func isCommonPassword(password string) bool {
+	commonPasswordsOnce.Do(func() {
+		commonPasswords = make(map[string]struct{})
+
+		// La lista se puede ampliar con un fichero propio (una contraseña por línea)
+		sources := []string{commonPasswordsFile}
+		if path := os.Getenv("PASSWORD_BLOCKLIST_FILE"); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				log.Printf("Warning: failed to read PASSWORD_BLOCKLIST_FILE: %v", err)
+			} else {
+				sources = append(sources, string(data))
+			}
+		}
+
+		for _, source := range sources {
+			scanner := bufio.NewScanner(strings.NewReader(source))
+			for scanner.Scan() {
+				line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+				if line == "" || strings.HasPrefix(line, "#") {
+					continue
+				}
+				commonPasswords[line] = struct{}{}
+			}
+		}
+	})
+
+	_, found := commonPasswords[password]
+	return found
+}
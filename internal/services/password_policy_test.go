@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"inventory-api/internal/i18n"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	strict := PasswordPolicy{MinLength: 10, MaxLength: 20, RequireUpper: true, RequireLower: true,
+		RequireDigit: true, RequireSymbol: true, RejectCommon: true}
+
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		email    string
+		want     []string // Códigos de las infracciones, en orden
+	}{
+		{"valid", strict, "Tornillo-42x", "ana@example.com", nil},
+		{"length counts characters, not bytes", PasswordPolicy{MinLength: 4, MaxLength: 4}, "ññññ", "", nil},
+		{"too short", strict, "Ab1-", "", []string{"password.min_length"}},
+		{"too long", strict, "Ab1-" + strings.Repeat("x", 17), "", []string{"password.max_length"}},
+		{"missing classes", strict, "abcdefghijk", "", []string{"password.upper", "password.digit", "password.symbol"}},
+		{"space counts as a symbol", strict, "Abc def 1234", "", nil},
+		{"same as the email", PasswordPolicy{MinLength: 1, MaxLength: 100}, " Ana@Example.com", "ana@example.com", []string{"password.email"}},
+		{"same as the email local part", PasswordPolicy{MinLength: 1, MaxLength: 100}, "ANA", "ana@example.com", []string{"password.email"}},
+		{"common password", PasswordPolicy{MinLength: 1, MaxLength: 100, RejectCommon: true}, "Password", "", []string{"password.common"}},
+		{"common passwords allowed", PasswordPolicy{MinLength: 1, MaxLength: 100}, "password", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.password, tt.email)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var policyErr *PasswordPolicyError
+			if !errors.As(err, &policyErr) {
+				t.Fatalf("got %v, want a PasswordPolicyError", err)
+			}
+			var got []string
+			for _, violation := range policyErr.Violations {
+				got = append(got, violation.Key)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got violations %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyErrorMessages(t *testing.T) {
+	err := PasswordPolicy{MinLength: 12, MaxLength: 100}.Validate("short", "")
+	var policyErr *PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("got %v, want a PasswordPolicyError", err)
+	}
+	if got, want := policyErr.Messages(i18n.English), []string{"must be at least 12 characters long"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := policyErr.Messages(i18n.Spanish); got[0] == policyErr.Messages(i18n.English)[0] || !strings.Contains(got[0], "12") {
+		t.Errorf("Spanish message %q is not translated", got[0])
+	}
+}
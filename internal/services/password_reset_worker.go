@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Máximo de solicitudes de recuperación pendientes; con la cola llena se descartan
+var passwordResetQueueSize = envInt("PASSWORD_RESET_QUEUE_SIZE", 100)
+
+// ErrPasswordResetUnavailable indica que la solicitud no se pudo encolar (worker parado o cola llena)
+var ErrPasswordResetUnavailable = errors.New("password reset queue unavailable")
+
+// passwordResetWorker procesa en segundo plano las solicitudes de recuperación de contraseña
+// Se arranca con StartPasswordResetWorker y se detiene con StopPasswordResetWorker, que espera
+// a que terminen los envíos en curso
+type passwordResetWorker struct {
+	mu    sync.RWMutex
+	queue chan string
+	done  chan struct{}
+}
+
+var passwordResets passwordResetWorker
+
+// StartPasswordResetWorker arranca el worker que busca la cuenta, emite el token y envía el correo
+func StartPasswordResetWorker(db *gorm.DB) {
+	passwordResets.mu.Lock()
+	defer passwordResets.mu.Unlock()
+	if passwordResets.queue != nil {
+		return
+	}
+
+	size := passwordResetQueueSize
+	if size < 1 {
+		size = 1
+	}
+	queue := make(chan string, size)
+	done := make(chan struct{})
+	passwordResets.queue, passwordResets.done = queue, done
+
+	accounts := NewAccountService(db)
+	go func() {
+		defer close(done)
+		for email := range queue {
+			if err := accounts.processPasswordReset(email); err != nil {
+				log.Printf("Warning: password reset request failed: %v", err)
+			}
+		}
+	}()
+}
+
+// StopPasswordResetWorker deja de aceptar solicitudes y espera a que se procesen las pendientes
+// o a que venza ctx
+func StopPasswordResetWorker(ctx context.Context) error {
+	passwordResets.mu.Lock()
+	queue, done := passwordResets.queue, passwordResets.done
+	passwordResets.queue = nil
+	passwordResets.mu.Unlock()
+	if queue == nil {
+		return nil
+	}
+
+	close(queue)
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enqueue añade una solicitud sin bloquear la petición
+func (w *passwordResetWorker) enqueue(email string) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.queue == nil {
+		return ErrPasswordResetUnavailable
+	}
+	select {
+	case w.queue <- email:
+		return nil
+	default:
+		return ErrPasswordResetUnavailable
+	}
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"errors"
+	"math"
+
+	"inventory-api/internal/i18n"
+	"inventory-api/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BulkProducts ejecuta un lote de operaciones sobre productos en una transacción
+// Cada operación se aplica en su propio savepoint y se informa de su resultado; en modo atomic
+// (y en las simulaciones) la transacción se deshace si alguna operación falla
+func (ps *ProductService) BulkProducts(req models.ProductBulkRequest) (*models.ProductBulkResponse, error) {
+	if ps.orgID == 0 {
+		return nil, ErrOrganizationRequired
+	}
+	if req.Mode == "" {
+		req.Mode = models.BulkModeAtomic
+	}
+	if req.Mode != models.BulkModeAtomic && req.Mode != models.BulkModePerItem {
+		return nil, ErrInvalidBulkMode
+	}
+	if len(req.Operations) == 0 {
+		return nil, ErrNoOperations
+	}
+	if len(req.Operations) > models.MaxBulkOperations {
+		return nil, ErrTooManyOperations
+	}
+
+	response := &models.ProductBulkResponse{
+		Mode:    req.Mode,
+		DryRun:  req.DryRun,
+		Results: make([]models.ProductBulkResult, 0, len(req.Operations)),
+	}
+
+	err := ps.transaction(func(scoped *ProductService) error {
+		for i, op := range req.Operations {
+			result := models.ProductBulkResult{Index: i, Op: op.Op, ID: op.ID, Status: "ok"}
+
+			var product *models.ProductResponse
+			err := scoped.transaction(func(item *ProductService) error {
+				var err error
+				product, err = item.bulkOperation(op)
+				return err
+			})
+			if err != nil {
+				field, reason, ok := productInputError(err)
+				if !ok {
+					return err
+				}
+				result.Status, result.Field, result.Error = "error", field, reason.In(scoped.lang)
+				response.Failed++
+			} else {
+				if product != nil {
+					result.ID, result.Product = product.ID, product
+				}
+				response.Succeeded++
+			}
+			response.Results = append(response.Results, result)
+		}
+
+		if req.DryRun || (req.Mode == models.BulkModeAtomic && response.Failed > 0) {
+			return errRolledBack
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRolledBack) {
+		return nil, err
+	}
+
+	response.Committed = err == nil
+	return response, nil
+}
+
+// bulkOperation aplica una operación del lote con las mismas reglas que su endpoint individual
+func (ps *ProductService) bulkOperation(op models.ProductBulkOperation) (*models.ProductResponse, error) {
+	if op.Op != models.BulkOpCreate && op.ID == 0 {
+		return nil, &productFieldError{field: "id", reason: i18n.Msg("validation.required")}
+	}
+
+	switch op.Op {
+	case models.BulkOpCreate, models.BulkOpUpdate:
+		if op.Product == nil {
+			return nil, &productFieldError{field: "product", reason: i18n.Msg("validation.required")}
+		}
+		if err := checkProductRequest(*op.Product); err != nil {
+			return nil, err
+		}
+		if op.Op == models.BulkOpCreate {
+			return ps.CreateProduct(*op.Product)
+		}
+		return ps.UpdateProduct(op.ID, *op.Product)
+	case models.BulkOpDelete:
+		return nil, ps.DeleteProduct(op.ID)
+	case models.BulkOpAdjustStock:
+		return ps.AdjustStock(op.ID, op.Delta)
+	}
+	return nil, &productFieldError{field: "op", reason: i18n.Msg("product_input.invalid_op")}
+}
+
+// AdjustStock suma delta unidades al stock de un producto (negativo para restar)
+// La fila se bloquea hasta confirmar para que los ajustes concurrentes no se pisen
+func (ps *ProductService) AdjustStock(id uint, delta int) (*models.ProductResponse, error) {
+	var response *models.ProductResponse
+	err := ps.transaction(func(scoped *ProductService) error {
+		var product models.Product
+		err := scoped.tenant().Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "quantity").First(&product, id).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProductNotFound
+			}
+			return err
+		}
+		if product.Quantity+delta < 0 {
+			return ErrInsufficientStock
+		}
+
+		response, err = scoped.UpdateStock(id, product.Quantity+delta)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// MassUpdateProducts cambia el precio o el stock de todos los productos que cumplen los filtros,
+// p. ej. un 5% más de precio en una categoría. Con DryRun calcula la vista previa sin guardar
+// Es atómica: si algún producto quedaría con un valor negativo no se aplica ningún cambio
+func (ps *ProductService) MassUpdateProducts(query models.ProductListQuery, req models.ProductMassUpdateRequest) (*models.ProductMassUpdateResult, error) {
+	if ps.orgID == 0 {
+		return nil, ErrOrganizationRequired
+	}
+	if req.Price == nil && req.Quantity == nil {
+		return nil, ErrNoChanges
+	}
+	for _, change := range []*models.ProductMassUpdateChange{req.Price, req.Quantity} {
+		if change != nil && !models.IsValidMassUpdateOp(change.Op) {
+			return nil, ErrInvalidMassUpdate
+		}
+	}
+	if len(query.Filters) == 0 && !req.All {
+		return nil, ErrFiltersRequired
+	}
+
+	// Se recorre por ID: ordenar por un campo que se está cambiando movería los productos entre páginas
+	query.Sort, query.Desc, query.Cursor = "", false, ""
+	changeType := models.ProductChangeUpdate
+	if req.Price == nil {
+		changeType = models.ProductChangeStock
+	}
+
+	result := &models.ProductMassUpdateResult{DryRun: req.DryRun, Items: []models.ProductMassUpdateItem{}}
+	err := ps.transaction(func(scoped *ProductService) error {
+		rules, err := scoped.stockRules()
+		if err != nil {
+			return err
+		}
+
+		filtered, err := applyProductFilters(scoped.tenant(), query.Filters, rules)
+		if err != nil {
+			return err
+		}
+
+		for {
+			products, next, err := productPage(filtered.Session(&gorm.Session{}), query, MaxProductPageSize)
+			if err != nil {
+				return err
+			}
+
+			for i := range products {
+				product := &products[i]
+				result.Matched++
+
+				item := models.ProductMassUpdateItem{
+					ID:     product.ID,
+					Name:   product.Name,
+					Before: models.ProductMassUpdateValues{Price: product.Price, Quantity: product.Quantity},
+				}
+				item.After = item.Before
+				if req.Price != nil {
+					item.After.Price = math.Round(applyMassUpdateChange(product.Price, *req.Price)*100) / 100
+				}
+				if req.Quantity != nil {
+					item.After.Quantity = int(math.Round(applyMassUpdateChange(float64(product.Quantity), *req.Quantity)))
+				}
+				if item.After == item.Before {
+					continue
+				}
+
+				if item.After.Price < 0 || item.After.Quantity < 0 {
+					item.Error = "value cannot be negative"
+					result.Failed++
+				} else {
+					result.Changed++
+				}
+				if len(result.Items) < models.MassUpdatePreviewLimit {
+					result.Items = append(result.Items, item)
+				} else {
+					result.Truncated = true
+				}
+				if req.DryRun || item.Error != "" {
+					continue
+				}
+
+				before := product.AuditFields()
+				product.Price, product.Quantity = item.After.Price, item.After.Quantity
+				if err := scoped.save(product, before, changeType); err != nil {
+					return err
+				}
+			}
+
+			if next == "" {
+				break
+			}
+			query.Cursor = next
+		}
+
+		if req.DryRun || result.Failed > 0 {
+			return errRolledBack
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRolledBack) {
+		return nil, err
+	}
+
+	result.Committed = err == nil
+	return result, nil
+}
+
+// applyMassUpdateChange calcula el nuevo valor de un campo numérico
+func applyMassUpdateChange(value float64, change models.ProductMassUpdateChange) float64 {
+	switch change.Op {
+	case models.MassUpdateSet:
+		return change.Value
+	case models.MassUpdateIncrease:
+		return value + change.Value
+	case models.MassUpdateDecrease:
+		return value - change.Value
+	case models.MassUpdateIncreasePercent:
+		return value * (1 + change.Value/100)
+	case models.MassUpdateDecreasePercent:
+		return value * (1 - change.Value/100)
+	}
+	return value
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"sync"
+
+	"inventory-api/internal/models"
+)
+
+// Tipos de eventos de producto
+const (
+	ProductEventSaved   = "saved"   // Alta, modificación o restauración
+	ProductEventRemoved = "removed" // Baja (papelera) o eliminación definitiva
+)
+
+// ProductEvent notifica un cambio de producto ya confirmado en la base de datos
+type ProductEvent struct {
+	Type    string
+	Product models.Product
+}
+
+var (
+	productListenersMu sync.RWMutex
+	productListeners   []func(ProductEvent)
+)
+
+// OnProductEvent registra una función que recibe los cambios de productos
+// Se ejecuta de forma síncrona tras cada cambio, así que debe ser rápida
+func OnProductEvent(listener func(ProductEvent)) {
+	productListenersMu.Lock()
+	defer productListenersMu.Unlock()
+	productListeners = append(productListeners, listener)
+}
+
+// publishProductEvent notifica un cambio a todos los suscriptores
+// Debe llamarse después de confirmar la transacción
+func publishProductEvent(eventType string, product models.Product) {
+	productListenersMu.RLock()
+	listeners := productListeners
+	productListenersMu.RUnlock()
+
+	event := ProductEvent{Type: eventType, Product: product}
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
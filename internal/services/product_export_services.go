@@ -0,0 +1,63 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+
+	"inventory-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ExportProducts entrega a visit, uno a uno y en el orden pedido, todos los productos que
+// cumplen los filtros de la consulta. Los lee por lotes con el mismo cursor que el listado,
+// dentro de una transacción de solo lectura para que la exportación sea una foto consistente
+func (ps *ProductService) ExportProducts(query models.ProductListQuery, visit func(models.ProductResponse) error) error {
+	if query.Sort != "" && !models.IsValidProductSortField(query.Sort) {
+		return ErrInvalidSortField
+	}
+	query.Cursor = ""
+
+	return ps.db.Transaction(func(tx *gorm.DB) error {
+		scoped := *ps
+		scoped.db = tx
+
+		rules, err := scoped.stockRules()
+		if err != nil {
+			return err
+		}
+
+		filtered, err := applyProductFilters(scoped.tenant(), query.Filters, rules)
+		if err != nil {
+			return err
+		}
+
+		for {
+			products, next, err := productPage(filtered.Session(&gorm.Session{}), query, MaxProductPageSize)
+			if err != nil {
+				return err
+			}
+			for _, product := range products {
+				if err := visit(product.ToResponse(rules)); err != nil {
+					return err
+				}
+			}
+			if next == "" {
+				return nil
+			}
+			query.Cursor = next
+		}
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+}
+
+// AttributeKeys retorna las claves de los atributos definidos en la organización, ordenadas
+func (ps *ProductService) AttributeKeys() ([]string, error) {
+	keys := []string{}
+	err := ps.db.Model(&models.AttributeDefinition{}).
+		Where("organization_id = ?", ps.orgID).
+		Distinct("key").Order("key").Pluck("key", &keys).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attribute keys: %w", err)
+	}
+	return keys, nil
+}
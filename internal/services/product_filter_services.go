@@ -0,0 +1,313 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"inventory-api/internal/i18n"
+	"inventory-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Tipos de valor que admite cada campo filtrable
+const (
+	filterKindText       = "text"
+	filterKindString     = "string"
+	filterKindCategory   = "category"
+	filterKindCategoryID = "category_id"
+	filterKindTag        = "tag"
+	filterKindAttribute  = "attribute"
+	filterKindStock      = "stock_status"
+	filterKindNumber     = "number"
+	filterKindInt        = "integer"
+	filterKindTime       = "time"
+)
+
+// productFilterField describe un campo filtrable: su tipo y los operadores que admite
+type productFilterField struct {
+	Kind      string   `json:"type"`
+	Operators []string `json:"operators"`
+}
+
+// productFilterFields es la especificación de la sintaxis de filtros del listado de productos
+var productFilterFields = map[string]productFilterField{
+	"search":       {Kind: filterKindText, Operators: []string{models.FilterOpEq}},
+	"sku":          {Kind: filterKindString, Operators: []string{models.FilterOpEq, models.FilterOpIn}},
+	"category":     {Kind: filterKindCategory, Operators: []string{models.FilterOpEq, models.FilterOpNe, models.FilterOpIn}},
+	"category_id":  {Kind: filterKindCategoryID, Operators: []string{models.FilterOpEq, models.FilterOpNe, models.FilterOpIn}},
+	"tag":          {Kind: filterKindTag, Operators: []string{models.FilterOpEq, models.FilterOpIn}},
+	"stock_status": {Kind: filterKindStock, Operators: []string{models.FilterOpEq, models.FilterOpIn}},
+	"price":        {Kind: filterKindNumber, Operators: comparisonOperators},
+	"quantity":     {Kind: filterKindInt, Operators: comparisonOperators},
+	"created_at":   {Kind: filterKindTime, Operators: rangeOperators},
+	"updated_at":   {Kind: filterKindTime, Operators: rangeOperators},
+}
+
+var (
+	rangeOperators      = []string{models.FilterOpGt, models.FilterOpGte, models.FilterOpLt, models.FilterOpLte}
+	comparisonOperators = append([]string{models.FilterOpEq, models.FilterOpNe}, rangeOperators...)
+)
+
+// Los atributos personalizados se filtran como filter[attr.clave][operador]=valor
+const attributeFilterPrefix = "attr."
+
+// attributeFilterField es la especificación común de los filtros de atributos
+var attributeFilterField = productFilterField{
+	Kind:      filterKindAttribute,
+	Operators: append([]string{models.FilterOpIn}, comparisonOperators...),
+}
+
+// Operadores SQL de las comparaciones
+var filterSQLOperators = map[string]string{
+	models.FilterOpEq:  "=",
+	models.FilterOpNe:  "<>",
+	models.FilterOpGt:  ">",
+	models.FilterOpGte: ">=",
+	models.FilterOpLt:  "<",
+	models.FilterOpLte: "<=",
+}
+
+// ProductFilterError indica un filtro del listado que no cumple la sintaxis
+type ProductFilterError struct {
+	Filter string
+	Reason i18n.Message
+}
+
+// Error implementa la interfaz error
+func (e *ProductFilterError) Error() string {
+	return fmt.Sprintf("invalid filter %s: %s", e.Filter, e.Reason)
+}
+
+// ProductFilterSpec retorna los campos filtrables y sus operadores, para documentar la sintaxis
+func ProductFilterSpec() map[string]productFilterField {
+	spec := make(map[string]productFilterField, len(productFilterFields)+1)
+	for name, field := range productFilterFields {
+		spec[name] = field
+	}
+	spec[attributeFilterPrefix+"<key>"] = attributeFilterField
+	return spec
+}
+
+// applyProductFilters valida los filtros y los añade a la consulta, combinados con AND
+// rules son las reglas de stock de la organización, con las que se filtra por stock_status
+func applyProductFilters(db *gorm.DB, filters []models.ProductFilter, rules *models.StockRules) (*gorm.DB, error) {
+	for _, filter := range filters {
+		field, ok := productFilterFields[filter.Field]
+		if !ok && strings.HasPrefix(filter.Field, attributeFilterPrefix) {
+			if !attributeKeyPattern.MatchString(strings.TrimPrefix(filter.Field, attributeFilterPrefix)) {
+				return nil, &ProductFilterError{Filter: filter.Field, Reason: i18n.Msg("filter.invalid_attribute_key")}
+			}
+			field, ok = attributeFilterField, true
+		}
+		if !ok {
+			return nil, &ProductFilterError{Filter: filter.Field, Reason: i18n.Msg("filter.unknown_field")}
+		}
+		if !containsString(field.Operators, filter.Op) {
+			return nil, &ProductFilterError{
+				Filter: filter.String(),
+				Reason: i18n.Msg("filter.invalid_operator", strings.Join(field.Operators, ", ")),
+			}
+		}
+		if len(filter.Values) == 0 || (filter.Op != models.FilterOpIn && len(filter.Values) > 1) {
+			return nil, &ProductFilterError{Filter: filter.String(), Reason: i18n.Msg("filter.single_value")}
+		}
+
+		var err error
+		switch field.Kind {
+		case filterKindText:
+			// Palabras completas por search_vector y fragmentos por ILIKE (índices de trigramas)
+			text := filter.Values[0]
+			searchPattern := "%" + text + "%"
+			db = db.Where("(search_vector @@ (websearch_to_tsquery('spanish', ?) || websearch_to_tsquery('english', ?)) OR name ILIKE ? OR description ILIKE ?)",
+				text, text, searchPattern, searchPattern)
+		case filterKindString:
+			db = applyStringFilter(db, filter)
+		case filterKindCategory, filterKindCategoryID:
+			db, err = applyCategoryFilter(db, filter, field.Kind)
+		case filterKindTag:
+			db = applyTagFilter(db, filter)
+		case filterKindAttribute:
+			db, err = applyAttributeFilter(db, filter, strings.TrimPrefix(filter.Field, attributeFilterPrefix))
+		case filterKindStock:
+			db, err = applyStockStatusFilter(db, filter, rules)
+		case filterKindNumber, filterKindInt:
+			db, err = applyNumericFilter(db, filter, field.Kind)
+		case filterKindTime:
+			db, err = applyTimeFilter(db, filter)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// applyStringFilter aplica igualdad, desigualdad o pertenencia a una lista
+func applyStringFilter(db *gorm.DB, filter models.ProductFilter) *gorm.DB {
+	switch filter.Op {
+	case models.FilterOpIn:
+		return db.Where(filter.Field+" IN ?", filter.Values)
+	default:
+		return db.Where(filter.Field+" "+filterSQLOperators[filter.Op]+" ?", filter.Values[0])
+	}
+}
+
+// applyCategoryFilter limita los productos a las categorías indicadas (por nombre o slug,
+// o por ID) incluidas sus subcategorías. Las categorías de otras organizaciones que
+// coincidan no afectan al resultado porque la consulta ya está limitada a la organización
+func applyCategoryFilter(db *gorm.DB, filter models.ProductFilter, kind string) (*gorm.DB, error) {
+	var condition string
+	var values []interface{}
+	if kind == filterKindCategoryID {
+		ids := make([]uint64, 0, len(filter.Values))
+		for _, raw := range filter.Values {
+			id, err := strconv.ParseUint(raw, 10, 32)
+			if err != nil {
+				return nil, &ProductFilterError{Filter: filter.String(), Reason: i18n.Msg("filter.category_id")}
+			}
+			ids = append(ids, id)
+		}
+		condition = "id IN ?"
+		values = append(values, ids)
+	} else {
+		slugs := make([]string, 0, len(filter.Values))
+		for _, value := range filter.Values {
+			slugs = append(slugs, slugify(value))
+		}
+		condition = "slug IN ?"
+		values = append(values, slugs)
+	}
+
+	subtree := fmt.Sprintf(categorySubtreeSQL, condition)
+	if filter.Op == models.FilterOpNe {
+		return db.Where("(category_id IS NULL OR category_id NOT IN ("+subtree+"))", values...), nil
+	}
+	return db.Where("category_id IN ("+subtree+")", values...), nil
+}
+
+// applyTagFilter limita los productos a los que tienen alguna de las etiquetas indicadas
+// Varios filtros de etiqueta se combinan con AND: filter[tag]=a&filter[tag]=b exige las dos
+func applyTagFilter(db *gorm.DB, filter models.ProductFilter) *gorm.DB {
+	slugs := make([]string, 0, len(filter.Values))
+	for _, value := range filter.Values {
+		slugs = append(slugs, slugify(value))
+	}
+	return db.Where(`EXISTS (SELECT 1 FROM product_tags pt JOIN tags t ON t.id = pt.tag_id
+		WHERE pt.product_id = products.id AND t.slug IN ?)`, slugs)
+}
+
+// applyAttributeFilter compara el valor de un atributo personalizado
+// Las igualdades usan la contención de JSONB (índice GIN) probando el valor como texto,
+// número o booleano; los rangos solo coinciden con valores numéricos
+func applyAttributeFilter(db *gorm.DB, filter models.ProductFilter, key string) (*gorm.DB, error) {
+	if op, ok := filterSQLOperators[filter.Op]; ok && filter.Op != models.FilterOpEq && filter.Op != models.FilterOpNe {
+		value, err := strconv.ParseFloat(filter.Values[0], 64)
+		if err != nil {
+			return nil, &ProductFilterError{Filter: filter.String(), Reason: i18n.Msg("filter.number")}
+		}
+		return db.Where("CASE WHEN jsonb_typeof(attributes -> ?) = 'number' THEN (attributes ->> ?)::numeric END "+op+" ?",
+			key, key, value), nil
+	}
+
+	var conditions []string
+	var args []interface{}
+	for _, raw := range filter.Values {
+		candidates := []interface{}{raw}
+		if number, err := strconv.ParseFloat(raw, 64); err == nil {
+			candidates = append(candidates, number)
+		}
+		if raw == "true" || raw == "false" {
+			candidates = append(candidates, raw == "true")
+		}
+		for _, candidate := range candidates {
+			document, err := json.Marshal(map[string]interface{}{key: candidate})
+			if err != nil {
+				return nil, &ProductFilterError{Filter: filter.String(), Reason: i18n.Msg("filter.invalid_value")}
+			}
+			conditions = append(conditions, "attributes @> ?::jsonb")
+			args = append(args, string(document))
+		}
+	}
+
+	condition := "(" + strings.Join(conditions, " OR ") + ")"
+	if filter.Op == models.FilterOpNe {
+		condition = "NOT " + condition
+	}
+	return db.Where(condition, args...), nil
+}
+
+// applyStockStatusFilter limita los productos a los estados de stock indicados, calculados con
+// las mismas reglas que las respuestas
+func applyStockStatusFilter(db *gorm.DB, filter models.ProductFilter, rules *models.StockRules) (*gorm.DB, error) {
+	for _, status := range filter.Values {
+		if !models.IsValidStockStatus(status) {
+			return nil, &ProductFilterError{
+				Filter: filter.String(),
+				Reason: i18n.Msg("filter.stock_status"),
+			}
+		}
+	}
+	status, args := stockStatusSQL(rules)
+	return db.Where(status+" IN ?", append(args, filter.Values)...), nil
+}
+
+// applyNumericFilter aplica una comparación sobre precio o cantidad
+func applyNumericFilter(db *gorm.DB, filter models.ProductFilter, kind string) (*gorm.DB, error) {
+	raw := filter.Values[0]
+
+	var value interface{}
+	if kind == filterKindInt {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, &ProductFilterError{Filter: filter.String(), Reason: i18n.Msg("filter.integer")}
+		}
+		value = n
+	} else {
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, &ProductFilterError{Filter: filter.String(), Reason: i18n.Msg("filter.number")}
+		}
+		value = f
+	}
+
+	return db.Where(filter.Field+" "+filterSQLOperators[filter.Op]+" ?", value), nil
+}
+
+// applyTimeFilter aplica un rango de fechas; una fecha sin hora abarca el día completo
+// (p. ej. lte 2026-01-31 incluye todo el 31 y gt 2026-01-31 empieza el 1 de febrero)
+func applyTimeFilter(db *gorm.DB, filter models.ProductFilter) (*gorm.DB, error) {
+	raw := filter.Values[0]
+
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		day, dayErr := time.Parse("2006-01-02", raw)
+		if dayErr != nil {
+			return nil, &ProductFilterError{Filter: filter.String(), Reason: i18n.Msg("filter.date")}
+		}
+
+		nextDay := day.AddDate(0, 0, 1)
+		switch filter.Op {
+		case models.FilterOpGt:
+			return db.Where(filter.Field+" >= ?", nextDay), nil
+		case models.FilterOpLte:
+			return db.Where(filter.Field+" < ?", nextDay), nil
+		}
+		value = day
+	}
+
+	return db.Where(filter.Field+" "+filterSQLOperators[filter.Op]+" ?", value), nil
+}
+
+// containsString verifica si la lista contiene el valor
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"inventory-api/internal/models"
+)
+
+// TestApplyProductFiltersRejects comprueba los filtros no válidos, que se rechazan antes de
+// tocar la consulta (por eso basta con una consulta nil)
+func TestApplyProductFiltersRejects(t *testing.T) {
+	tests := []struct {
+		filter     models.ProductFilter
+		wantFilter string
+		wantReason string
+	}{
+		{models.ProductFilter{Field: "color", Op: models.FilterOpEq, Values: []string{"red"}}, "color", "filter.unknown_field"},
+		{models.ProductFilter{Field: "attr.Color", Op: models.FilterOpEq, Values: []string{"red"}}, "attr.Color", "filter.invalid_attribute_key"},
+		{models.ProductFilter{Field: "search", Op: models.FilterOpIn, Values: []string{"a"}}, "filter[search][in]", "filter.invalid_operator"},
+		{models.ProductFilter{Field: "created_at", Op: models.FilterOpEq, Values: []string{"2026-01-01"}}, "filter[created_at][eq]", "filter.invalid_operator"},
+		{models.ProductFilter{Field: "price", Op: models.FilterOpGte, Values: []string{"1", "2"}}, "filter[price][gte]", "filter.single_value"},
+		{models.ProductFilter{Field: "sku", Op: models.FilterOpIn}, "filter[sku][in]", "filter.single_value"},
+		{models.ProductFilter{Field: "price", Op: models.FilterOpLt, Values: []string{"cheap"}}, "filter[price][lt]", "filter.number"},
+		{models.ProductFilter{Field: "quantity", Op: models.FilterOpEq, Values: []string{"1.5"}}, "filter[quantity][eq]", "filter.integer"},
+		{models.ProductFilter{Field: "updated_at", Op: models.FilterOpGt, Values: []string{"31/01/2026"}}, "filter[updated_at][gt]", "filter.date"},
+		{models.ProductFilter{Field: "stock_status", Op: models.FilterOpIn, Values: []string{"low", "empty"}}, "filter[stock_status][in]", "filter.stock_status"},
+	}
+
+	for _, tt := range tests {
+		_, err := applyProductFilters(nil, []models.ProductFilter{tt.filter}, nil)
+		var filterErr *ProductFilterError
+		if !errors.As(err, &filterErr) {
+			t.Errorf("%s: got %v, want a ProductFilterError", tt.filter, err)
+			continue
+		}
+		if filterErr.Filter != tt.wantFilter || filterErr.Reason.Key != tt.wantReason {
+			t.Errorf("%s: got %s (%s), want %s (%s)", tt.filter, filterErr.Filter, filterErr.Reason.Key, tt.wantFilter, tt.wantReason)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"inventory-api/internal/i18n"
+	"inventory-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Máximo de filas por importación; toda la importación se hace en una sola transacción
+var productImportMaxRows = envInt("PRODUCT_IMPORT_MAX_ROWS", 50000)
+
+// ProductImportError indica un archivo de importación que no se puede procesar
+type ProductImportError struct {
+	Reason i18n.Message
+}
+
+// Error implementa la interfaz error
+func (e *ProductImportError) Error() string {
+	return "invalid import: " + e.Reason.String()
+}
+
+// productImportRow representa una fila del CSV con las columnas ya asignadas a campos
+type productImportRow struct {
+	line    int
+	record  []string
+	columns map[string]int
+}
+
+// value retorna el contenido de la celda del campo; vacío si el archivo no tiene esa columna
+func (r productImportRow) value(field string) string {
+	i, ok := r.columns[field]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	return strings.TrimSpace(r.record[i])
+}
+
+// ImportProducts crea o actualiza productos a partir de un CSV, leyéndolo fila a fila
+// Las filas con un SKU existente actualizan ese producto (sus celdas vacías conservan el valor
+// actual) y el resto crean productos. Cada fila se guarda en su propio savepoint dentro de una
+// única transacción, que se deshace en las simulaciones y en all_or_nothing si alguna fila falla
+func (ps *ProductService) ImportProducts(r io.Reader, opts models.ProductImportOptions) (*models.ProductImportResult, error) {
+	if ps.orgID == 0 {
+		return nil, ErrOrganizationRequired
+	}
+	if opts.Mode == "" {
+		opts.Mode = models.ImportModeAllOrNothing
+	}
+	if !models.IsValidImportMode(opts.Mode) {
+		return nil, &ProductImportError{Reason: i18n.Msg("import.invalid_mode")}
+	}
+
+	reader := csv.NewReader(r)
+	if opts.Delimiter != 0 {
+		reader.Comma = opts.Delimiter
+	}
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, &ProductImportError{Reason: i18n.Msg("import.empty_file")}
+	}
+	if err != nil {
+		return nil, &ProductImportError{Reason: i18n.Msg("import.invalid_header", err.Error())}
+	}
+	columns, ignored, err := mapImportColumns(header, opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ProductImportResult{
+		Mode:           opts.Mode,
+		DryRun:         opts.DryRun,
+		IgnoredColumns: ignored,
+		Errors:         []models.ProductImportRowError{},
+	}
+
+	err = ps.transaction(func(scoped *ProductService) error {
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				// Un error de formato solo invalida la fila; el lector continúa en la siguiente
+				var parseErr *csv.ParseError
+				if !errors.As(err, &parseErr) {
+					return fmt.Errorf("failed to read file: %w", err)
+				}
+				result.Rows++
+				result.Failed++
+				result.Errors = append(result.Errors, models.ProductImportRowError{Row: parseErr.Line, Error: parseErr.Err.Error()})
+				continue
+			}
+			if isBlankRecord(record) {
+				continue
+			}
+
+			line, _ := reader.FieldPos(0)
+			result.Rows++
+			if result.Rows > productImportMaxRows {
+				return &ProductImportError{Reason: i18n.Msg("import.too_many_rows", productImportMaxRows)}
+			}
+
+			row := productImportRow{line: line, record: record, columns: columns}
+			created, rowErr, err := scoped.importRow(row)
+			if err != nil {
+				return err
+			}
+			switch {
+			case rowErr != nil:
+				result.Failed++
+				result.Errors = append(result.Errors, *rowErr)
+			case created:
+				result.Created++
+			default:
+				result.Updated++
+			}
+		}
+
+		if opts.DryRun || (opts.Mode == models.ImportModeAllOrNothing && result.Failed > 0) {
+			return errRolledBack
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRolledBack) {
+		return nil, err
+	}
+
+	result.Committed = err == nil
+	return result, nil
+}
+
+// importRow crea o actualiza el producto de una fila dentro de un savepoint
+// Retorna el error de validación de la fila, o un error de base de datos que aborta la importación
+func (ps *ProductService) importRow(row productImportRow) (bool, *models.ProductImportRowError, error) {
+	var created bool
+	var rowErr *models.ProductImportRowError
+
+	err := ps.transaction(func(scoped *ProductService) error {
+		req, existing, err := scoped.importRequest(row)
+		if err == nil {
+			if existing == nil {
+				created = true
+				_, err = scoped.CreateProduct(req)
+			} else {
+				_, err = scoped.UpdateProduct(existing.ID, req)
+			}
+		}
+		if err != nil {
+			if rowErr = importRowError(row, err, scoped.lang); rowErr != nil {
+				return errRolledBack
+			}
+			return err
+		}
+		return nil
+	})
+	if rowErr != nil {
+		return false, rowErr, nil
+	}
+	return created, nil, err
+}
+
+// importRequest construye la petición de producto de una fila
+// Si el SKU ya existe se parte del producto actual, que se retorna como existing
+func (ps *ProductService) importRequest(row productImportRow) (models.ProductRequest, *models.Product, error) {
+	req := models.ProductRequest{SKU: row.value("sku")}
+	if len(req.SKU) > 64 {
+		return req, nil, &productFieldError{field: "sku", reason: i18n.Msg("validation.max_length", "64")}
+	}
+
+	var existing *models.Product
+	if req.SKU != "" {
+		var product models.Product
+		err := ps.tenant().Preload("Tags").Where("sku = ?", req.SKU).First(&product).Error
+		switch {
+		case err == nil:
+			existing = &product
+			req.Name = product.Name
+			req.Description = product.Description
+			req.Quantity = product.Quantity
+			req.ReorderPoint = product.ReorderPoint
+			req.Price = product.Price
+			req.CategoryID = product.CategoryID
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return req, nil, fmt.Errorf("failed to fetch product: %w", err)
+		}
+	}
+
+	if value := row.value("name"); value != "" {
+		req.Name = value
+	}
+	if value := row.value("description"); value != "" {
+		req.Description = value
+	}
+	if value := row.value("quantity"); value != "" {
+		quantity, err := strconv.Atoi(value)
+		if err != nil || quantity < 0 {
+			return req, nil, &productFieldError{field: "quantity", reason: i18n.Msg("product_input.whole_number")}
+		}
+		req.Quantity = quantity
+	}
+	if value := row.value("reorder_point"); value != "" {
+		reorderPoint, err := strconv.Atoi(value)
+		if err != nil || reorderPoint < 0 {
+			return req, nil, &productFieldError{field: "reorder_point", reason: i18n.Msg("product_input.whole_number")}
+		}
+		req.ReorderPoint = &reorderPoint
+	}
+	if value := row.value("price"); value != "" {
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || price < 0 {
+			return req, nil, &productFieldError{field: "price", reason: i18n.Msg("product_input.number")}
+		}
+		req.Price = price
+	}
+	if value := row.value("category"); value != "" {
+		req.Category = value
+		req.CategoryID = nil
+	}
+	if value := row.value("category_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return req, nil, &productFieldError{field: "category_id", reason: i18n.Msg("product_input.category_id")}
+		}
+		categoryID := uint(id)
+		req.CategoryID = &categoryID
+	}
+	if value := row.value("tags"); value != "" {
+		req.Tags = strings.Split(value, models.ProductImportTagSeparator)
+	}
+
+	if err := checkProductRequest(req); err != nil {
+		return req, nil, err
+	}
+
+	attributes, err := ps.importAttributes(row, &req, existing)
+	if err != nil {
+		return req, nil, err
+	}
+	req.Attributes = attributes
+	return req, existing, nil
+}
+
+// importAttributes convierte las columnas attr.<clave> al tipo de los atributos de la categoría
+// Sin celdas de atributos retorna nil, y al actualizar se conservan los valores actuales
+func (ps *ProductService) importAttributes(row productImportRow, req *models.ProductRequest, existing *models.Product) (map[string]interface{}, error) {
+	raw := make(map[string]string)
+	for field := range row.columns {
+		if key := strings.TrimPrefix(field, attributeFilterPrefix); key != field {
+			if value := row.value(field); value != "" {
+				raw[key] = value
+			}
+		}
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	// La categoría se resuelve aquí para conocer los tipos; la petición pasa a llevar su ID
+	category, err := resolveProductCategory(ps.db, ps.orgID, req.CategoryID, req.Category)
+	if err != nil {
+		return nil, err
+	}
+	req.CategoryID, req.Category = &category.ID, ""
+
+	definitions, err := categoryAttributeDefinitions(ps.db, ps.orgID, category.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Al actualizar se parte de los valores actuales que sigan definidos en la categoría
+	attributes := make(map[string]interface{})
+	if existing != nil {
+		current, err := validateProductAttributes(definitions, existing.AttributeValues(), true)
+		if err != nil {
+			current = models.ProductAttributes{}
+		}
+		for key, value := range current {
+			attributes[key] = value
+		}
+	}
+	for key, value := range raw {
+		parsed, err := parseAttributeValue(definitions, key, value)
+		if err != nil {
+			return nil, err
+		}
+		attributes[key] = parsed
+	}
+	return attributes, nil
+}
+
+// importRowError traduce el error de una fila a su entrada del informe, en el idioma indicado
+// Retorna nil si el error no es de validación y debe abortar la importación
+func importRowError(row productImportRow, err error, lang i18n.Language) *models.ProductImportRowError {
+	column, reason, ok := productInputError(err)
+	if !ok {
+		return nil
+	}
+	return &models.ProductImportRowError{Row: row.line, SKU: row.value("sku"), Column: column, Error: reason.In(lang)}
+}
+
+// mapImportColumns asigna cada columna de la cabecera a un campo de producto
+// Las columnas sin campo se retornan como ignoradas
+func mapImportColumns(header []string, mapping map[string]string) (map[string]int, []string, error) {
+	for column, field := range mapping {
+		if !isProductImportField(field) {
+			return nil, nil, &ProductImportError{Reason: i18n.Msg("import.unknown_mapping_field", field, column)}
+		}
+	}
+
+	columns := make(map[string]int)
+	mapped := make(map[string]bool)
+	var ignored []string
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")) // BOM de los CSV de Excel
+		field, ok := mapping[name]
+		if ok {
+			mapped[name] = true
+		} else {
+			field = strings.ToLower(name)
+		}
+		if !isProductImportField(field) {
+			ignored = append(ignored, name)
+			continue
+		}
+		if _, duplicated := columns[field]; duplicated {
+			return nil, nil, &ProductImportError{Reason: i18n.Msg("import.duplicate_field", field)}
+		}
+		columns[field] = i
+	}
+
+	for column := range mapping {
+		if !mapped[column] {
+			return nil, nil, &ProductImportError{Reason: i18n.Msg("import.column_not_found", column)}
+		}
+	}
+	if _, ok := columns["sku"]; !ok {
+		if _, ok := columns["name"]; !ok {
+			return nil, nil, &ProductImportError{Reason: i18n.Msg("import.name_or_sku_required")}
+		}
+	}
+	return columns, ignored, nil
+}
+
+// isProductImportField verifica si se puede asignar una columna al campo indicado
+func isProductImportField(field string) bool {
+	if key := strings.TrimPrefix(field, attributeFilterPrefix); key != field {
+		return attributeKeyPattern.MatchString(key)
+	}
+	return containsString(models.ProductImportFields, field)
+}
+
+// isBlankRecord verifica si todas las celdas de una fila están vacías
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"errors"
+
+	"inventory-api/internal/i18n"
+	"inventory-api/internal/models"
+	"inventory-api/internal/validation"
+)
+
+// productFieldError indica un campo de producto con un valor no válido en las operaciones
+// por lotes (importaciones y operaciones masivas), que informan del error por elemento
+type productFieldError struct {
+	field  string
+	reason i18n.Message
+}
+
+// Error implementa la interfaz error
+func (e *productFieldError) Error() string {
+	return e.field + ": " + e.reason.String()
+}
+
+// checkProductRequest valida una petición de producto con las mismas reglas (etiquetas validate)
+// que los endpoints de creación y actualización. Se informa del primer campo no válido
+func checkProductRequest(req models.ProductRequest) error {
+	err := validation.Validate(req)
+	var fieldErrs validation.Errors
+	if errors.As(err, &fieldErrs) && len(fieldErrs) > 0 {
+		return &productFieldError{field: fieldErrs[0].Field, reason: fieldErrs[0].Reason()}
+	}
+	return err
+}
+
+// productInputError traduce un error de validación de un producto al campo afectado y su motivo
+// ok es falso si el error no es de validación (p. ej. un fallo de la base de datos)
+func productInputError(err error) (field string, reason i18n.Message, ok bool) {
+	var fieldErr *productFieldError
+	var attributeErr *ProductAttributeError
+	switch {
+	case errors.As(err, &fieldErr):
+		return fieldErr.field, fieldErr.reason, true
+	case errors.As(err, &attributeErr):
+		return attributeFilterPrefix + attributeErr.Attribute, attributeErr.Reason, true
+	}
+
+	switch {
+	case errors.Is(err, ErrProductNotFound):
+		return "id", i18n.Msg("product_input.not_found"), true
+	case errors.Is(err, ErrInsufficientStock):
+		return "delta", i18n.Msg("product_input.insufficient_stock"), true
+	case errors.Is(err, ErrSKUExists):
+		return "sku", i18n.Msg("product_input.sku_exists"), true
+	case errors.Is(err, ErrCategoryNotFound), errors.Is(err, ErrInvalidCategory):
+		return "category", i18n.Msg("product_input.invalid_category"), true
+	case errors.Is(err, ErrInvalidTag), errors.Is(err, ErrTooManyTags):
+		return "tags", i18n.Msg("product_input.invalid_tags", models.MaxProductTags), true
+	}
+	return "", i18n.Message{}, false
+}
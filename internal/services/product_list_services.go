@@ -0,0 +1,203 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"inventory-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Límites de paginación del listado de productos
+const (
+	DefaultProductPageSize = 50
+	MaxProductPageSize     = 500
+)
+
+// productCursor es el contenido del token opaco de paginación: la posición del último
+// producto devuelto según el orden pedido. El ID desempata valores repetidos
+type productCursor struct {
+	Sort  string          `json:"s,omitempty"`
+	Desc  bool            `json:"d,omitempty"`
+	Value json.RawMessage `json:"v,omitempty"`
+	ID    uint            `json:"id"`
+}
+
+// ListProducts obtiene una página de productos con orden, filtros y paginación por cursor
+// La paginación por cursor (keyset) no se degrada con el número de páginas, a diferencia de OFFSET
+func (ps *ProductService) ListProducts(query models.ProductListQuery) (*models.ProductPage, error) {
+	if query.Sort != "" && !models.IsValidProductSortField(query.Sort) {
+		return nil, ErrInvalidSortField
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultProductPageSize
+	}
+	if limit > MaxProductPageSize {
+		limit = MaxProductPageSize
+	}
+
+	rules, err := ps.stockRules()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := applyProductFilters(ps.tenant(), query.Filters, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	// Total de productos que cumplen los filtros
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count products: %w", err)
+	}
+
+	products, next, err := productPage(filtered.Session(&gorm.Session{}), query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ProductPage{
+		Products:   []models.ProductResponse{},
+		Total:      total,
+		Limit:      limit,
+		NextCursor: next,
+	}
+	for _, product := range products {
+		result.Products = append(result.Products, product.ToResponse(rules))
+	}
+
+	return result, nil
+}
+
+// productPage obtiene los productos filtrados que siguen al cursor de la consulta y el cursor
+// de la página siguiente (vacío si es la última)
+func productPage(filtered *gorm.DB, query models.ProductListQuery, limit int) ([]models.Product, string, error) {
+	// Orden estable: campo pedido y después ID en la misma dirección
+	column := "id"
+	if query.Sort != "" {
+		column = query.Sort
+	}
+	direction, comparator := "ASC", ">"
+	if query.Desc {
+		direction, comparator = "DESC", "<"
+	}
+
+	page := filtered
+	if query.Cursor != "" {
+		cursor, err := decodeProductCursor(query.Cursor, query.Sort, query.Desc)
+		if err != nil {
+			return nil, "", err
+		}
+		if query.Sort == "" {
+			page = page.Where("id "+comparator+" ?", cursor.ID)
+		} else {
+			value, err := cursorValue(query.Sort, cursor.Value)
+			if err != nil {
+				return nil, "", err
+			}
+			page = page.Where("("+column+", id) "+comparator+" (?, ?)", value, cursor.ID)
+		}
+	}
+	if query.Sort != "" {
+		page = page.Order(column + " " + direction)
+	}
+
+	// Se pide un producto de más para saber si existe otra página
+	var products []models.Product
+	if err := page.Preload("Tags").Order("id " + direction).Limit(limit + 1).Find(&products).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to fetch products: %w", err)
+	}
+
+	if len(products) <= limit {
+		return products, "", nil
+	}
+	products = products[:limit]
+	next, err := encodeProductCursor(&products[limit-1], query.Sort, query.Desc)
+	if err != nil {
+		return nil, "", err
+	}
+	return products, next, nil
+}
+
+// encodeProductCursor genera el token que apunta justo después del producto indicado
+func encodeProductCursor(product *models.Product, sort string, desc bool) (string, error) {
+	cursor := productCursor{Sort: sort, Desc: desc, ID: product.ID}
+
+	var value interface{}
+	switch sort {
+	case "name":
+		value = product.Name
+	case "price":
+		value = product.Price
+	case "quantity":
+		value = product.Quantity
+	case "created_at":
+		value = product.CreatedAt
+	case "updated_at":
+		value = product.UpdatedAt
+	}
+	if value != nil {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode cursor: %w", err)
+		}
+		cursor.Value = raw
+	}
+
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeProductCursor valida un token y comprueba que corresponde al mismo orden
+func decodeProductCursor(token, sort string, desc bool) (*productCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor productCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Sort != sort || cursor.Desc != desc {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// cursorValue interpreta el valor de ordenación guardado en el cursor según el campo
+func cursorValue(sort string, raw json.RawMessage) (interface{}, error) {
+	var err error
+	var value interface{}
+	switch sort {
+	case "name":
+		var s string
+		err = json.Unmarshal(raw, &s)
+		value = s
+	case "price":
+		var f float64
+		err = json.Unmarshal(raw, &f)
+		value = f
+	case "quantity":
+		var n int
+		err = json.Unmarshal(raw, &n)
+		value = n
+	default:
+		var t time.Time
+		err = json.Unmarshal(raw, &t)
+		value = t
+	}
+	if err != nil || len(raw) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return value, nil
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"inventory-api/internal/models"
+)
+
+// TestProductCursorRoundTrip comprueba que el valor de ordenación y el ID sobreviven al token
+func TestProductCursorRoundTrip(t *testing.T) {
+	created := time.Date(2026, 3, 14, 15, 9, 26, 535897000, time.UTC)
+	product := models.Product{ID: 42, Name: "Tornillo \"M4\" ñ", Price: 12.5, Quantity: 7}
+	product.CreatedAt = created
+	product.UpdatedAt = created.Add(time.Hour)
+
+	tests := []struct {
+		sort string
+		want interface{} // nil si el orden es por ID
+	}{
+		{"", nil},
+		{"name", product.Name},
+		{"price", product.Price},
+		{"quantity", product.Quantity},
+		{"created_at", product.CreatedAt},
+		{"updated_at", product.UpdatedAt},
+	}
+	for _, tt := range tests {
+		for _, desc := range []bool{false, true} {
+			token, err := encodeProductCursor(&product, tt.sort, desc)
+			if err != nil {
+				t.Fatalf("sort %q: encode: %v", tt.sort, err)
+			}
+			cursor, err := decodeProductCursor(token, tt.sort, desc)
+			if err != nil {
+				t.Fatalf("sort %q desc %v: decode: %v", tt.sort, desc, err)
+			}
+			if cursor.ID != product.ID {
+				t.Errorf("sort %q: got ID %d, want %d", tt.sort, cursor.ID, product.ID)
+			}
+			if tt.want == nil {
+				if len(cursor.Value) != 0 {
+					t.Errorf("sort by ID: got value %s, want none", cursor.Value)
+				}
+				continue
+			}
+			value, err := cursorValue(tt.sort, cursor.Value)
+			if err != nil {
+				t.Fatalf("sort %q: value: %v", tt.sort, err)
+			}
+			if want, ok := tt.want.(time.Time); ok {
+				if got, _ := value.(time.Time); !got.Equal(want) {
+					t.Errorf("sort %q: got %v, want %v", tt.sort, value, want)
+				}
+			} else if value != tt.want {
+				t.Errorf("sort %q: got %v (%T), want %v (%T)", tt.sort, value, value, tt.want, tt.want)
+			}
+		}
+	}
+}
+
+// TestDecodeProductCursorRejects comprueba que un token de otro orden o manipulado no se acepta
+func TestDecodeProductCursorRejects(t *testing.T) {
+	product := models.Product{ID: 1, Name: "a"}
+	token, err := encodeProductCursor(&product, "name", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		sort  string
+		desc  bool
+	}{
+		{"other sort", token, "price", false},
+		{"other order", token, "name", true},
+		{"sort by ID", token, "", false},
+		{"not base64", "not*base64", "name", false},
+		{"not JSON", base64.RawURLEncoding.EncodeToString([]byte("{")), "name", false},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"s":"name","id":1}`)), "name", false},
+	}
+	for _, tt := range tests {
+		if _, err := decodeProductCursor(tt.token, tt.sort, tt.desc); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: got %v, want ErrInvalidCursor", tt.name, err)
+		}
+	}
+
+	// Un cursor del orden correcto sin valor o con un valor del tipo equivocado tampoco sirve
+	for _, raw := range []string{`{"s":"name","id":1}`, `{"s":"name","v":3,"id":1}`} {
+		cursor, err := decodeProductCursor(base64.RawURLEncoding.EncodeToString([]byte(raw)), "name", false)
+		if err != nil {
+			t.Fatalf("%s: decode: %v", raw, err)
+		}
+		if _, err := cursorValue("name", cursor.Value); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: got %v, want ErrInvalidCursor", raw, err)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+
+	"inventory-api/internal/i18n"
+	"inventory-api/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Campos de ProductRequest que puede cambiar un parche
+var productPatchFields = map[string]bool{
+	"sku": true, "name": true, "description": true, "quantity": true, "reorder_point": true, "price": true,
+	"category": true, "category_id": true, "tags": true, "attributes": true,
+}
+
+// ProductValidationError indica que el producto resultante de un parche no es válido
+type ProductValidationError struct {
+	Field  string
+	Reason i18n.Message
+}
+
+// Error implementa la interfaz error
+func (e *ProductValidationError) Error() string {
+	return "invalid product " + e.Field + ": " + e.Reason.String()
+}
+
+// PatchProduct aplica un parche (JSON Merge Patch o JSON Patch, según contentType) a un producto
+// El parche se aplica sobre el documento de ProductRequest del producto, así que solo cambian los
+// campos que toca, y el resultado se valida con las mismas reglas que PUT. La fila queda bloqueada
+// hasta guardar para no pisar cambios concurrentes, p. ej. de stock
+func (ps *ProductService) PatchProduct(id uint, contentType string, patch []byte) (*models.ProductResponse, error) {
+	var response *models.ProductResponse
+	err := ps.transaction(func(scoped *ProductService) error {
+		var product models.Product
+		err := scoped.tenant().Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Tags").First(&product, id).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProductNotFound
+			}
+			return err
+		}
+
+		current, err := productPatchDocument(product.ToRequest())
+		if err != nil {
+			return err
+		}
+		var doc interface{}
+		if doc, err = productPatchDocument(product.ToRequest()); err != nil {
+			return err
+		}
+
+		switch contentType {
+		case models.MergePatchContentType:
+			var mergePatch interface{}
+			if err := json.Unmarshal(patch, &mergePatch); err != nil {
+				return &JSONPatchError{Reason: i18n.Msg("patch.not_json")}
+			}
+			doc = applyMergePatch(doc, mergePatch)
+		case models.JSONPatchContentType:
+			if doc, err = applyJSONPatch(doc, patch); err != nil {
+				return err
+			}
+		default:
+			return ErrUnsupportedPatchType
+		}
+
+		req, err := productRequestFromPatch(current, doc)
+		if err != nil {
+			return err
+		}
+		if err := checkProductRequest(req); err != nil {
+			return err
+		}
+		response, err = scoped.UpdateProduct(id, req)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrProductNotFound) || errors.Is(err, ErrSKUExists) {
+			return nil, err
+		}
+		if field, reason, ok := productInputError(err); ok {
+			return nil, &ProductValidationError{Field: field, Reason: reason}
+		}
+		return nil, err
+	}
+	return response, nil
+}
+
+// productPatchDocument convierte una ProductRequest al documento JSON genérico que se parchea
+func productPatchDocument(req models.ProductRequest) (map[string]interface{}, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// productRequestFromPatch convierte el documento parcheado en una ProductRequest para UpdateProduct
+// Se compara con el documento original para respetar lo que no cambió: una categoría cambiada por
+// nombre descarta el category_id anterior, y las etiquetas o atributos sin tocar se conservan
+func productRequestFromPatch(current map[string]interface{}, patched interface{}) (models.ProductRequest, error) {
+	var req models.ProductRequest
+	doc, ok := patched.(map[string]interface{})
+	if !ok {
+		return req, &JSONPatchError{Reason: i18n.Msg("patch.not_object")}
+	}
+
+	fields := make([]string, 0, len(doc))
+	for field := range doc {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		if !productPatchFields[field] {
+			return req, &productFieldError{field: field, reason: i18n.Msg("product_input.not_patchable")}
+		}
+	}
+
+	if !reflect.DeepEqual(current["category"], doc["category"]) && reflect.DeepEqual(current["category_id"], doc["category_id"]) {
+		delete(doc, "category_id")
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return req, err
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return req, &productFieldError{field: typeErr.Field, reason: i18n.Msg("product_input.invalid_type")}
+		}
+		return req, err
+	}
+
+	// UpdateProduct conserva las etiquetas y atributos nulos; si el parche los quitó hay que vaciarlos
+	switch {
+	case reflect.DeepEqual(current["tags"], doc["tags"]):
+		req.Tags = nil
+	case req.Tags == nil:
+		req.Tags = []string{}
+	}
+	switch {
+	case reflect.DeepEqual(current["attributes"], doc["attributes"]):
+		req.Attributes = nil
+	case req.Attributes == nil:
+		req.Attributes = map[string]interface{}{}
+	}
+	return req, nil
+}
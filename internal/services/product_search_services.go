@@ -0,0 +1,123 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"inventory-api/internal/models"
+)
+
+// Límites de la búsqueda de productos
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// productTSQuery combina la consulta en español y en inglés, como la columna search_vector
+const productTSQuery = "(websearch_to_tsquery('spanish', @q) || websearch_to_tsquery('english', @q))"
+
+// fullTextSearchSQL busca por palabras con ranking y genera el fragmento resaltado en el
+// idioma que haya encontrado coincidencias
+const fullTextSearchSQL = `
+SELECT ranked.*,
+	CASE WHEN position('<mark>' in ranked.snippet_es) > 0 THEN ranked.snippet_es ELSE ranked.snippet_en END AS snippet
+FROM (
+	SELECT p.*,
+		ts_rank_cd(p.search_vector, q.query) AS rank,
+		ts_headline('spanish', p.name || ' — ' || coalesce(p.description, ''), q.query, @options) AS snippet_es,
+		ts_headline('english', p.name || ' — ' || coalesce(p.description, ''), q.query, @options) AS snippet_en
+	FROM products p, (SELECT ` + productTSQuery + ` AS query) q
+	WHERE p.organization_id = @org AND p.deleted_at IS NULL AND p.search_vector @@ q.query
+	ORDER BY rank DESC, p.id
+	LIMIT @limit
+) ranked
+ORDER BY ranked.rank DESC, ranked.id`
+
+// fuzzySearchSQL busca por similitud de trigramas para tolerar errores de escritura
+// Los operadores % y <% usan los índices GIN gin_trgm_ops
+const fuzzySearchSQL = `
+SELECT p.*,
+	GREATEST(similarity(p.name, @q), word_similarity(@q, p.name), word_similarity(@q, coalesce(p.description, ''))) AS rank,
+	p.name AS snippet
+FROM products p
+WHERE p.organization_id = @org AND p.deleted_at IS NULL
+	AND (p.name % @q OR @q <% p.name OR @q <% p.description)
+ORDER BY rank DESC, p.id
+LIMIT @limit`
+
+// Opciones de ts_headline para los fragmentos resaltados
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=25, MinWords=8, MaxFragments=2, FragmentDelimiter=\" … \""
+
+// productSearchRow es una fila de producto con su relevancia y fragmento
+type productSearchRow struct {
+	models.Product
+	Rank    float64
+	Snippet string
+}
+
+// SearchProducts busca productos por texto completo ordenados por relevancia
+// Si no hay coincidencias de palabras recurre a la similitud de trigramas (errores de escritura)
+func (ps *ProductService) SearchProducts(query string, limit int) ([]models.ProductSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	results := []models.ProductSearchResult{}
+	if query == "" {
+		return results, nil
+	}
+
+	params := map[string]interface{}{
+		"q":       query,
+		"org":     ps.orgID,
+		"limit":   limit,
+		"options": headlineOptions,
+	}
+
+	var rows []productSearchRow
+	if err := ps.db.Raw(fullTextSearchSQL, params).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to search products: %w", err)
+	}
+	match := models.SearchMatchFullText
+
+	if len(rows) == 0 {
+		if err := ps.db.Raw(fuzzySearchSQL, params).Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to search products: %w", err)
+		}
+		match = models.SearchMatchFuzzy
+	}
+
+	products := make([]*models.Product, len(rows))
+	for i := range rows {
+		products[i] = &rows[i].Product
+	}
+	if err := loadProductTags(ps.db, products); err != nil {
+		return nil, err
+	}
+
+	rules, err := ps.stockRules()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		results = append(results, models.ProductSearchResult{
+			ProductResponse: row.Product.ToResponse(rules),
+			Rank:            row.Rank,
+			Snippet:         escapeSnippet(row.Snippet),
+			Match:           match,
+		})
+	}
+	return results, nil
+}
+
+// escapeSnippet escapa el HTML del texto del producto y conserva solo las marcas de resaltado
+func escapeSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, "&lt;mark&gt;", "<mark>")
+	return strings.ReplaceAll(escaped, "&lt;/mark&gt;", "</mark>")
+}
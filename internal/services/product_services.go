@@ -6,14 +6,22 @@ import (
 	"sync"
 	"time"
 
+	"inventory-api/internal/i18n"
 	"inventory-api/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ProductService maneja la lógica de negocio de productos
+// Todas las consultas se limitan a la organización indicada con WithTenant
+// y los cambios se atribuyen en la auditoría al autor indicado con WithActor
 type ProductService struct {
-	db *gorm.DB
+	db      *gorm.DB
+	orgID   uint
+	audit   models.AuditContext
+	lang    i18n.Language   // Idioma de los mensajes generados (alertas)
+	pending *[]ProductEvent // Eventos aplazados hasta confirmar la transacción exterior (importaciones)
 }
 
 // NewProductService crea una nueva instancia del servicio de productos
@@ -21,109 +29,221 @@ func NewProductService(db *gorm.DB) *ProductService {
 	return &ProductService{db: db}
 }
 
+// WithTenant retorna una copia del servicio limitada a la organización indicada
+func (ps *ProductService) WithTenant(orgID uint) *ProductService {
+	scoped := *ps
+	scoped.orgID = orgID
+	return &scoped
+}
+
+// WithActor retorna una copia del servicio que atribuye los cambios al autor indicado
+func (ps *ProductService) WithActor(audit models.AuditContext) *ProductService {
+	scoped := *ps
+	scoped.audit = audit
+	return &scoped
+}
+
+// WithLanguage retorna una copia del servicio que genera los mensajes en el idioma indicado
+func (ps *ProductService) WithLanguage(lang i18n.Language) *ProductService {
+	scoped := *ps
+	scoped.lang = lang
+	return &scoped
+}
+
+// tenant retorna una consulta de productos filtrada por la organización actual
+// Sin organización (orgID 0) no coincide ninguna fila
+func (ps *ProductService) tenant() *gorm.DB {
+	return ps.db.Model(&models.Product{}).Where("organization_id = ?", ps.orgID)
+}
+
 // CreateProduct crea un nuevo producto
 func (ps *ProductService) CreateProduct(req models.ProductRequest) (*models.ProductResponse, error) {
+	if ps.orgID == 0 {
+		return nil, ErrOrganizationRequired
+	}
+
+	sku := models.NormalizeSKU(req.SKU)
+	if err := ps.checkSKUAvailable(sku, 0); err != nil {
+		return nil, err
+	}
+
+	category, err := resolveProductCategory(ps.db, ps.orgID, req.CategoryID, req.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := ps.productAttributes(category.ID, req.Attributes, false)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := resolveProductTags(ps.db, ps.orgID, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
 	product := models.Product{
-		Name:        req.Name,
-		Description: req.Description,
-		Quantity:    req.Quantity,
-		Price:       req.Price,
-		Category:    req.Category,
+		OrganizationID: ps.orgID,
+		SKU:            sku,
+		Name:           req.Name,
+		Description:    req.Description,
+		Quantity:       req.Quantity,
+		ReorderPoint:   req.ReorderPoint,
+		Price:          req.Price,
+		CategoryID:     &category.ID,
+		Category:       category.Name,
+		Tags:           tags,
+		Attributes:     attributes,
 	}
 
-	if err := ps.db.Create(&product).Error; err != nil {
-		return nil, fmt.Errorf("failed to create product: %w", err)
+	err = ps.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&product).Error; err != nil {
+			return fmt.Errorf("failed to create product: %w", err)
+		}
+		if err := ps.recordVersion(tx, &product, models.ProductChangeCreate); err != nil {
+			return err
+		}
+		return recordAudit(tx, ps.audit, models.AuditActionCreate, models.AuditEntityProduct,
+			product.ID, product.OrganizationID, nil, product.AuditFields())
+	})
+	if err != nil {
+		return nil, err
 	}
+	ps.publish(ProductEventSaved, product)
 
-	response := product.ToResponse()
-	return &response, nil
+	return ps.productResponse(&product)
 }
 
 // GetAllProducts obtiene todos los productos
 func (ps *ProductService) GetAllProducts() ([]models.ProductResponse, error) {
 	var products []models.Product
-	if err := ps.db.Find(&products).Error; err != nil {
+	if err := ps.tenant().Preload("Tags").Find(&products).Error; err != nil {
 		return nil, fmt.Errorf("failed to fetch products: %w", err)
 	}
 
-	var responses []models.ProductResponse
-	for _, product := range products {
-		responses = append(responses, product.ToResponse())
-	}
-
-	return responses, nil
+	return ps.productResponses(products)
 }
 
 // GetProductByID obtiene un producto por su ID
 func (ps *ProductService) GetProductByID(id uint) (*models.ProductResponse, error) {
 	var product models.Product
-	if err := ps.db.First(&product, id).Error; err != nil {
+	if err := ps.tenant().Preload("Tags").First(&product, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("product not found")
+			return nil, ErrProductNotFound
 		}
 		return nil, fmt.Errorf("failed to fetch product: %w", err)
 	}
 
-	response := product.ToResponse()
-	return &response, nil
+	return ps.productResponse(&product)
 }
 
 // UpdateProduct actualiza un producto existente
 func (ps *ProductService) UpdateProduct(id uint, req models.ProductRequest) (*models.ProductResponse, error) {
 	var product models.Product
-	if err := ps.db.First(&product, id).Error; err != nil {
+	if err := ps.tenant().Preload("Tags").First(&product, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("product not found")
+			return nil, ErrProductNotFound
 		}
 		return nil, fmt.Errorf("failed to fetch product: %w", err)
 	}
 
+	sku := models.NormalizeSKU(req.SKU)
+	if err := ps.checkSKUAvailable(sku, product.ID); err != nil {
+		return nil, err
+	}
+
+	category, err := resolveProductCategory(ps.db, ps.orgID, req.CategoryID, req.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sin atributos en la petición se conservan los actuales que sigan definidos en la categoría
+	attributeValues, keepAttributes := req.Attributes, req.Attributes == nil
+	if keepAttributes {
+		attributeValues = product.AttributeValues()
+	}
+	attributes, err := ps.productAttributes(category.ID, attributeValues, keepAttributes)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := product.Tags
+	if req.Tags != nil {
+		if tags, err = resolveProductTags(ps.db, ps.orgID, req.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	before := product.AuditFields()
+
 	// Actualizar campos
+	product.SKU = sku
 	product.Name = req.Name
 	product.Description = req.Description
 	product.Quantity = req.Quantity
+	product.ReorderPoint = req.ReorderPoint
 	product.Price = req.Price
-	product.Category = req.Category
+	product.CategoryID = &category.ID
+	product.Category = category.Name
+	product.Tags = tags
+	product.Attributes = attributes
 
-	if err := ps.db.Save(&product).Error; err != nil {
+	if err := ps.save(&product, before, models.ProductChangeUpdate); err != nil {
 		return nil, fmt.Errorf("failed to update product: %w", err)
 	}
 
-	response := product.ToResponse()
-	return &response, nil
+	return ps.productResponse(&product)
 }
 
 // DeleteProduct elimina un producto (soft delete)
 func (ps *ProductService) DeleteProduct(id uint) error {
 	var product models.Product
-	if err := ps.db.First(&product, id).Error; err != nil {
+	if err := ps.tenant().Preload("Tags").First(&product, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("product not found")
+			return ErrProductNotFound
 		}
 		return fmt.Errorf("failed to fetch product: %w", err)
 	}
 
-	if err := ps.db.Delete(&product).Error; err != nil {
-		return fmt.Errorf("failed to delete product: %w", err)
+	err := ps.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&product).Error; err != nil {
+			return fmt.Errorf("failed to delete product: %w", err)
+		}
+		return recordAudit(tx, ps.audit, models.AuditActionDelete, models.AuditEntityProduct,
+			product.ID, product.OrganizationID, product.AuditFields(), nil)
+	})
+	if err != nil {
+		return err
 	}
 
+	ps.publish(ProductEventRemoved, product)
 	return nil
 }
 
-// GetLowStockProducts obtiene productos con stock bajo
+// GetLowStockProducts obtiene productos con stock bajo: los que no están en estado normal según
+// sus reglas de stock o, con un umbral explícito, los que tienen menos unidades que el umbral
 func (ps *ProductService) GetLowStockProducts(threshold int) ([]models.ProductResponse, error) {
-	if threshold <= 0 {
-		threshold = 5 // Valor por defecto
+	rules, err := ps.stockRules()
+	if err != nil {
+		return nil, err
+	}
+
+	query := ps.tenant().Preload("Tags")
+	if threshold > 0 {
+		query = query.Where("quantity < ?", threshold)
+	} else {
+		status, args := stockStatusSQL(rules)
+		query = query.Where(status+" <> ?", append(args, models.StockStatusNormal)...)
 	}
 
 	var products []models.Product
-	if err := ps.db.Where("quantity < ?", threshold).Find(&products).Error; err != nil {
+	if err := query.Find(&products).Error; err != nil {
 		return nil, fmt.Errorf("failed to fetch low stock products: %w", err)
 	}
 
 	var responses []models.ProductResponse
 	for _, product := range products {
-		responses = append(responses, product.ToResponse())
+		responses = append(responses, product.ToResponse(rules))
 	}
 
 	return responses, nil
@@ -132,27 +252,24 @@ func (ps *ProductService) GetLowStockProducts(threshold int) ([]models.ProductRe
 // GetProductsByCategory obtiene productos por categoría
 func (ps *ProductService) GetProductsByCategory(category string) ([]models.ProductResponse, error) {
 	var products []models.Product
-	if err := ps.db.Where("category = ?", category).Find(&products).Error; err != nil {
+	if err := ps.tenant().Preload("Tags").Where("category = ?", category).Find(&products).Error; err != nil {
 		return nil, fmt.Errorf("failed to fetch products by category: %w", err)
 	}
 
-	var responses []models.ProductResponse
-	for _, product := range products {
-		responses = append(responses, product.ToResponse())
-	}
-
-	return responses, nil
+	return ps.productResponses(products)
 }
 
 // GenerateAlertsWithConcurrency genera alertas de stock bajo usando concurrencia
+// Sin umbral (threshold 0) se usan las reglas de stock de cada producto
 func (ps *ProductService) GenerateAlertsWithConcurrency(threshold int) ([]models.ProductAlert, error) {
-	if threshold <= 0 {
-		threshold = 5
+	rules, err := ps.stockRules()
+	if err != nil {
+		return nil, err
 	}
 
 	// Obtener todos los productos
 	var products []models.Product
-	if err := ps.db.Find(&products).Error; err != nil {
+	if err := ps.tenant().Find(&products).Error; err != nil {
 		return nil, fmt.Errorf("failed to fetch products: %w", err)
 	}
 
@@ -165,12 +282,12 @@ func (ps *ProductService) GenerateAlertsWithConcurrency(threshold int) ([]models
 		wg.Add(1)
 		go func(p models.Product) {
 			defer wg.Done()
-			
+
 			// Simular procesamiento más complejo
 			time.Sleep(10 * time.Millisecond)
-			
+
 			// Generar alerta si es necesario
-			if alert := p.GenerateAlert(threshold); alert != nil {
+			if alert := p.GenerateAlert(rules, threshold, ps.lang); alert != nil {
 				alertsChan <- alert
 			}
 		}(product)
@@ -197,35 +314,51 @@ func (ps *ProductService) GetInventoryStats() (map[string]interface{}, error) {
 
 	// Total de productos
 	var totalProducts int64
-	if err := ps.db.Model(&models.Product{}).Count(&totalProducts).Error; err != nil {
+	if err := ps.tenant().Count(&totalProducts).Error; err != nil {
 		return nil, fmt.Errorf("failed to count products: %w", err)
 	}
 	stats["total_products"] = totalProducts
 
 	// Valor total del inventario
 	var totalValue float64
-	if err := ps.db.Model(&models.Product{}).Select("SUM(price * quantity)").Scan(&totalValue).Error; err != nil {
+	if err := ps.tenant().Select("COALESCE(SUM(price * quantity), 0)").Scan(&totalValue).Error; err != nil {
 		return nil, fmt.Errorf("failed to calculate total value: %w", err)
 	}
 	stats["total_value"] = totalValue
 
-	// Productos con stock bajo (< 5)
-	var lowStockCount int64
-	if err := ps.db.Model(&models.Product{}).Where("quantity < ?", 5).Count(&lowStockCount).Error; err != nil {
-		return nil, fmt.Errorf("failed to count low stock products: %w", err)
+	// Productos por estado de stock, con las mismas reglas que las respuestas
+	rules, err := ps.stockRules()
+	if err != nil {
+		return nil, err
 	}
-	stats["low_stock_count"] = lowStockCount
-
-	// Productos sin stock
-	var outOfStockCount int64
-	if err := ps.db.Model(&models.Product{}).Where("quantity = 0").Count(&outOfStockCount).Error; err != nil {
-		return nil, fmt.Errorf("failed to count out of stock products: %w", err)
+	status, args := stockStatusSQL(rules)
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err := ps.tenant().Select(status+" AS status, COUNT(*) AS count", args...).
+		Group("status").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count products by stock status: %w", err)
+	}
+	statusCounts := map[string]int64{
+		models.StockStatusOutOfStock: 0,
+		models.StockStatusCritical:   0,
+		models.StockStatusLow:        0,
+		models.StockStatusNormal:     0,
+	}
+	for _, row := range rows {
+		statusCounts[row.Status] = row.Count
 	}
-	stats["out_of_stock_count"] = outOfStockCount
+	stats["stock_status_counts"] = statusCounts
 
-	// Categorías disponibles
+	// Productos con stock bajo: todos los que no están en estado normal, incluidos los agotados
+	stats["low_stock_count"] = totalProducts - statusCounts[models.StockStatusNormal]
+	stats["out_of_stock_count"] = statusCounts[models.StockStatusOutOfStock]
+
+	// Categorías de la organización
 	var categories []string
-	if err := ps.db.Model(&models.Product{}).Distinct("category").Pluck("category", &categories).Error; err != nil {
+	if err := ps.db.Model(&models.Category{}).Where("organization_id = ?", ps.orgID).
+		Order("name").Pluck("name", &categories).Error; err != nil {
 		return nil, fmt.Errorf("failed to fetch categories: %w", err)
 	}
 	stats["categories"] = categories
@@ -234,38 +367,205 @@ func (ps *ProductService) GetInventoryStats() (map[string]interface{}, error) {
 	return stats, nil
 }
 
-// SearchProducts busca productos por nombre o descripción
-func (ps *ProductService) SearchProducts(query string) ([]models.ProductResponse, error) {
-	var products []models.Product
-	searchPattern := "%" + query + "%"
-	
-	if err := ps.db.Where("name ILIKE ? OR description ILIKE ?", searchPattern, searchPattern).Find(&products).Error; err != nil {
-		return nil, fmt.Errorf("failed to search products: %w", err)
+// UpdateStock actualiza solo el stock de un producto
+func (ps *ProductService) UpdateStock(id uint, newQuantity int) (*models.ProductResponse, error) {
+	var product models.Product
+	if err := ps.tenant().Preload("Tags").First(&product, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch product: %w", err)
 	}
 
-	var responses []models.ProductResponse
-	for _, product := range products {
-		responses = append(responses, product.ToResponse())
+	before := product.AuditFields()
+	product.Quantity = newQuantity
+	if err := ps.save(&product, before, models.ProductChangeStock); err != nil {
+		return nil, fmt.Errorf("failed to update stock: %w", err)
 	}
 
-	return responses, nil
+	return ps.productResponse(&product)
 }
 
-// UpdateStock actualiza solo el stock de un producto
-func (ps *ProductService) UpdateStock(id uint, newQuantity int) (*models.ProductResponse, error) {
+// save guarda un producto modificado (y sus etiquetas si cambiaron), añade una versión a su
+// historial y registra en la auditoría los campos que cambiaron. Sin cambios reales no se crea versión
+func (ps *ProductService) save(product *models.Product, before map[string]interface{}, changeType string) error {
+	err := ps.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(product).Error; err != nil {
+			return err
+		}
+		after := product.AuditFields()
+		if before["tags"] != after["tags"] {
+			if err := tx.Model(product).Association("Tags").Replace(product.Tags); err != nil {
+				return err
+			}
+		}
+		if len(models.DiffAuditFields(before, after)) > 0 {
+			if err := ps.recordVersion(tx, product, changeType); err != nil {
+				return err
+			}
+		}
+		return recordAudit(tx, ps.audit, models.AuditActionUpdate, models.AuditEntityProduct,
+			product.ID, product.OrganizationID, before, after)
+	})
+	if err != nil {
+		return err
+	}
+
+	ps.publish(ProductEventSaved, *product)
+	return nil
+}
+
+// GetProductHistory obtiene todas las versiones de un producto, de la más antigua a la más reciente
+// Incluye productos eliminados para poder consultar su historial
+func (ps *ProductService) GetProductHistory(id uint) ([]models.ProductVersion, error) {
 	var product models.Product
-	if err := ps.db.First(&product, id).Error; err != nil {
+	if err := ps.tenant().Unscoped().Select("id").First(&product, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("product not found")
+			return nil, ErrProductNotFound
 		}
 		return nil, fmt.Errorf("failed to fetch product: %w", err)
 	}
 
-	product.Quantity = newQuantity
-	if err := ps.db.Save(&product).Error; err != nil {
-		return nil, fmt.Errorf("failed to update stock: %w", err)
+	versions := []models.ProductVersion{}
+	if err := ps.db.Where("product_id = ?", product.ID).Order("version ASC").Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch product history: %w", err)
 	}
+	return versions, nil
+}
 
-	response := product.ToResponse()
+// GetProductAsOf obtiene un producto tal como estaba en el instante indicado
+// Retorna "product not found" si aún no existía o ya estaba eliminado en esa fecha
+func (ps *ProductService) GetProductAsOf(id uint, asOf time.Time) (*models.ProductResponse, *models.ProductVersion, error) {
+	var product models.Product
+	if err := ps.tenant().Unscoped().First(&product, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrProductNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to fetch product: %w", err)
+	}
+	if product.DeletedAt != nil && product.DeletedAt.Valid && !product.DeletedAt.Time.After(asOf) {
+		return nil, nil, ErrProductNotFound
+	}
+
+	var version models.ProductVersion
+	err := ps.db.Where("product_id = ? AND valid_from <= ?", product.ID, asOf).
+		Order("valid_from DESC, version DESC").
+		First(&version).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrProductNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to fetch product version: %w", err)
+	}
+
+	rules, err := ps.stockRules()
+	if err != nil {
+		return nil, nil, err
+	}
+	response := version.ToResponse(&product, rules)
+	return &response, &version, nil
+}
+
+// productResponse convierte un producto en su respuesta con las reglas de stock de la organización
+func (ps *ProductService) productResponse(product *models.Product) (*models.ProductResponse, error) {
+	rules, err := ps.stockRules()
+	if err != nil {
+		return nil, err
+	}
+	response := product.ToResponse(rules)
 	return &response, nil
-}
\ No newline at end of file
+}
+
+// productResponses convierte una lista de productos en sus respuestas
+func (ps *ProductService) productResponses(products []models.Product) ([]models.ProductResponse, error) {
+	rules, err := ps.stockRules()
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []models.ProductResponse
+	for _, product := range products {
+		responses = append(responses, product.ToResponse(rules))
+	}
+
+	return responses, nil
+}
+
+// errRolledBack deshace una transacción sin que sea un error para quien la inició
+// (simulaciones, o lotes que no se confirman porque alguna operación falló)
+var errRolledBack = errors.New("transaction rolled back")
+
+// transaction ejecuta fn con una copia del servicio que trabaja dentro de una transacción
+// Los eventos de producto se aplazan hasta confirmarla; anidada, crea un savepoint y sus
+// eventos pasan a la transacción exterior
+func (ps *ProductService) transaction(fn func(scoped *ProductService) error) error {
+	var events []ProductEvent
+	err := ps.db.Transaction(func(tx *gorm.DB) error {
+		scoped := *ps
+		scoped.db = tx
+		scoped.pending = &events
+		return fn(&scoped)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		ps.publish(event.Type, event.Product)
+	}
+	return nil
+}
+
+// publish notifica un cambio de producto, o lo aplaza si el servicio trabaja dentro de una
+// transacción exterior que todavía puede deshacerse
+func (ps *ProductService) publish(eventType string, product models.Product) {
+	if ps.pending != nil {
+		*ps.pending = append(*ps.pending, ProductEvent{Type: eventType, Product: product})
+		return
+	}
+	publishProductEvent(eventType, product)
+}
+
+// recordVersion añade el estado actual del producto a su historial
+// Debe llamarse después de escribir el producto en la misma transacción: el bloqueo
+// de esa fila serializa los cambios concurrentes y la numeración no se duplica
+func (ps *ProductService) recordVersion(tx *gorm.DB, product *models.Product, changeType string) error {
+	var last int
+	if err := tx.Model(&models.ProductVersion{}).Where("product_id = ?", product.ID).
+		Select("COALESCE(MAX(version), 0)").Scan(&last).Error; err != nil {
+		return fmt.Errorf("failed to fetch product version: %w", err)
+	}
+
+	version := models.NewProductVersion(product, changeType, ps.audit, product.UpdatedAt)
+	version.Version = last + 1
+	if err := tx.Create(&version).Error; err != nil {
+		return fmt.Errorf("failed to record product version: %w", err)
+	}
+	return nil
+}
+
+// productAttributes valida los atributos de un producto contra las definiciones de su categoría
+func (ps *ProductService) productAttributes(categoryID uint, values map[string]interface{}, dropUnknown bool) (models.ProductAttributes, error) {
+	definitions, err := categoryAttributeDefinitions(ps.db, ps.orgID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	return validateProductAttributes(definitions, values, dropUnknown)
+}
+
+// checkSKUAvailable verifica que ningún otro producto de la organización (incluida la
+// papelera) use el SKU. El índice único idx_products_org_sku lo garantiza igualmente
+func (ps *ProductService) checkSKUAvailable(sku *string, productID uint) error {
+	if sku == nil {
+		return nil
+	}
+
+	var count int64
+	if err := ps.tenant().Unscoped().Where("sku = ? AND id <> ?", *sku, productID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check sku: %w", err)
+	}
+	if count > 0 {
+		return ErrSKUExists
+	}
+	return nil
+}
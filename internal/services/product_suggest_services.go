@@ -0,0 +1,323 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"inventory-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Límites de las sugerencias de productos
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 25
+)
+
+// Umbral de similitud de trigramas, el mismo que usa pg_trgm por defecto
+const suggestSimilarityThreshold = 0.3
+
+// suggestEntry es un producto preparado para las sugerencias
+type suggestEntry struct {
+	id           uint
+	sku          string
+	name         string
+	category     string
+	normSKU      string
+	normName     string
+	words        []string
+	trigrams     []string   // Ordenados y sin repetir
+	wordTrigrams [][]string // Trigramas de cada palabra del nombre
+}
+
+// suggestOrgIndex contiene los productos activos de una organización
+type suggestOrgIndex struct {
+	entries  map[uint]*suggestEntry
+	loadedAt time.Time
+}
+
+// suggestLoad registra los eventos que llegan mientras se carga una organización
+// La consulta puede no incluirlos, así que se reaplican sobre la carga antes de publicarla
+type suggestLoad struct {
+	events []ProductEvent
+}
+
+// productSuggestIndex mantiene en memoria los productos de cada organización
+// Se carga bajo demanda desde la base de datos y se actualiza con los eventos de ProductService
+// La recarga periódica (SUGGEST_INDEX_TTL) recoge los cambios hechos por otras instancias
+type productSuggestIndex struct {
+	db    *gorm.DB
+	ttl   time.Duration
+	mu    sync.RWMutex
+	orgs  map[uint]*suggestOrgIndex
+	loads map[uint][]*suggestLoad // Cargas en curso de cada organización
+}
+
+var (
+	suggestIndex     *productSuggestIndex
+	suggestIndexOnce sync.Once
+)
+
+// getSuggestIndex retorna el índice de sugerencias, creándolo y suscribiéndolo a los eventos la primera vez
+func getSuggestIndex(db *gorm.DB) *productSuggestIndex {
+	suggestIndexOnce.Do(func() {
+		ttl := envDuration("SUGGEST_INDEX_TTL", 5*time.Minute)
+		if ttl <= 0 {
+			ttl = 5 * time.Minute
+		}
+		suggestIndex = &productSuggestIndex{
+			db:    db,
+			ttl:   ttl,
+			orgs:  map[uint]*suggestOrgIndex{},
+			loads: map[uint][]*suggestLoad{},
+		}
+		OnProductEvent(suggestIndex.handleEvent)
+	})
+	return suggestIndex
+}
+
+// SuggestProducts retorna las mejores coincidencias para autocompletar, por prefijo y similitud de trigramas
+func (ps *ProductService) SuggestProducts(query string, limit int) ([]models.ProductSuggestion, error) {
+	if limit <= 0 {
+		limit = defaultSuggestLimit
+	}
+	if limit > maxSuggestLimit {
+		limit = maxSuggestLimit
+	}
+	suggestions := []models.ProductSuggestion{}
+
+	normQuery := normalizeSuggestText(query)
+	if normQuery == "" {
+		return suggestions, nil
+	}
+
+	index := getSuggestIndex(ps.db)
+	if err := index.ensureLoaded(ps.orgID); err != nil {
+		return nil, err
+	}
+
+	queryTrigrams := suggestTrigrams(normQuery)
+
+	index.mu.RLock()
+	for _, entry := range index.orgs[ps.orgID].entries {
+		if score, match := entry.score(normQuery, queryTrigrams); score > 0 {
+			suggestions = append(suggestions, models.ProductSuggestion{
+				ID:       entry.id,
+				SKU:      entry.sku,
+				Name:     entry.name,
+				Category: entry.category,
+				Score:    score,
+				Match:    match,
+			})
+		}
+	}
+	index.mu.RUnlock()
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		if suggestions[i].Name != suggestions[j].Name {
+			return suggestions[i].Name < suggestions[j].Name
+		}
+		return suggestions[i].ID < suggestions[j].ID
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// ensureLoaded carga los productos de la organización si no están en memoria o han caducado
+func (idx *productSuggestIndex) ensureLoaded(orgID uint) error {
+	idx.mu.RLock()
+	org, ok := idx.orgs[orgID]
+	fresh := ok && time.Since(org.loadedAt) < idx.ttl
+	idx.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	// Registrar la carga antes de la consulta para no perder los eventos que lleguen durante ella
+	load := &suggestLoad{}
+	idx.mu.Lock()
+	loadedAt := time.Now()
+	idx.loads[orgID] = append(idx.loads[orgID], load)
+	idx.mu.Unlock()
+
+	var products []models.Product
+	err := idx.db.Select("id", "organization_id", "sku", "name", "category").
+		Where("organization_id = ?", orgID).Find(&products).Error
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.finishLoad(orgID, load)
+	if err != nil {
+		return fmt.Errorf("failed to load products for suggestions: %w", err)
+	}
+
+	entries := make(map[uint]*suggestEntry, len(products))
+	for i := range products {
+		entries[products[i].ID] = newSuggestEntry(&products[i])
+	}
+	for _, event := range load.events {
+		applySuggestEvent(entries, event)
+	}
+
+	// Otra petición pudo cargarla mientras tanto con datos más recientes
+	if current, ok := idx.orgs[orgID]; ok && current.loadedAt.After(loadedAt) {
+		return nil
+	}
+	idx.orgs[orgID] = &suggestOrgIndex{entries: entries, loadedAt: loadedAt}
+	return nil
+}
+
+// finishLoad quita la carga de las que están en curso; se llama con el mutex tomado
+func (idx *productSuggestIndex) finishLoad(orgID uint, load *suggestLoad) {
+	loads := idx.loads[orgID]
+	for i := range loads {
+		if loads[i] == load {
+			loads = append(loads[:i], loads[i+1:]...)
+			break
+		}
+	}
+	if len(loads) == 0 {
+		delete(idx.loads, orgID)
+		return
+	}
+	idx.loads[orgID] = loads
+}
+
+// handleEvent aplica un cambio de producto a las organizaciones ya cargadas y lo guarda
+// para las cargas en curso, que lo reaplican sobre su consulta
+func (idx *productSuggestIndex) handleEvent(event ProductEvent) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	orgID := event.Product.OrganizationID
+	for _, load := range idx.loads[orgID] {
+		load.events = append(load.events, event)
+	}
+	if org, ok := idx.orgs[orgID]; ok {
+		applySuggestEvent(org.entries, event)
+	}
+}
+
+// applySuggestEvent aplica un cambio de producto a las entradas de una organización
+func applySuggestEvent(entries map[uint]*suggestEntry, event ProductEvent) {
+	if event.Type == ProductEventRemoved || event.Product.DeletedAt != nil {
+		delete(entries, event.Product.ID)
+		return
+	}
+	entries[event.Product.ID] = newSuggestEntry(&event.Product)
+}
+
+// newSuggestEntry prepara un producto para las sugerencias
+func newSuggestEntry(p *models.Product) *suggestEntry {
+	normName := normalizeSuggestText(p.Name)
+	words := strings.Fields(normName)
+	wordTrigrams := make([][]string, len(words))
+	for i, word := range words {
+		wordTrigrams[i] = suggestTrigrams(word)
+	}
+
+	return &suggestEntry{
+		id:           p.ID,
+		sku:          p.SKUValue(),
+		name:         p.Name,
+		category:     p.Category,
+		normSKU:      normalizeSuggestText(p.SKUValue()),
+		normName:     normName,
+		words:        words,
+		trigrams:     suggestTrigrams(normName),
+		wordTrigrams: wordTrigrams,
+	}
+}
+
+// score calcula la relevancia de la entrada para el texto buscado; 0 si no coincide
+func (e *suggestEntry) score(query string, queryTrigrams []string) (float64, string) {
+	if e.normSKU != "" && strings.HasPrefix(e.normSKU, query) {
+		if e.normSKU == query {
+			return 1.1, models.SuggestMatchSKU
+		}
+		return 1.0, models.SuggestMatchSKU
+	}
+
+	if strings.HasPrefix(e.normName, query) {
+		return 1.0, models.SuggestMatchPrefix
+	}
+	for _, word := range e.words {
+		if strings.HasPrefix(word, query) {
+			return 0.9, models.SuggestMatchPrefix
+		}
+	}
+
+	// Se compara con el nombre completo y con cada palabra, para tolerar errores en una sola
+	similarity := trigramSimilarity(queryTrigrams, e.trigrams)
+	for _, trigrams := range e.wordTrigrams {
+		if wordSimilarity := trigramSimilarity(queryTrigrams, trigrams); wordSimilarity > similarity {
+			similarity = wordSimilarity
+		}
+	}
+
+	// Por debajo de los prefijos: una coincidencia aproximada nunca supera a una exacta
+	if similarity >= suggestSimilarityThreshold {
+		return similarity * 0.8, models.SuggestMatchTrigram
+	}
+	return 0, ""
+}
+
+// normalizeSuggestText pasa a minúsculas, quita tildes (para que "cafe" sugiera "Café") y deja solo letras y números separados por espacios
+func normalizeSuggestText(text string) string {
+	text = foldAccents(strings.ToLower(text))
+	return strings.Join(strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// suggestTrigrams genera los trigramas del texto como pg_trgm: cada palabra con dos
+// espacios delante y uno detrás. El resultado está ordenado y sin repetir
+func suggestTrigrams(text string) []string {
+	seen := map[string]bool{}
+	for _, word := range strings.Fields(text) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			seen[string(runes[i:i+3])] = true
+		}
+	}
+
+	trigrams := make([]string, 0, len(seen))
+	for trigram := range seen {
+		trigrams = append(trigrams, trigram)
+	}
+	sort.Strings(trigrams)
+	return trigrams
+}
+
+// trigramSimilarity calcula la similitud de dos conjuntos ordenados de trigramas
+// (compartidos / total distintos), igual que similarity() de pg_trgm
+func trigramSimilarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			shared++
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"inventory-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// StockRuleService maneja las reglas del estado de stock y de la severidad de las alertas
+// Las consultas se limitan a la organización indicada con WithTenant
+// y los cambios se atribuyen en la auditoría al autor indicado con WithActor
+type StockRuleService struct {
+	db    *gorm.DB
+	orgID uint
+	audit models.AuditContext
+}
+
+// NewStockRuleService crea una nueva instancia del servicio de reglas de stock
+func NewStockRuleService(db *gorm.DB) *StockRuleService {
+	return &StockRuleService{db: db}
+}
+
+// WithTenant retorna una copia del servicio limitada a la organización indicada
+func (ss *StockRuleService) WithTenant(orgID uint) *StockRuleService {
+	scoped := *ss
+	scoped.orgID = orgID
+	return &scoped
+}
+
+// WithActor retorna una copia del servicio que atribuye los cambios al autor indicado
+func (ss *StockRuleService) WithActor(audit models.AuditContext) *StockRuleService {
+	scoped := *ss
+	scoped.audit = audit
+	return &scoped
+}
+
+// tenant retorna una consulta de reglas filtrada por la organización actual
+func (ss *StockRuleService) tenant() *gorm.DB {
+	return ss.db.Model(&models.StockRule{}).Where("organization_id = ?", ss.orgID)
+}
+
+// ListStockRules obtiene las reglas de la organización: la global, las de categoría y las de producto
+func (ss *StockRuleService) ListStockRules() ([]models.StockRule, error) {
+	rules := []models.StockRule{}
+	err := ss.tenant().
+		Order("CASE scope WHEN 'global' THEN 0 WHEN 'category' THEN 1 ELSE 2 END, id").
+		Find(&rules).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch stock rules: %w", err)
+	}
+	return rules, nil
+}
+
+// CreateStockRule crea una regla. Cada ámbito admite una sola regla por destino: una global,
+// una por categoría y una por producto
+func (ss *StockRuleService) CreateStockRule(req models.StockRuleRequest) (*models.StockRule, error) {
+	if ss.orgID == 0 {
+		return nil, ErrOrganizationRequired
+	}
+
+	rule := models.StockRule{OrganizationID: ss.orgID, Scope: req.Scope}
+	query := ss.tenant().Where("scope = ?", req.Scope)
+	switch req.Scope {
+	case models.StockRuleScopeCategory:
+		if req.CategoryID == nil || req.ProductID != nil {
+			return nil, ErrInvalidStockRuleTarget
+		}
+		category, err := findCategory(ss.db, ss.orgID, *req.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		rule.CategoryID = &category.ID
+		query = query.Where("category_id = ?", category.ID)
+	case models.StockRuleScopeProduct:
+		if req.ProductID == nil || req.CategoryID != nil {
+			return nil, ErrInvalidStockRuleTarget
+		}
+		var product models.Product
+		err := ss.db.Where("organization_id = ?", ss.orgID).Select("id").First(&product, *req.ProductID).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrProductNotFound
+			}
+			return nil, fmt.Errorf("failed to fetch product: %w", err)
+		}
+		rule.ProductID = &product.ID
+		query = query.Where("product_id = ?", product.ID)
+	default:
+		if req.CategoryID != nil || req.ProductID != nil {
+			return nil, ErrInvalidStockRuleTarget
+		}
+	}
+	if err := applyStockRuleRequest(&rule, req); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if count > 0 {
+		return nil, ErrStockRuleExists
+	}
+
+	err := ss.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rule).Error; err != nil {
+			return fmt.Errorf("failed to create stock rule: %w", err)
+		}
+		return recordAudit(tx, ss.audit, models.AuditActionCreate, models.AuditEntityStockRule,
+			rule.ID, rule.OrganizationID, nil, rule.AuditFields())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// UpdateStockRule cambia el modo, los umbrales o las severidades de una regla
+// El ámbito y el destino no se pueden cambiar: se elimina la regla y se crea otra
+func (ss *StockRuleService) UpdateStockRule(id uint, req models.StockRuleRequest) (*models.StockRule, error) {
+	rule, err := ss.findStockRule(id)
+	if err != nil {
+		return nil, err
+	}
+	if (req.Scope != "" && req.Scope != rule.Scope) ||
+		(req.CategoryID != nil && (rule.CategoryID == nil || *req.CategoryID != *rule.CategoryID)) ||
+		(req.ProductID != nil && (rule.ProductID == nil || *req.ProductID != *rule.ProductID)) {
+		return nil, ErrStockRuleTargetChange
+	}
+	before := rule.AuditFields()
+
+	if err := applyStockRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+
+	err = ss.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(rule).Error; err != nil {
+			return fmt.Errorf("failed to update stock rule: %w", err)
+		}
+		return recordAudit(tx, ss.audit, models.AuditActionUpdate, models.AuditEntityStockRule,
+			rule.ID, rule.OrganizationID, before, rule.AuditFields())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteStockRule elimina una regla; sus productos pasan a la regla del siguiente ámbito
+func (ss *StockRuleService) DeleteStockRule(id uint) error {
+	rule, err := ss.findStockRule(id)
+	if err != nil {
+		return err
+	}
+
+	return ss.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(rule).Error; err != nil {
+			return fmt.Errorf("failed to delete stock rule: %w", err)
+		}
+		return recordAudit(tx, ss.audit, models.AuditActionDelete, models.AuditEntityStockRule,
+			rule.ID, rule.OrganizationID, rule.AuditFields(), nil)
+	})
+}
+
+// findStockRule obtiene una regla de la organización
+func (ss *StockRuleService) findStockRule(id uint) (*models.StockRule, error) {
+	var rule models.StockRule
+	if err := ss.tenant().First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStockRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch stock rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// applyStockRuleRequest copia el modo, los umbrales y las severidades de la petición a la regla
+// El modo y las severidades omitidos conservan los de la regla (al crearla, los valores por
+// defecto). El umbral crítico no puede superar al de stock bajo, ni un porcentaje a 100
+func applyStockRuleRequest(rule *models.StockRule, req models.StockRuleRequest) error {
+	mode := defaultString(req.Mode, defaultString(rule.Mode, models.StockRuleModeAbsolute))
+	if req.CriticalThreshold > req.LowThreshold {
+		return ErrInvalidStockThresholds
+	}
+	if mode == models.StockRuleModePercent && req.LowThreshold > 100 {
+		return ErrInvalidStockPercent
+	}
+	rule.Mode = mode
+	rule.LowThreshold = req.LowThreshold
+	rule.CriticalThreshold = req.CriticalThreshold
+
+	defaults := models.DefaultStockBands
+	rule.LowSeverity = defaultString(req.LowSeverity, defaultString(rule.LowSeverity, defaults.LowSeverity))
+	rule.CriticalSeverity = defaultString(req.CriticalSeverity, defaultString(rule.CriticalSeverity, defaults.CriticalSeverity))
+	rule.OutOfStockSeverity = defaultString(req.OutOfStockSeverity, defaultString(rule.OutOfStockSeverity, defaults.OutOfStockSeverity))
+	return nil
+}
+
+// defaultString retorna value, o fallback si está vacío
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// loadStockRules obtiene las reglas de stock de la organización con el árbol de categorías que
+// necesitan para heredarse. Sin reglas retorna nil, que aplica las bandas por defecto
+func loadStockRules(db *gorm.DB, orgID uint) (*models.StockRules, error) {
+	var rules []models.StockRule
+	if err := db.Where("organization_id = ?", orgID).Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch stock rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	var categories []models.Category
+	if err := db.Select("id", "parent_id").Where("organization_id = ?", orgID).Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch categories: %w", err)
+	}
+	parents := make(map[uint]*uint, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+	return models.NewStockRules(rules, parents), nil
+}
+
+// stockRules obtiene las reglas de stock de la organización del servicio
+func (ps *ProductService) stockRules() (*models.StockRules, error) {
+	return loadStockRules(ps.db, ps.orgID)
+}
+
+// stockStatusSQL construye la expresión SQL del estado de stock de cada producto, que resuelve las
+// reglas en el mismo orden que StockRules.Rule: la del producto, las de su categoría y antecesoras,
+// la global y las bandas por defecto. Así los filtros y los recuentos coinciden con las respuestas
+func stockStatusSQL(rules *models.StockRules) (string, []interface{}) {
+	var sql strings.Builder
+	var args []interface{}
+	when := func(condition string, conditionArgs []interface{}, rule *models.StockRule) {
+		if rule.Mode == models.StockRuleModePercent {
+			condition += " AND products.reorder_point > 0"
+		}
+		bands, bandArgs := stockBandsSQL(rule)
+		sql.WriteString(" WHEN " + condition + " THEN " + bands)
+		args = append(append(args, conditionArgs...), bandArgs...)
+	}
+
+	sql.WriteString("CASE")
+	if rules != nil {
+		productIDs := make([]uint, 0, len(rules.Products))
+		for id := range rules.Products {
+			productIDs = append(productIDs, id)
+		}
+		sort.Slice(productIDs, func(i, j int) bool { return productIDs[i] < productIDs[j] })
+		for _, id := range productIDs {
+			when("products.id = ?", []interface{}{id}, rules.Products[id])
+		}
+
+		// Las reglas heredadas se evalúan por niveles: primero la más cercana de cada categoría y,
+		// si no aplica (porcentaje sin punto de pedido), la siguiente antecesora
+		chains := make(map[uint][]*models.StockRule, len(rules.Parents))
+		depth := 0
+		for id := range rules.Parents {
+			categoryID := id
+			chains[id] = rules.CategoryChain(&categoryID)
+			if len(chains[id]) > depth {
+				depth = len(chains[id])
+			}
+		}
+		for level := 0; level < depth; level++ {
+			byRule := make(map[uint][]uint)
+			levelRules := make(map[uint]*models.StockRule)
+			for categoryID, chain := range chains {
+				if level < len(chain) && !absoluteRuleBefore(chain, level) {
+					rule := chain[level]
+					byRule[rule.ID] = append(byRule[rule.ID], categoryID)
+					levelRules[rule.ID] = rule
+				}
+			}
+			ruleIDs := make([]uint, 0, len(byRule))
+			for id := range byRule {
+				ruleIDs = append(ruleIDs, id)
+			}
+			sort.Slice(ruleIDs, func(i, j int) bool { return ruleIDs[i] < ruleIDs[j] })
+			for _, ruleID := range ruleIDs {
+				categoryIDs := byRule[ruleID]
+				sort.Slice(categoryIDs, func(i, j int) bool { return categoryIDs[i] < categoryIDs[j] })
+				when("products.category_id IN ?", []interface{}{categoryIDs}, levelRules[ruleID])
+			}
+		}
+
+		if rules.Global != nil {
+			when("TRUE", nil, rules.Global)
+		}
+	}
+
+	bands, bandArgs := stockBandsSQL(nil)
+	if len(args) == 0 {
+		return bands, bandArgs
+	}
+	sql.WriteString(" ELSE " + bands + " END")
+	return "(" + sql.String() + ")", append(args, bandArgs...)
+}
+
+// stockBandsSQL construye la expresión del estado de stock con las bandas de una regla
+// (o las bandas por defecto si rule es nil)
+func stockBandsSQL(rule *models.StockRule) (string, []interface{}) {
+	critical, low := "?", "?"
+	args := []interface{}{models.DefaultStockBands.Critical, models.DefaultStockBands.Low}
+	if rule != nil {
+		args = []interface{}{rule.CriticalThreshold, rule.LowThreshold}
+		if rule.Mode == models.StockRuleModePercent {
+			critical = "products.reorder_point * ? / 100.0"
+			low = critical
+		}
+	}
+	return fmt.Sprintf("(CASE WHEN products.quantity = 0 THEN '%s' WHEN products.quantity <= %s THEN '%s' WHEN products.quantity <= %s THEN '%s' ELSE '%s' END)",
+		models.StockStatusOutOfStock, critical, models.StockStatusCritical, low, models.StockStatusLow, models.StockStatusNormal), args
+}
+
+// absoluteRuleBefore indica si alguna regla de la cadena anterior al nivel es absoluta: esas
+// siempre aplican, así que los niveles siguientes nunca se alcanzan
+func absoluteRuleBefore(chain []*models.StockRule, level int) bool {
+	for _, rule := range chain[:level] {
+		if rule.Mode != models.StockRuleModePercent {
+			return true
+		}
+	}
+	return false
+}
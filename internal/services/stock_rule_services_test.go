@@ -0,0 +1,358 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"inventory-api/internal/models"
+)
+
+// sqlRow es una fila de products para evaluar expresiones; una columna NULL es nil
+type sqlRow map[string]interface{}
+
+// sqlEvaluator evalúa el subconjunto de SQL que genera stockStatusSQL (CASE, comparaciones, AND,
+// IN y aritmética con * y /), para comprobar sin base de datos que coincide con StockRules
+// Las expresiones se compilan primero para asignar los argumentos en el orden de sus marcadores
+type sqlEvaluator struct {
+	tokens []string
+	pos    int
+	args   []interface{}
+	arg    int
+}
+
+type sqlExpr func(row sqlRow) interface{}
+
+// compileSQL compila la expresión y comprueba que se consumen todos los tokens y argumentos
+func compileSQL(sql string, args []interface{}) (expr sqlExpr, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	e := &sqlEvaluator{tokens: tokenizeSQL(sql), args: args}
+	expr = e.expr()
+	if e.pos != len(e.tokens) {
+		return nil, fmt.Errorf("unexpected token %q", e.tokens[e.pos])
+	}
+	if e.arg != len(e.args) {
+		return nil, fmt.Errorf("%d placeholders for %d args", e.arg, len(e.args))
+	}
+	return expr, nil
+}
+
+func tokenizeSQL(sql string) []string {
+	var tokens []string
+	for i := 0; i < len(sql); {
+		switch c := sql[i]; {
+		case c == ' ':
+			i++
+		case c == '\'':
+			end := strings.IndexByte(sql[i+1:], '\'') + i + 2
+			tokens = append(tokens, sql[i:end])
+			i = end
+		case strings.HasPrefix(sql[i:], "<=") || strings.HasPrefix(sql[i:], ">="):
+			tokens = append(tokens, sql[i:i+2])
+			i += 2
+		case strings.IndexByte("()?=<>*/", c) >= 0:
+			tokens = append(tokens, string(c))
+			i++
+		default:
+			end := i
+			for end < len(sql) && strings.IndexByte(" ()?=<>*/'", sql[end]) < 0 {
+				end++
+			}
+			tokens = append(tokens, sql[i:end])
+			i = end
+		}
+	}
+	return tokens
+}
+
+func (e *sqlEvaluator) peek() string {
+	if e.pos < len(e.tokens) {
+		return e.tokens[e.pos]
+	}
+	return ""
+}
+
+func (e *sqlEvaluator) next() string {
+	token := e.peek()
+	if token == "" {
+		panic("unexpected end of expression")
+	}
+	e.pos++
+	return token
+}
+
+func (e *sqlEvaluator) expect(token string) {
+	if got := e.next(); got != token {
+		panic(fmt.Sprintf("expected %q, got %q", token, got))
+	}
+}
+
+func (e *sqlEvaluator) expr() sqlExpr {
+	switch token := e.peek(); {
+	case token == "(":
+		e.next()
+		inner := e.expr()
+		e.expect(")")
+		return inner
+	case token == "CASE":
+		return e.caseExpr()
+	case strings.HasPrefix(token, "'"):
+		e.next()
+		value := strings.Trim(token, "'")
+		return func(sqlRow) interface{} { return value }
+	default:
+		return e.arith()
+	}
+}
+
+func (e *sqlEvaluator) caseExpr() sqlExpr {
+	e.expect("CASE")
+	type branch struct {
+		when func(sqlRow) bool
+		then sqlExpr
+	}
+	var branches []branch
+	for e.peek() == "WHEN" {
+		e.next()
+		when := e.cond()
+		e.expect("THEN")
+		branches = append(branches, branch{when, e.expr()})
+	}
+	if len(branches) == 0 {
+		panic("CASE without WHEN")
+	}
+	otherwise := func(sqlRow) interface{} { return nil }
+	if e.peek() == "ELSE" {
+		e.next()
+		otherwise = e.expr()
+	}
+	e.expect("END")
+	return func(row sqlRow) interface{} {
+		for _, b := range branches {
+			if b.when(row) {
+				return b.then(row)
+			}
+		}
+		return otherwise(row)
+	}
+}
+
+func (e *sqlEvaluator) cond() func(sqlRow) bool {
+	comparisons := []func(sqlRow) bool{e.comparison()}
+	for e.peek() == "AND" {
+		e.next()
+		comparisons = append(comparisons, e.comparison())
+	}
+	return func(row sqlRow) bool {
+		for _, comparison := range comparisons {
+			if !comparison(row) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func (e *sqlEvaluator) comparison() func(sqlRow) bool {
+	if e.peek() == "TRUE" {
+		e.next()
+		return func(sqlRow) bool { return true }
+	}
+	left := e.arith()
+	op := e.next()
+	if op == "IN" {
+		e.expect("?")
+		ids, ok := e.args[e.arg].([]uint)
+		if !ok {
+			panic(fmt.Sprintf("IN argument %d is %T, want []uint", e.arg, e.args[e.arg]))
+		}
+		e.arg++
+		return func(row sqlRow) bool {
+			value, ok := left(row).(float64)
+			if !ok {
+				return false
+			}
+			for _, id := range ids {
+				if value == float64(id) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	right := e.arith()
+	return func(row sqlRow) bool {
+		l, lok := left(row).(float64)
+		r, rok := right(row).(float64)
+		if !lok || !rok {
+			return false // NULL
+		}
+		switch op {
+		case "=":
+			return l == r
+		case "<":
+			return l < r
+		case "<=":
+			return l <= r
+		case ">":
+			return l > r
+		case ">=":
+			return l >= r
+		}
+		panic("unknown operator " + op)
+	}
+}
+
+func (e *sqlEvaluator) arith() sqlExpr {
+	left := e.term()
+	for e.peek() == "*" || e.peek() == "/" {
+		op, l, r := e.next(), left, e.term()
+		left = func(row sqlRow) interface{} {
+			a, aok := l(row).(float64)
+			b, bok := r(row).(float64)
+			if !aok || !bok {
+				return nil
+			}
+			if op == "*" {
+				return a * b
+			}
+			return a / b
+		}
+	}
+	return left
+}
+
+func (e *sqlEvaluator) term() sqlExpr {
+	token := e.next()
+	switch {
+	case token == "?":
+		var value float64
+		switch arg := e.args[e.arg].(type) {
+		case int:
+			value = float64(arg)
+		case uint:
+			value = float64(arg)
+		case float64:
+			value = arg
+		default:
+			panic(fmt.Sprintf("argument %d is %T, want a number", e.arg, arg))
+		}
+		e.arg++
+		return func(sqlRow) interface{} { return value }
+	case strings.HasPrefix(token, "products."):
+		return func(row sqlRow) interface{} {
+			value, ok := row[token]
+			if !ok {
+				panic("unknown column " + token)
+			}
+			return value
+		}
+	default:
+		value, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			panic("unexpected token " + token)
+		}
+		return func(sqlRow) interface{} { return value }
+	}
+}
+
+// productRow convierte un producto en la fila que ve la expresión SQL
+func productRow(p *models.Product) sqlRow {
+	row := sqlRow{
+		"products.id":            float64(p.ID),
+		"products.quantity":      float64(p.Quantity),
+		"products.reorder_point": nil,
+		"products.category_id":   nil,
+	}
+	if p.ReorderPoint != nil {
+		row["products.reorder_point"] = float64(*p.ReorderPoint)
+	}
+	if p.CategoryID != nil {
+		row["products.category_id"] = float64(*p.CategoryID)
+	}
+	return row
+}
+
+// TestStockStatusSQLMatchesRules comprueba que la expresión SQL da el mismo estado que
+// StockRules y StockBands.Status, incluidas las cantidades iguales a los umbrales
+func TestStockStatusSQLMatchesRules(t *testing.T) {
+	categoryID := func(id uint) *uint { return &id }
+	rule := func(id uint, scope, mode string, category, product *uint, low, critical float64) models.StockRule {
+		return models.StockRule{ID: id, Scope: scope, Mode: mode, CategoryID: category, ProductID: product,
+			LowThreshold: low, CriticalThreshold: critical}
+	}
+	// 1 (porcentaje) → 2 (absoluta) → 3; 4 → 5 (porcentaje) → 6 (porcentaje); 7 sin regla
+	parents := map[uint]*uint{1: nil, 2: categoryID(1), 3: categoryID(2), 4: nil, 5: categoryID(4), 6: categoryID(5), 7: nil}
+	categoryRules := []models.StockRule{
+		rule(2, models.StockRuleScopeCategory, models.StockRuleModePercent, categoryID(1), nil, 50, 20),
+		rule(3, models.StockRuleScopeCategory, models.StockRuleModeAbsolute, categoryID(2), nil, 8, 3),
+		rule(4, models.StockRuleScopeCategory, models.StockRuleModePercent, categoryID(5), nil, 40, 10),
+		rule(5, models.StockRuleScopeCategory, models.StockRuleModePercent, categoryID(6), nil, 100, 25),
+		rule(6, models.StockRuleScopeProduct, models.StockRuleModePercent, nil, categoryID(100), 30, 10),
+		rule(7, models.StockRuleScopeProduct, models.StockRuleModeAbsolute, nil, categoryID(101), 2, 0),
+	}
+	global := rule(1, models.StockRuleScopeGlobal, models.StockRuleModeAbsolute, nil, nil, 6, 1)
+
+	ruleSets := map[string]*models.StockRules{
+		"nil rules":           nil,
+		"no rules":            models.NewStockRules(nil, parents),
+		"global only":         models.NewStockRules([]models.StockRule{global}, parents),
+		"without global":      models.NewStockRules(categoryRules, parents),
+		"with global":         models.NewStockRules(append([]models.StockRule{global}, categoryRules...), parents),
+		"percent global only": models.NewStockRules([]models.StockRule{rule(1, models.StockRuleScopeGlobal, models.StockRuleModePercent, nil, nil, 50, 20)}, parents),
+	}
+
+	var categories []*uint
+	categories = append(categories, nil)
+	for id := uint(1); id <= 8; id++ { // 8 no está en el árbol
+		categories = append(categories, categoryID(id))
+	}
+	reorderPoints := []*int{nil}
+	for _, value := range []int{0, 4, 10, 20} {
+		value := value
+		reorderPoints = append(reorderPoints, &value)
+	}
+
+	for name, rules := range ruleSets {
+		t.Run(name, func(t *testing.T) {
+			sql, args := stockStatusSQL(rules)
+			expr, err := compileSQL(sql, args)
+			if err != nil {
+				t.Fatalf("cannot evaluate %s: %v", sql, err)
+			}
+			for _, id := range []uint{1, 100, 101} {
+				for _, category := range categories {
+					for _, reorderPoint := range reorderPoints {
+						for quantity := 0; quantity <= 12; quantity++ {
+							product := models.Product{ID: id, Quantity: quantity, CategoryID: category, ReorderPoint: reorderPoint}
+							want := rules.Bands(&product).Status(quantity)
+							if got := expr(productRow(&product)); got != want {
+								t.Fatalf("product %d, category %v, reorder point %v, quantity %d: SQL status %v, want %s",
+									id, derefUint(category), derefInt(reorderPoint), quantity, got, want)
+							}
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func derefUint(v *uint) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func derefInt(v *int) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"inventory-api/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListTags obtiene las etiquetas de la organización con el número de productos activos que las usan
+func (ps *ProductService) ListTags() ([]models.TagResponse, error) {
+	tags := []models.TagResponse{}
+	err := ps.db.Model(&models.Tag{}).
+		Select("tags.id, tags.name, tags.slug, COUNT(products.id) AS product_count").
+		Joins("LEFT JOIN product_tags ON product_tags.tag_id = tags.id").
+		Joins("LEFT JOIN products ON products.id = product_tags.product_id AND products.deleted_at IS NULL").
+		Where("tags.organization_id = ?", ps.orgID).
+		Group("tags.id, tags.name, tags.slug").
+		Order("tags.name").
+		Scan(&tags).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+	return tags, nil
+}
+
+// resolveProductTags obtiene las etiquetas con los nombres indicados, creando las que no existan
+// Los nombres con el mismo slug ("Wireless", "wireless") son la misma etiqueta
+func resolveProductTags(db *gorm.DB, orgID uint, names []string) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	bySlug := make(map[string]string)
+	var slugs []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		slug := slugify(name)
+		if slug == "" || len(name) > 50 {
+			return nil, ErrInvalidTag
+		}
+		if _, ok := bySlug[slug]; !ok {
+			bySlug[slug] = name
+			slugs = append(slugs, slug)
+		}
+	}
+	if len(slugs) == 0 {
+		return tags, nil
+	}
+	if len(slugs) > models.MaxProductTags {
+		return nil, ErrTooManyTags
+	}
+
+	missing := make([]models.Tag, 0, len(slugs))
+	var existing []models.Tag
+	if err := db.Where("organization_id = ? AND slug IN ?", orgID, slugs).Find(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+	found := make(map[string]bool, len(existing))
+	for _, tag := range existing {
+		found[tag.Slug] = true
+	}
+	for _, slug := range slugs {
+		if !found[slug] {
+			missing = append(missing, models.Tag{OrganizationID: orgID, Name: bySlug[slug], Slug: slug})
+		}
+	}
+	if len(missing) == 0 {
+		return existing, nil
+	}
+
+	// Si otra petición las creó a la vez, se usan las existentes
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&missing).Error; err != nil {
+		return nil, fmt.Errorf("failed to create tags: %w", err)
+	}
+	if err := db.Where("organization_id = ? AND slug IN ?", orgID, slugs).Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+	return tags, nil
+}
+
+// loadProductTags carga las etiquetas de productos obtenidos sin Preload (p. ej. con SQL propio)
+func loadProductTags(db *gorm.DB, products []*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(products))
+	byID := make(map[uint]*models.Product, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+		byID[product.ID] = product
+		product.Tags = []models.Tag{}
+	}
+
+	var rows []struct {
+		ProductID uint
+		models.Tag
+	}
+	err := db.Table("product_tags").
+		Select("product_tags.product_id, tags.*").
+		Joins("JOIN tags ON tags.id = product_tags.tag_id").
+		Where("product_tags.product_id IN ?", ids).
+		Scan(&rows).Error
+	if err != nil {
+		return fmt.Errorf("failed to fetch product tags: %w", err)
+	}
+	for _, row := range rows {
+		if product, ok := byID[row.ProductID]; ok {
+			product.Tags = append(product.Tags, row.Tag)
+		}
+	}
+	return nil
+}
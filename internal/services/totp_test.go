@@ -0,0 +1,96 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret es el secreto de los vectores de prueba de los RFC 4226 y 6238 (SHA-1)
+const rfcSecret = "12345678901234567890"
+
+// TestHOTP usa los vectores del apéndice D del RFC 4226
+func TestHOTP(t *testing.T) {
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		if got := hotp([]byte(rfcSecret), int64(counter)); got != code {
+			t.Errorf("hotp(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+// TestValidateTOTP usa los vectores SHA-1 del apéndice B del RFC 6238, truncados a 6 dígitos
+func TestValidateTOTP(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte(rfcSecret))
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, v := range vectors {
+		now := time.Unix(v.unix, 0)
+		step, ok := validateTOTP(secret, v.code, now, 0)
+		if !ok || step != v.unix/totpPeriod {
+			t.Errorf("T=%d: validateTOTP(%s) = %d, %v, want step %d", v.unix, v.code, step, ok, v.unix/totpPeriod)
+		}
+	}
+
+	now := time.Unix(1111111109, 0)
+	current := now.Unix() / totpPeriod
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		now      time.Time
+		lastStep int64
+		want     bool
+	}{
+		{"previous step within skew", secret, "081804", now.Add(totpPeriod * time.Second), 0, true},
+		{"next step within skew", secret, "081804", now.Add(-totpPeriod * time.Second), 0, true},
+		{"outside skew", secret, "081804", now.Add(2 * totpPeriod * time.Second), 0, false},
+		{"already used step", secret, "081804", now, current, false},
+		{"later step than the last used", secret, "081804", now, current - 1, true},
+		{"surrounding spaces", secret, " 081804 ", now, 0, true},
+		{"lowercase secret", strings.ToLower(secret), "081804", now, 0, true},
+		{"wrong code", secret, "081805", now, 0, false},
+		{"eight digit code", secret, "07081804", now, 0, false},
+		{"invalid secret", "not base32!", "081804", now, 0, false},
+	}
+	for _, tt := range tests {
+		if _, ok := validateTOTP(tt.secret, tt.code, tt.now, tt.lastStep); ok != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, ok, tt.want)
+		}
+	}
+}
+
+func TestTOTPSecretAndURI(t *testing.T) {
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) != totpSecretLen {
+		t.Fatalf("secret %q decodes to %d bytes (%v), want %d", secret, len(key), err, totpSecretLen)
+	}
+
+	uri, err := url.Parse(totpProvisioningURI(secret, "ana+test@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/"+totpIssuer+":ana+test@example.com" {
+		t.Errorf("unexpected URI %s", uri)
+	}
+	query := uri.Query()
+	for param, want := range map[string]string{"secret": secret, "issuer": totpIssuer, "algorithm": "SHA1", "digits": "6", "period": "30"} {
+		if got := query.Get(param); got != want {
+			t.Errorf("%s = %q, want %q", param, got, want)
+		}
+	}
+}
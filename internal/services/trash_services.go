@@ -0,0 +1,216 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"inventory-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Tamaño de los lotes que borra cada pasada de la purga automática
+const trashPurgeBatchSize = 500
+
+// TrashConfig define cuánto se conservan los productos eliminados y cada cuánto se purgan
+type TrashConfig struct {
+	Retention     time.Duration // 0 desactiva la purga automática
+	PurgeInterval time.Duration
+}
+
+var (
+	trashConfig     TrashConfig
+	trashConfigOnce sync.Once
+)
+
+// LoadTrashConfig lee la configuración de la papelera (una sola vez)
+// TRASH_RETENTION_DAYS=0 conserva los productos eliminados indefinidamente
+func LoadTrashConfig() TrashConfig {
+	trashConfigOnce.Do(func() {
+		days := envInt("TRASH_RETENTION_DAYS", 30)
+		if days < 0 {
+			days = 0
+		}
+		trashConfig = TrashConfig{
+			Retention:     time.Duration(days) * 24 * time.Hour,
+			PurgeInterval: envDuration("TRASH_PURGE_INTERVAL", time.Hour),
+		}
+		if trashConfig.PurgeInterval <= 0 {
+			trashConfig.PurgeInterval = time.Hour
+		}
+	})
+	return trashConfig
+}
+
+// ListTrash obtiene los productos eliminados de la organización, del más reciente al más antiguo
+func (ps *ProductService) ListTrash() ([]models.TrashedProductResponse, error) {
+	var products []models.Product
+	if err := ps.tenant().Unscoped().Preload("Tags").Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").Find(&products).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch deleted products: %w", err)
+	}
+
+	rules, err := ps.stockRules()
+	if err != nil {
+		return nil, err
+	}
+
+	retention := LoadTrashConfig().Retention
+	responses := []models.TrashedProductResponse{}
+	for _, product := range products {
+		responses = append(responses, product.ToTrashResponse(retention, rules))
+	}
+	return responses, nil
+}
+
+// RestoreProduct recupera un producto de la papelera
+func (ps *ProductService) RestoreProduct(id uint) (*models.ProductResponse, error) {
+	var product models.Product
+	if err := ps.tenant().Unscoped().Preload("Tags").Where("deleted_at IS NOT NULL").First(&product, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotInTrash
+		}
+		return nil, fmt.Errorf("failed to fetch product: %w", err)
+	}
+
+	deletedAt := product.DeletedAt.Time
+	err := ps.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&product).Update("deleted_at", nil).Error; err != nil {
+			return fmt.Errorf("failed to restore product: %w", err)
+		}
+		return recordAudit(tx, ps.audit, models.AuditActionRestore, models.AuditEntityProduct, product.ID, product.OrganizationID,
+			map[string]interface{}{"deleted_at": &deletedAt},
+			map[string]interface{}{"deleted_at": (*time.Time)(nil)})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	product.DeletedAt = nil
+	ps.publish(ProductEventSaved, product)
+
+	return ps.productResponse(&product)
+}
+
+// PurgeProduct elimina definitivamente un producto, esté o no en la papelera,
+// junto con su historial de versiones y su regla de stock. El log de auditoría se conserva
+func (ps *ProductService) PurgeProduct(id uint) error {
+	var product models.Product
+	if err := ps.tenant().Unscoped().Preload("Tags").First(&product, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrProductNotFound
+		}
+		return fmt.Errorf("failed to fetch product: %w", err)
+	}
+
+	err := ps.db.Transaction(func(tx *gorm.DB) error {
+		if err := purgeProducts(tx, []uint{product.ID}); err != nil {
+			return err
+		}
+		return recordAudit(tx, ps.audit, models.AuditActionPurge, models.AuditEntityProduct,
+			product.ID, product.OrganizationID, product.AuditFields(), nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	ps.publish(ProductEventRemoved, product)
+	return nil
+}
+
+// PurgeExpiredProducts elimina definitivamente los productos que llevan en la papelera
+// más tiempo que la retención configurada. Retorna cuántos se purgaron
+func PurgeExpiredProducts(db *gorm.DB, retention time.Duration) (int, error) {
+	if retention <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().Add(-retention)
+
+	purged := 0
+	for {
+		var products []models.Product
+		if err := db.Unscoped().Preload("Tags").Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Order("id").Limit(trashPurgeBatchSize).Find(&products).Error; err != nil {
+			return purged, fmt.Errorf("failed to fetch expired products: %w", err)
+		}
+		if len(products) == 0 {
+			return purged, nil
+		}
+
+		ids := make([]uint, len(products))
+		for i, product := range products {
+			ids[i] = product.ID
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := purgeProducts(tx, ids); err != nil {
+				return err
+			}
+			// Sin autor: la purga la hace el sistema
+			for _, product := range products {
+				if err := recordAudit(tx, models.AuditContext{}, models.AuditActionPurge, models.AuditEntityProduct,
+					product.ID, product.OrganizationID, product.AuditFields(), nil); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return purged, err
+		}
+		for _, product := range products {
+			publishProductEvent(ProductEventRemoved, product)
+		}
+		purged += len(products)
+
+		if len(products) < trashPurgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// StartTrashPurger lanza en segundo plano la purga periódica de la papelera
+// No hace nada si la retención está desactivada
+func StartTrashPurger(db *gorm.DB) {
+	cfg := LoadTrashConfig()
+	if cfg.Retention <= 0 {
+		log.Println("🗑️  Trash retention disabled, deleted products are kept indefinitely")
+		return
+	}
+
+	log.Printf("🗑️  Purging deleted products older than %s every %s", cfg.Retention, cfg.PurgeInterval)
+	go func() {
+		ticker := time.NewTicker(cfg.PurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			if purged, err := PurgeExpiredProducts(db, cfg.Retention); err != nil {
+				log.Printf("Warning: trash purge failed: %v", err)
+			} else if purged > 0 {
+				log.Printf("🗑️  Purged %d expired products from the trash", purged)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// purgeProducts borra físicamente los productos indicados, sus etiquetas, su historial de versiones
+// y sus reglas de stock
+func purgeProducts(tx *gorm.DB, ids []uint) error {
+	if err := tx.Exec("DELETE FROM product_tags WHERE product_id IN ?", ids).Error; err != nil {
+		return fmt.Errorf("failed to delete product tags: %w", err)
+	}
+	if err := tx.Where("product_id IN ?", ids).Delete(&models.ProductVersion{}).Error; err != nil {
+		return fmt.Errorf("failed to delete product history: %w", err)
+	}
+	if err := tx.Where("product_id IN ?", ids).Delete(&models.StockRule{}).Error; err != nil {
+		return fmt.Errorf("failed to delete product stock rules: %w", err)
+	}
+	if err := tx.Unscoped().Where("id IN ?", ids).Delete(&models.Product{}).Error; err != nil {
+		return fmt.Errorf("failed to delete products: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"inventory-api/internal/i18n"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// skuPattern es el formato de los SKU: letras, dígitos, '.', '-' y '_', empezando por letra o dígito
+var skuPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// FieldError describe un campo de la petición que no cumple una regla
+type FieldError struct {
+	Field   string `json:"field"` // Ruta del campo en el JSON, p. ej. name o price.op
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+
+	messageKey  string        // Código del mensaje en el catálogo de i18n
+	messageArgs []interface{} // Argumentos del mensaje
+}
+
+// newFieldError crea el error de un campo con el mensaje en inglés; Localize lo traduce
+func newFieldError(field, rule, param, key string, args ...interface{}) FieldError {
+	return FieldError{
+		Field:       field,
+		Rule:        rule,
+		Param:       param,
+		Message:     i18n.T(i18n.English, key, args...),
+		messageKey:  key,
+		messageArgs: args,
+	}
+}
+
+// Reason retorna el mensaje del error sin traducir, para incluirlo en otro mensaje
+func (e FieldError) Reason() i18n.Message {
+	return i18n.Msg(e.messageKey, e.messageArgs...)
+}
+
+// Errors es el error que retorna la validación, con un elemento por campo no válido
+type Errors []FieldError
+
+// Error implementa la interfaz error
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, fieldErr := range e {
+		parts[i] = fieldErr.Field + " " + fieldErr.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Localize retorna una copia de los errores con los mensajes en el idioma indicado
+func (e Errors) Localize(lang i18n.Language) Errors {
+	localized := make(Errors, len(e))
+	for i, fieldErr := range e {
+		if fieldErr.messageKey != "" {
+			fieldErr.Message = i18n.T(lang, fieldErr.messageKey, fieldErr.messageArgs...)
+		}
+		localized[i] = fieldErr
+	}
+	return localized
+}
+
+// Required retorna el error de un campo obligatorio que las etiquetas no pueden expresar,
+// p. ej. porque la misma petición se usa en operaciones donde es opcional
+func Required(field string) Errors {
+	return Errors{newFieldError(field, "required", "", "validation.required")}
+}
+
+// Validator aplica las etiquetas validate de los modelos. Implementa echo.Validator
+type Validator struct {
+	validate *validator.Validate
+}
+
+var defaultValidator = New()
+
+// New crea un validador con los nombres de campo del JSON y las reglas propias de la API:
+//   - sku: formato de SKU (skuPattern)
+//   - currency: código de moneda ISO 4217 en mayúsculas, p. ej. EUR
+func New() *Validator {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(jsonFieldName)
+	if err := validate.RegisterValidation("sku", isSKU); err != nil {
+		panic(err)
+	}
+	validate.RegisterAlias("currency", "iso4217")
+	return &Validator{validate: validate}
+}
+
+// Default retorna el validador compartido por Echo y los servicios
+func Default() *Validator {
+	return defaultValidator
+}
+
+// Validate valida una petición con el validador compartido
+func Validate(i interface{}) error {
+	return defaultValidator.Validate(i)
+}
+
+// Validate valida una estructura; si algún campo no es válido retorna Errors
+func (v *Validator) Validate(i interface{}) error {
+	err := v.validate.Struct(i)
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	fieldErrs := make(Errors, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		param := fe.Param()
+		if fe.Tag() == "required_without" {
+			param = snakeCase(param)
+		}
+		key, args := message(fe)
+		fieldErrs = append(fieldErrs, newFieldError(fieldPath(fe.Namespace()), fe.Tag(), param, key, args...))
+	}
+	return fieldErrs
+}
+
+// jsonFieldName usa el nombre del campo en el JSON para los errores
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// fieldPath convierte el namespace del validador (Tipo.campo[0].campo) en la ruta del campo
+// sin el tipo raíz
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		namespace = namespace[i+1:]
+	}
+	namespace = strings.ReplaceAll(namespace, "[", ".")
+	return strings.ReplaceAll(namespace, "]", "")
+}
+
+// isSKU implementa la regla sku
+func isSKU(fl validator.FieldLevel) bool {
+	return skuPattern.MatchString(fl.Field().String())
+}
+
+// message retorna el código del mensaje del error y sus argumentos; el mensaje está
+// redactado para seguir al nombre del campo
+func message(fe validator.FieldError) (string, []interface{}) {
+	counted := fe.Kind() == reflect.String || fe.Kind() == reflect.Slice || fe.Kind() == reflect.Map
+	unit := "_length"
+	if fe.Kind() != reflect.String {
+		unit = "_items"
+	}
+
+	switch fe.Tag() {
+	case "required", "email", "sku", "currency":
+		return "validation." + fe.Tag(), nil
+	case "required_without":
+		return "validation.required_without", []interface{}{snakeCase(fe.Param())}
+	case "oneof":
+		return "validation.oneof", []interface{}{strings.Join(strings.Fields(fe.Param()), ", ")}
+	case "min", "max":
+		if counted {
+			return "validation." + fe.Tag() + unit, []interface{}{fe.Param()}
+		}
+		return "validation." + fe.Tag(), []interface{}{fe.Param()}
+	case "len":
+		return "validation.len" + unit, []interface{}{fe.Param()}
+	}
+	return "validation.rule", []interface{}{fe.Tag()}
+}
+
+// snakeCase convierte el nombre de un campo Go (CategoryID) a su nombre en el JSON (category_id)
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := !unicode.IsUpper(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || nextLower {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
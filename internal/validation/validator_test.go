@@ -0,0 +1,210 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"inventory-api/internal/i18n"
+	"inventory-api/internal/models"
+)
+
+type testItem struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type testRequest struct {
+	SKU        string     `json:"sku" validate:"omitempty,sku"`
+	Currency   string     `json:"currency" validate:"omitempty,currency"`
+	Email      string     `json:"email" validate:"omitempty,email"`
+	Slug       string     `json:"slug" validate:"required_without=CategoryID"`
+	CategoryID *uint      `json:"category_id"`
+	Code       string     `json:"code" validate:"omitempty,len=3"`
+	Language   string     `json:"language" validate:"omitempty,oneof=es en"`
+	Quantity   int        `json:"quantity" validate:"min=0,max=10"`
+	Tags       []string   `json:"tags" validate:"max=2"`
+	Items      []testItem `json:"items" validate:"dive"`
+	Website    string     `json:"website" validate:"omitempty,url"`
+}
+
+// validRequest es una petición válida a la que cada caso le cambia un campo
+func validRequest() testRequest {
+	return testRequest{Slug: "tools"}
+}
+
+func TestValidate(t *testing.T) {
+	categoryID := uint(1)
+	tests := []struct {
+		name    string
+		change  func(r *testRequest)
+		want    FieldError // Vacío si la petición es válida
+		message string
+	}{
+		{name: "valid", change: func(r *testRequest) {}},
+		{name: "valid sku", change: func(r *testRequest) { r.SKU = "AB-12_x.3" }},
+		{name: "valid currency", change: func(r *testRequest) { r.Currency = "USD" }},
+		{name: "required_without satisfied by the other field", change: func(r *testRequest) { r.Slug, r.CategoryID = "", &categoryID }},
+		{
+			name:    "sku starting with a symbol",
+			change:  func(r *testRequest) { r.SKU = "-AB" },
+			want:    FieldError{Field: "sku", Rule: "sku"},
+			message: "must contain only letters, digits, '.', '-' and '_', starting with a letter or digit",
+		},
+		{
+			name:    "sku with spaces",
+			change:  func(r *testRequest) { r.SKU = "AB 12" },
+			want:    FieldError{Field: "sku", Rule: "sku"},
+			message: "must contain only letters, digits, '.', '-' and '_', starting with a letter or digit",
+		},
+		{
+			name:    "lowercase currency",
+			change:  func(r *testRequest) { r.Currency = "eur" },
+			want:    FieldError{Field: "currency", Rule: "currency"},
+			message: "must be an ISO 4217 currency code, e.g. EUR",
+		},
+		{
+			name:    "unknown currency",
+			change:  func(r *testRequest) { r.Currency = "XXY" },
+			want:    FieldError{Field: "currency", Rule: "currency"},
+			message: "must be an ISO 4217 currency code, e.g. EUR",
+		},
+		{
+			name:    "email",
+			change:  func(r *testRequest) { r.Email = "nope" },
+			want:    FieldError{Field: "email", Rule: "email"},
+			message: "must be a valid email address",
+		},
+		{
+			name:    "required_without uses the JSON name of the other field",
+			change:  func(r *testRequest) { r.Slug = "" },
+			want:    FieldError{Field: "slug", Rule: "required_without", Param: "category_id"},
+			message: "is required when category_id is not set",
+		},
+		{
+			name:    "len of a string",
+			change:  func(r *testRequest) { r.Code = "ABCD" },
+			want:    FieldError{Field: "code", Rule: "len", Param: "3"},
+			message: "must have exactly 3 characters",
+		},
+		{
+			name:    "oneof",
+			change:  func(r *testRequest) { r.Language = "fr" },
+			want:    FieldError{Field: "language", Rule: "oneof", Param: "es en"},
+			message: "must be one of: es, en",
+		},
+		{
+			name:    "min of a number",
+			change:  func(r *testRequest) { r.Quantity = -1 },
+			want:    FieldError{Field: "quantity", Rule: "min", Param: "0"},
+			message: "must be 0 or greater",
+		},
+		{
+			name:    "max of a number",
+			change:  func(r *testRequest) { r.Quantity = 11 },
+			want:    FieldError{Field: "quantity", Rule: "max", Param: "10"},
+			message: "must be 10 or less",
+		},
+		{
+			name:    "max of a slice",
+			change:  func(r *testRequest) { r.Tags = []string{"a", "b", "c"} },
+			want:    FieldError{Field: "tags", Rule: "max", Param: "2"},
+			message: "must have at most 2 items",
+		},
+		{
+			name:    "nested field path",
+			change:  func(r *testRequest) { r.Items = []testItem{{Name: "a"}, {}} },
+			want:    FieldError{Field: "items.1.name", Rule: "required"},
+			message: "is required",
+		},
+		{
+			name:    "rule without its own message",
+			change:  func(r *testRequest) { r.Website = "not a url" },
+			want:    FieldError{Field: "website", Rule: "url"},
+			message: "does not satisfy the url rule",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validRequest()
+			tt.change(&req)
+			err := Validate(req)
+			if tt.want.Field == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var errs Errors
+			if !errors.As(err, &errs) || len(errs) != 1 {
+				t.Fatalf("got %v, want one field error", err)
+			}
+			got := errs[0]
+			if got.Field != tt.want.Field || got.Rule != tt.want.Rule || got.Param != tt.want.Param || got.Message != tt.message {
+				t.Fatalf("got %+v, want %+v with message %q", got, tt.want, tt.message)
+			}
+		})
+	}
+}
+
+// TestValidateProductRequest comprueba las reglas que antes no se aplicaban en los controladores
+func TestValidateProductRequest(t *testing.T) {
+	req := models.ProductRequest{
+		Name:        "x",
+		Description: strings.Repeat("a", 501),
+		Price:       -1,
+	}
+	var errs Errors
+	if !errors.As(Validate(req), &errs) {
+		t.Fatal("want field errors")
+	}
+	got := map[string]string{}
+	for _, fieldErr := range errs {
+		got[fieldErr.Field] = fieldErr.Rule
+	}
+	want := map[string]string{"name": "min", "description": "max", "price": "min", "category": "required_without"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestErrorsLocalize(t *testing.T) {
+	var errs Errors
+	if !errors.As(Validate(testRequest{Quantity: 11}), &errs) {
+		t.Fatal("want field errors")
+	}
+	spanish := errs.Localize(i18n.Spanish)
+	for i := range errs {
+		want := i18n.T(i18n.Spanish, errs[i].messageKey, errs[i].messageArgs...)
+		if spanish[i].Message != want || spanish[i].Field != errs[i].Field {
+			t.Errorf("got %+v, want the message %q", spanish[i], want)
+		}
+		if spanish[i].Message == errs[i].Message {
+			t.Errorf("%s: message %q is not translated", errs[i].Field, errs[i].Message)
+		}
+		if reason := errs[i].Reason(); reason.In(i18n.Spanish) != want {
+			t.Errorf("Reason() = %q, want %q", reason.In(i18n.Spanish), want)
+		}
+	}
+	// Localize no modifica los errores originales
+	if errs.Localize(i18n.English)[0].Message != errs[0].Message {
+		t.Error("English messages changed after localizing")
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"CategoryID":   "category_id",
+		"ReorderPoint": "reorder_point",
+		"SKU":          "sku",
+		"APIKeyID":     "api_key_id",
+		"Name":         "name",
+	}
+	for in, want := range tests {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
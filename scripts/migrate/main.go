@@ -42,8 +42,28 @@ func main() {
 	fmt.Println("📊 Tables created:")
 	fmt.Println("   - users")
 	fmt.Println("   - products")
+	fmt.Println("   - api_keys")
+	fmt.Println("   - mfa_recovery_codes")
+	fmt.Println("   - user_tokens")
+	fmt.Println("   - auth_events")
+	fmt.Println("   - login_throttles")
+	fmt.Println("   - oidc_login_states")
+	fmt.Println("   - organizations")
+	fmt.Println("   - memberships")
+	fmt.Println("   - audit_logs")
+	fmt.Println("   - product_versions")
+	fmt.Println("   - categories")
+	fmt.Println("   - tags")
+	fmt.Println("   - product_tags")
+	fmt.Println("   - attribute_definitions")
+	fmt.Println("   - stock_rules")
+	fmt.Println("   - schema_migrations")
 	fmt.Println("🔍 Indexes created:")
 	fmt.Println("   - idx_users_email (unique)")
 	fmt.Println("   - idx_products_category")
 	fmt.Println("   - idx_products_quantity")
+	fmt.Println("   - idx_categories_org_slug (unique)")
+	fmt.Println("   - idx_tags_org_slug (unique)")
+	fmt.Println("   - idx_products_attributes (GIN)")
+	fmt.Println("   - idx_stock_rules_{global,category,product} (unique)")
 }
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Proveedor OIDC mínimo para desarrollo local y pruebas del login OIDC
+// Aprueba automáticamente cada login con el usuario configurado (o el login_hint recibido)
+
+// authorization representa un código de autorización pendiente de canjear
+type authorization struct {
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	email         string
+	expiresAt     time.Time
+}
+
+// mockIdP mantiene la clave de firma y los códigos emitidos
+type mockIdP struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	defaultEmail string
+	amr          []string
+	key          *rsa.PrivateKey
+	kid          string
+
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+func main() {
+	port := getEnv("MOCK_IDP_PORT", "9000")
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatal("❌ Failed to generate signing key:", err)
+	}
+
+	idp := &mockIdP{
+		issuer:       getEnv("MOCK_IDP_ISSUER", "http://localhost:"+port),
+		clientID:     getEnv("MOCK_IDP_CLIENT_ID", "inventory-api"),
+		clientSecret: os.Getenv("MOCK_IDP_CLIENT_SECRET"),
+		defaultEmail: getEnv("MOCK_IDP_EMAIL", "jane.doe@example.com"),
+		amr:          strings.Fields(getEnv("MOCK_IDP_AMR", "pwd")),
+		key:          key,
+		kid:          "mock-idp-key",
+		codes:        make(map[string]authorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc("/jwks", idp.jwks)
+
+	fmt.Printf("🪪 Mock OIDC provider running on %s\n", idp.issuer)
+	fmt.Printf("   Client ID: %s\n", idp.clientID)
+	fmt.Printf("   Default user: %s (override with ?login_hint=)\n", idp.defaultEmail)
+	fmt.Println("   Configure the API with:")
+	fmt.Printf("   OIDC_ISSUER_URL=%s\n", idp.issuer)
+	fmt.Printf("   OIDC_CLIENT_ID=%s\n", idp.clientID)
+	fmt.Println("   OIDC_REDIRECT_URL=http://localhost:8080/auth/oidc/callback")
+
+	if err := http.ListenAndServe(":"+port, mux); err != nil {
+		log.Fatal("❌ Mock IdP failed to start:", err)
+	}
+}
+
+// discovery publica los metadatos del proveedor
+func (idp *mockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                idp.issuer,
+		"authorization_endpoint":                idp.issuer + "/authorize",
+		"token_endpoint":                        idp.issuer + "/token",
+		"jwks_uri":                              idp.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"scopes_supported":                      []string{"openid", "email", "profile"},
+	})
+}
+
+// authorize aprueba el login y redirige con el código de autorización
+func (idp *mockIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	if q.Get("response_type") != "code" || q.Get("client_id") != idp.clientID {
+		http.Error(w, "invalid client_id or response_type", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		http.Error(w, "PKCE with S256 is required", http.StatusBadRequest)
+		return
+	}
+
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	email := q.Get("login_hint")
+	if email == "" {
+		email = idp.defaultEmail
+	}
+
+	code := randomToken()
+	idp.mu.Lock()
+	idp.codes[code] = authorization{
+		clientID:      q.Get("client_id"),
+		redirectURI:   q.Get("redirect_uri"),
+		codeChallenge: q.Get("code_challenge"),
+		nonce:         q.Get("nonce"),
+		email:         email,
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	idp.mu.Unlock()
+
+	params := redirectURI.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirectURI.RawQuery = params.Encode()
+
+	log.Printf("✅ Authorized %s, redirecting to %s", email, redirectURI.Host)
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// token canjea el código (verificando PKCE) por un ID token firmado
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request", err.Error())
+		return
+	}
+
+	// Autenticación del cliente (básica o en el formulario)
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID = r.PostForm.Get("client_id")
+		clientSecret = r.PostForm.Get("client_secret")
+	}
+	if clientID != idp.clientID || (idp.clientSecret != "" && clientSecret != idp.clientSecret) {
+		tokenError(w, "invalid_client", "client authentication failed")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	idp.mu.Lock()
+	auth, found := idp.codes[code]
+	delete(idp.codes, code) // Los códigos son de un solo uso
+	idp.mu.Unlock()
+
+	if !found || time.Now().After(auth.expiresAt) || auth.clientID != clientID {
+		tokenError(w, "invalid_grant", "unknown or expired code")
+		return
+	}
+	if r.PostForm.Get("redirect_uri") != auth.redirectURI {
+		tokenError(w, "invalid_grant", "redirect_uri mismatch")
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.codeChallenge {
+		tokenError(w, "invalid_grant", "PKCE verification failed")
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            idp.issuer,
+		"sub":            subjectFor(auth.email),
+		"aud":            idp.clientID,
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          auth.nonce,
+		"email":          auth.email,
+		"email_verified": true,
+		"amr":            idp.amr,
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = idp.kid
+	idToken, err := token.SignedString(idp.key)
+	if err != nil {
+		tokenError(w, "server_error", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomToken(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// jwks publica la clave pública de firma
+func (idp *mockIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := idp.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": idp.kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// subjectFor genera un subject estable a partir del email
+func subjectFor(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(email)))
+	return "mock|" + base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+// tokenError responde con un error OAuth 2.0
+func tokenError(w http.ResponseWriter, code, description string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+// writeJSON escribe una respuesta JSON
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// randomToken genera un valor aleatorio para códigos y tokens
+func randomToken() string {
+	buf := make([]byte, 24)
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// getEnv lee una variable de entorno con valor por defecto
+func getEnv(name, def string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return def
+}
@@ -8,6 +8,7 @@ import (
 	"inventory-api/internal/models"
 
 	"github.com/joho/godotenv"
+	"gorm.io/gorm"
 )
 
 func main() {
@@ -43,6 +44,7 @@ func main() {
 		{
 			Email:    "admin@inventory.com",
 			Password: "admin123", // Se hasheará automáticamente
+			Role:     models.RoleAdmin,
 		},
 		{
 			Email:    "manager@inventory.com",
@@ -55,14 +57,58 @@ func main() {
 	}
 
 	fmt.Println("👤 Creating example users...")
-	for _, user := range users {
-		if err := database.Create(&user).Error; err != nil {
-			log.Printf("❌ Failed to create user %s: %v", user.Email, err)
+	for i := range users {
+		if err := database.Create(&users[i]).Error; err != nil {
+			log.Printf("❌ Failed to create user %s: %v", users[i].Email, err)
 		} else {
-			fmt.Printf("   ✅ Created user: %s\n", user.Email)
+			fmt.Printf("   ✅ Created user: %s\n", users[i].Email)
 		}
 	}
 
+	// Crear la organización de ejemplo con un rol distinto para cada usuario
+	fmt.Println("🏢 Creating example organization...")
+	org := models.Organization{Name: "Inventory Demo", Slug: "demo"}
+	if err := database.Create(&org).Error; err != nil {
+		log.Fatal("❌ Failed to create organization:", err)
+	}
+
+	orgRoles := []string{models.OrgRoleOwner, models.OrgRoleAdmin, models.OrgRoleViewer}
+	for i, user := range users {
+		if user.ID == 0 {
+			continue
+		}
+		membership := models.Membership{OrganizationID: org.ID, UserID: user.ID, Role: orgRoles[i]}
+		if err := database.Create(&membership).Error; err != nil {
+			log.Printf("❌ Failed to add %s to %s: %v", user.Email, org.Name, err)
+		} else {
+			fmt.Printf("   ✅ %s is %s of %s\n", user.Email, membership.Role, org.Name)
+		}
+	}
+
+	// Crear el árbol de categorías de ejemplo (las padres antes que sus hijas)
+	fmt.Println("🗂️  Creating example categories...")
+	categories := []struct {
+		Name, Slug, Parent string
+	}{
+		{Name: "Electronics", Slug: "electronics"},
+		{Name: "Appliances", Slug: "appliances", Parent: "Electronics"},
+		{Name: "Furniture", Slug: "furniture"},
+		{Name: "Lighting", Slug: "lighting", Parent: "Furniture"},
+		{Name: "Office Equipment", Slug: "office-equipment"},
+	}
+	categoryIDs := make(map[string]uint)
+	for _, c := range categories {
+		category := models.Category{OrganizationID: org.ID, Name: c.Name, Slug: c.Slug}
+		if parentID, ok := categoryIDs[c.Parent]; ok {
+			category.ParentID = &parentID
+		}
+		if err := database.Create(&category).Error; err != nil {
+			log.Fatal("❌ Failed to create category:", err)
+		}
+		categoryIDs[c.Name] = category.ID
+		fmt.Printf("   ✅ Created category: %s\n", c.Name)
+	}
+
 	// Crear productos de ejemplo
 	products := []models.Product{
 		{
@@ -175,7 +221,19 @@ func main() {
 
 	fmt.Println("📦 Creating example products...")
 	for _, product := range products {
-		if err := database.Create(&product).Error; err != nil {
+		product.OrganizationID = org.ID
+		categoryID := categoryIDs[product.Category]
+		product.CategoryID = &categoryID
+		err := database.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&product).Error; err != nil {
+				return err
+			}
+			// Versión inicial del historial del producto
+			version := models.NewProductVersion(&product, models.ProductChangeCreate, models.AuditContext{}, product.CreatedAt)
+			version.Version = 1
+			return tx.Create(&version).Error
+		})
+		if err != nil {
 			log.Printf("❌ Failed to create product %s: %v", product.Name, err)
 		} else {
 			status := "normal"
@@ -198,21 +256,21 @@ func main() {
 	fmt.Printf("   👤 Users created: %d\n", userCount)
 	fmt.Printf("   📦 Products created: %d\n", productCount)
 
-	// Mostrar estadísticas de stock
+	// Mostrar estadísticas de stock (recién sembrado no hay reglas de stock: umbrales por defecto)
 	var lowStockCount int64
-	database.Model(&models.Product{}).Where("quantity < ?", 5).Count(&lowStockCount)
+	database.Model(&models.Product{}).Where("quantity <= ?", models.LowStockThreshold).Count(&lowStockCount)
 
 	var outOfStockCount int64
 	database.Model(&models.Product{}).Where("quantity = 0").Count(&outOfStockCount)
 
 	fmt.Println("\n⚠️  Stock Status:")
-	fmt.Printf("   📉 Low stock products (< 5): %d\n", lowStockCount)
+	fmt.Printf("   📉 Low stock products (<= %d): %d\n", models.LowStockThreshold, lowStockCount)
 	fmt.Printf("   🚫 Out of stock products: %d\n", outOfStockCount)
 
 	fmt.Println("\n🔑 Test Credentials:")
-	fmt.Println("   Email: admin@inventory.com | Password: admin123")
-	fmt.Println("   Email: manager@inventory.com | Password: manager123")
-	fmt.Println("   Email: user@inventory.com | Password: user123")
+	fmt.Println("   Email: admin@inventory.com | Password: admin123 (owner)")
+	fmt.Println("   Email: manager@inventory.com | Password: manager123 (admin)")
+	fmt.Println("   Email: user@inventory.com | Password: user123 (viewer)")
 
 	fmt.Println("\n✅ Database seeding completed successfully!")
 }